@@ -2,9 +2,24 @@ package main
 
 import (
 	"fmt"
+	"github.com/drewbuiltit/trading-journal/backend/internal/accounts"
 	"github.com/drewbuiltit/trading-journal/backend/internal/auth"
+	"github.com/drewbuiltit/trading-journal/backend/internal/cash"
+	"github.com/drewbuiltit/trading-journal/backend/internal/corporate"
+	"github.com/drewbuiltit/trading-journal/backend/internal/executions"
+	"github.com/drewbuiltit/trading-journal/backend/internal/export"
+	"github.com/drewbuiltit/trading-journal/backend/internal/fees"
+	"github.com/drewbuiltit/trading-journal/backend/internal/fx"
+	"github.com/drewbuiltit/trading-journal/backend/internal/imports"
+	"github.com/drewbuiltit/trading-journal/backend/internal/instruments"
+	"github.com/drewbuiltit/trading-journal/backend/internal/lots"
 	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/drewbuiltit/trading-journal/backend/internal/positions"
+	"github.com/drewbuiltit/trading-journal/backend/internal/spreads"
+	"github.com/drewbuiltit/trading-journal/backend/internal/stats"
 	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"github.com/drewbuiltit/trading-journal/backend/internal/tax"
+	"github.com/drewbuiltit/trading-journal/backend/internal/trades"
 	"github.com/gorilla/mux"
 	"github.com/joho/godotenv"
 	"gorm.io/driver/postgres"
@@ -28,22 +43,43 @@ func main() {
 
 	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable", dbHost, dbUser, dbPassword, dbName, dbPort)
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
 	if err != nil {
 		log.Fatalf("Failed to connect ot the database: %v", err)
 	}
 
-	if err := db.AutoMigrate(&models.User{}, &models.Trade{}); err != nil {
+	if err := db.AutoMigrate(&models.User{}, &models.Account{}, &models.Trade{}, &models.Execution{}, &models.Spread{}, &models.LotSelection{}, &models.FeeSchedule{}, &models.FXRate{}, &models.CSVTemplate{}, &models.ImportBatch{}, &models.CashTransaction{}, &models.CorporateAction{}); err != nil {
 		log.Fatalf("Failed to migrate database: %v", err)
 	}
 
 	auth.Init()
 
+	if path := os.Getenv("FUTURES_SPECS_FILE"); path != "" {
+		if err := instruments.LoadFuturesFile(path); err != nil {
+			log.Fatalf("Failed to load futures specs: %v", err)
+		}
+	}
+
 	router := mux.NewRouter()
 
 	s := store.NewPostgresStore(db)
 
 	authHandler := &auth.AuthHandler{Store: s}
+	accountHandler := &accounts.AccountHandler{Store: s}
+	tradeHandler := &trades.TradeHandler{Store: s}
+	executionHandler := &executions.ExecutionHandler{Store: s}
+	spreadHandler := &spreads.SpreadHandler{Store: s}
+	positionHandler := &positions.PositionHandler{Store: s}
+	lotHandler := &lots.LotHandler{Store: s}
+	feeHandler := &fees.FeeHandler{Store: s}
+	instrumentHandler := &instruments.InstrumentHandler{}
+	fxHandler := &fx.FXHandler{Store: s}
+	importHandler := &imports.ImportHandler{Store: s}
+	cashHandler := &cash.CashHandler{Store: s}
+	exportHandler := &export.ExportHandler{Store: s}
+	taxHandler := &tax.TaxHandler{Store: s}
+	corporateHandler := &corporate.CorporateActionHandler{Store: s}
+	statsHandler := &stats.StatsHandler{Store: s}
 
 	router.HandleFunc("/register", authHandler.Register).Methods("POST")
 	router.HandleFunc("/login", authHandler.Login).Methods("POST")
@@ -53,6 +89,91 @@ func main() {
 	protected.Use(auth.AuthMiddleWare)
 	protected.HandleFunc("/", authHandler.ProtectedEndpoint).Methods("GET")
 
+	protected.HandleFunc("/accounts", accountHandler.ListAccounts).Methods("GET")
+	protected.HandleFunc("/accounts", accountHandler.CreateAccount).Methods("POST")
+	protected.HandleFunc("/accounts/{id}", accountHandler.GetAccount).Methods("GET")
+	protected.HandleFunc("/accounts/{id}", accountHandler.UpdateAccount).Methods("PUT")
+	protected.HandleFunc("/accounts/{id}", accountHandler.DeleteAccount).Methods("DELETE")
+
+	protected.HandleFunc("/trades", tradeHandler.ListTrades).Methods("GET")
+	protected.HandleFunc("/trades", tradeHandler.CreateTrade).Methods("POST")
+	protected.HandleFunc("/trades/{id}", tradeHandler.GetTrade).Methods("GET")
+	protected.HandleFunc("/trades/{id}", tradeHandler.UpdateTrade).Methods("PUT")
+	protected.HandleFunc("/trades/{id}", tradeHandler.DeleteTrade).Methods("DELETE")
+
+	protected.HandleFunc("/executions", executionHandler.ListExecutions).Methods("GET")
+	protected.HandleFunc("/executions", executionHandler.CreateExecution).Methods("POST")
+	protected.HandleFunc("/executions/{id}", executionHandler.GetExecution).Methods("GET")
+	protected.HandleFunc("/executions/{id}", executionHandler.UpdateExecution).Methods("PUT")
+	protected.HandleFunc("/executions/{id}", executionHandler.DeleteExecution).Methods("DELETE")
+	protected.HandleFunc("/executions/{id}/lots", lotHandler.SetSelections).Methods("PUT")
+
+	protected.HandleFunc("/cash-transactions", cashHandler.ListTransactions).Methods("GET")
+	protected.HandleFunc("/cash-transactions", cashHandler.CreateTransaction).Methods("POST")
+	protected.HandleFunc("/cash-transactions/ledger", cashHandler.GetLedger).Methods("GET")
+	protected.HandleFunc("/cash-transactions/{id}", cashHandler.UpdateTransaction).Methods("PUT")
+	protected.HandleFunc("/cash-transactions/{id}", cashHandler.DeleteTransaction).Methods("DELETE")
+	protected.HandleFunc("/returns", cashHandler.GetReturns).Methods("GET")
+
+	protected.HandleFunc("/spreads", spreadHandler.ListSpreads).Methods("GET")
+	protected.HandleFunc("/spreads", spreadHandler.CreateSpread).Methods("POST")
+	protected.HandleFunc("/spreads/{id}", spreadHandler.GetSpread).Methods("GET")
+	protected.HandleFunc("/spreads/{id}", spreadHandler.UpdateSpread).Methods("PUT")
+	protected.HandleFunc("/spreads/{id}", spreadHandler.DeleteSpread).Methods("DELETE")
+
+	protected.HandleFunc("/positions", positionHandler.ListPositions).Methods("GET")
+	protected.HandleFunc("/positions/chains", positionHandler.ListChains).Methods("GET")
+	protected.HandleFunc("/lots", positionHandler.ListLots).Methods("GET")
+	protected.HandleFunc("/lots/method", lotHandler.GetMethod).Methods("GET")
+	protected.HandleFunc("/lots/method", lotHandler.SetMethod).Methods("PUT")
+
+	protected.HandleFunc("/export/fills", exportHandler.ExportFills).Methods("GET")
+	protected.HandleFunc("/export/positions", exportHandler.ExportPositions).Methods("GET")
+	protected.HandleFunc("/export/workbook", exportHandler.ExportWorkbook).Methods("GET")
+
+	protected.HandleFunc("/stats", statsHandler.GetStats).Methods("GET")
+	protected.HandleFunc("/stats/equity", statsHandler.GetEquityCurve).Methods("GET")
+	protected.HandleFunc("/stats/risk", statsHandler.GetRisk).Methods("GET")
+	protected.HandleFunc("/stats/breakdown", statsHandler.GetBreakdown).Methods("GET")
+	protected.HandleFunc("/timezone", statsHandler.GetTimezone).Methods("GET")
+	protected.HandleFunc("/timezone", statsHandler.SetTimezone).Methods("PUT")
+
+	protected.HandleFunc("/tax/lots", taxHandler.GetReport).Methods("GET")
+	protected.HandleFunc("/tax/8949", taxHandler.ExportForm8949).Methods("GET")
+
+	protected.HandleFunc("/instruments", instrumentHandler.GetInstrument).Methods("GET")
+	protected.HandleFunc("/instruments/futures", instrumentHandler.ListFutures).Methods("GET")
+
+	protected.HandleFunc("/fee-schedule", feeHandler.GetSchedule).Methods("GET")
+	protected.HandleFunc("/fee-schedule", feeHandler.SaveSchedule).Methods("PUT")
+
+	protected.HandleFunc("/base-currency", fxHandler.GetBaseCurrency).Methods("GET")
+	protected.HandleFunc("/base-currency", fxHandler.SetBaseCurrency).Methods("PUT")
+	protected.HandleFunc("/fx-rates", fxHandler.ListRates).Methods("GET")
+	protected.HandleFunc("/fx-rates/import", fxHandler.ImportRates).Methods("POST")
+
+	protected.HandleFunc("/corporate-actions", corporateHandler.ListActions).Methods("GET")
+	protected.HandleFunc("/corporate-actions", corporateHandler.CreateAction).Methods("POST")
+	protected.HandleFunc("/corporate-actions/import", corporateHandler.ImportActions).Methods("POST")
+	protected.HandleFunc("/corporate-actions/{id}", corporateHandler.DeleteAction).Methods("DELETE")
+
+	protected.HandleFunc("/imports", importHandler.ListBatches).Methods("GET")
+	protected.HandleFunc("/imports/{id}", importHandler.GetBatch).Methods("GET")
+	protected.HandleFunc("/imports/{id}", importHandler.DeleteBatch).Methods("DELETE")
+	protected.HandleFunc("/imports/ibkr", importHandler.ImportIBKR).Methods("POST")
+	protected.HandleFunc("/imports/ofx", importHandler.ImportOFX).Methods("POST")
+	protected.HandleFunc("/imports/fix", importHandler.ImportFIX).Methods("POST")
+	protected.HandleFunc("/imports/ninjatrader", importHandler.ImportNinjaTrader).Methods("POST")
+	protected.HandleFunc("/imports/tradovate", importHandler.ImportTradovate).Methods("POST")
+	protected.HandleFunc("/imports/tradestation", importHandler.ImportTradeStation).Methods("POST")
+	protected.HandleFunc("/imports/csv", importHandler.ImportCSV).Methods("POST")
+	protected.HandleFunc("/imports/csv/preview", importHandler.PreviewCSV).Methods("POST")
+	protected.HandleFunc("/imports/csv/templates", importHandler.ListTemplates).Methods("GET")
+	protected.HandleFunc("/imports/csv/templates", importHandler.CreateTemplate).Methods("POST")
+	protected.HandleFunc("/imports/csv/templates/{id}", importHandler.GetTemplate).Methods("GET")
+	protected.HandleFunc("/imports/csv/templates/{id}", importHandler.UpdateTemplate).Methods("PUT")
+	protected.HandleFunc("/imports/csv/templates/{id}", importHandler.DeleteTemplate).Methods("DELETE")
+
 	log.Println("Server starting on port 8080...")
 	err = http.ListenAndServe(":8080", router)
 	if err != nil {
@@ -0,0 +1,60 @@
+package accounts
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidFilter = errors.New("account must be a list of account IDs or none")
+
+// unassigned is the key "none" uses for records without an account.
+const unassigned = 0
+
+// Filter selects records by account. An empty filter matches everything.
+type Filter map[int]bool
+
+// ParseFilter reads the account query parameter, which may be repeated or
+// hold a comma separated list such as ?account=1,2. The value "none" selects
+// records that are not attached to an account.
+func ParseFilter(query url.Values) (Filter, error) {
+	filter := Filter{}
+	for _, value := range query["account"] {
+		for _, part := range strings.Split(value, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			if strings.EqualFold(part, "none") {
+				filter[unassigned] = true
+				continue
+			}
+			id, err := strconv.Atoi(part)
+			if err != nil || id <= 0 {
+				return nil, ErrInvalidFilter
+			}
+			filter[id] = true
+		}
+	}
+	return filter, nil
+}
+
+func (f Filter) Match(accountID *int) bool {
+	if len(f) == 0 {
+		return true
+	}
+	if accountID == nil {
+		return f[unassigned]
+	}
+	return f[*accountID]
+}
+
+// Same reports whether two records belong to the same account, counting two
+// unassigned records as the same.
+func Same(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
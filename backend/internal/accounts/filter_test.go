@@ -0,0 +1,38 @@
+package accounts
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/url"
+	"testing"
+)
+
+func TestParseFilter(t *testing.T) {
+	filter, err := ParseFilter(url.Values{})
+	assert.NoError(t, err)
+	assert.True(t, filter.Match(nil), "an empty filter matches everything")
+
+	one, two, three := 1, 2, 3
+	filter, err = ParseFilter(url.Values{"account": {"1, 2", "none"}})
+	assert.NoError(t, err)
+	assert.True(t, filter.Match(&one))
+	assert.True(t, filter.Match(&two))
+	assert.False(t, filter.Match(&three))
+	assert.True(t, filter.Match(nil))
+
+	filter, err = ParseFilter(url.Values{"account": {"3"}})
+	assert.NoError(t, err)
+	assert.False(t, filter.Match(nil))
+
+	for _, value := range []string{"abc", "0", "-1", "1,x"} {
+		_, err = ParseFilter(url.Values{"account": {value}})
+		assert.ErrorIs(t, err, ErrInvalidFilter, value)
+	}
+}
+
+func TestSame(t *testing.T) {
+	one, alsoOne, two := 1, 1, 2
+	assert.True(t, Same(nil, nil))
+	assert.True(t, Same(&one, &alsoOne))
+	assert.False(t, Same(&one, &two))
+	assert.False(t, Same(&one, nil))
+}
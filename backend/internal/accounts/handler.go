@@ -0,0 +1,189 @@
+package accounts
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/drewbuiltit/trading-journal/backend/internal/auth"
+	"github.com/drewbuiltit/trading-journal/backend/internal/instruments"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"github.com/gorilla/mux"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type AccountHandler struct {
+	Store store.Store
+}
+
+type AccountRequest struct {
+	Name     string `json:"name"`
+	Broker   string `json:"broker,omitempty"`
+	Number   string `json:"number,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Currency string `json:"currency,omitempty"`
+}
+
+func (req *AccountRequest) validate() error {
+	if strings.TrimSpace(req.Name) == "" {
+		return errors.New("name is required")
+	}
+	if req.Type != "" && !models.ValidAccountType(strings.ToLower(req.Type)) {
+		return errors.New("type must be cash, margin, ira, futures or other")
+	}
+	if req.Currency != "" && !instruments.ValidCurrency(strings.ToUpper(req.Currency)) {
+		return errors.New("currency must be a three-letter currency code")
+	}
+	return nil
+}
+
+func (req *AccountRequest) apply(account *models.Account) {
+	account.Name = strings.TrimSpace(req.Name)
+	account.Broker = strings.TrimSpace(req.Broker)
+	account.Number = strings.TrimSpace(req.Number)
+	account.Type = strings.ToLower(req.Type)
+	account.Currency = strings.ToUpper(req.Currency)
+}
+
+func (h *AccountHandler) CreateAccount(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	var req AccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	if err := req.validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	account := &models.Account{UserID: userID}
+	req.apply(account)
+
+	if err := h.Store.CreateAccount(account); err != nil {
+		http.Error(w, "Error creating account", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(account)
+}
+
+func (h *AccountHandler) ListAccounts(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	accounts, err := h.Store.ListAccounts(userID)
+	if err != nil {
+		http.Error(w, "Error listing accounts", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(accounts)
+}
+
+func (h *AccountHandler) GetAccount(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	accountID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid account ID", http.StatusBadRequest)
+		return
+	}
+
+	account, err := h.Store.GetAccount(userID, accountID)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "Account not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error fetching account", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(account)
+}
+
+func (h *AccountHandler) UpdateAccount(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	accountID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid account ID", http.StatusBadRequest)
+		return
+	}
+
+	var req AccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	if err := req.validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	account := &models.Account{ID: accountID, UserID: userID}
+	req.apply(account)
+
+	err = h.Store.UpdateAccount(account)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "Account not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error updating account", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(account)
+}
+
+// DeleteAccount removes the account; its trades and executions are kept and
+// become unassigned.
+func (h *AccountHandler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	accountID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid account ID", http.StatusBadRequest)
+		return
+	}
+
+	err = h.Store.DeleteAccount(userID, accountID)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "Account not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error deleting account", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
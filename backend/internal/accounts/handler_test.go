@@ -0,0 +1,98 @@
+package accounts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/drewbuiltit/trading-journal/backend/internal/auth"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newAccountRequest(t *testing.T, method, url string, body []byte, userID int, vars map[string]string) *http.Request {
+	req, err := http.NewRequest(method, url, bytes.NewBuffer(body))
+	assert.NoError(t, err)
+	req = req.WithContext(context.WithValue(req.Context(), auth.UserContextKey, userID))
+	if vars != nil {
+		req = mux.SetURLVars(req, vars)
+	}
+	return req
+}
+
+func TestAccountHandler(t *testing.T) {
+	accountHandler := &AccountHandler{Store: store.NewMemoryStore()}
+
+	t.Run("Creation", func(t *testing.T) {
+		for _, req := range []AccountRequest{
+			{Name: "Roth IRA", Broker: "Schwab", Type: "IRA"},
+			{Name: "Futures", Broker: "AMP", Number: " 12345 ", Type: "futures"},
+		} {
+			body, _ := json.Marshal(req)
+			rr := httptest.NewRecorder()
+			accountHandler.CreateAccount(rr, newAccountRequest(t, "POST", "/accounts", body, 1, nil))
+			assert.Equal(t, http.StatusCreated, rr.Code)
+		}
+
+		rr := httptest.NewRecorder()
+		accountHandler.ListAccounts(rr, newAccountRequest(t, "GET", "/accounts", nil, 1, nil))
+
+		var accounts []models.Account
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &accounts))
+		assert.Len(t, accounts, 2)
+		assert.Equal(t, "Futures", accounts[0].Name)
+		assert.Equal(t, "12345", accounts[0].Number)
+		assert.Equal(t, models.AccountIRA, accounts[1].Type)
+	})
+
+	t.Run("Creation with Invalid Type", func(t *testing.T) {
+		body, _ := json.Marshal(AccountRequest{Name: "Crypto", Type: "wallet"})
+
+		rr := httptest.NewRecorder()
+		accountHandler.CreateAccount(rr, newAccountRequest(t, "POST", "/accounts", body, 1, nil))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, "type must be cash, margin, ira, futures or other\n", rr.Body.String())
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		body, _ := json.Marshal(AccountRequest{Name: "Roth IRA", Broker: "Fidelity", Type: "ira"})
+
+		rr := httptest.NewRecorder()
+		accountHandler.UpdateAccount(rr, newAccountRequest(t, "PUT", "/accounts/1", body, 1, map[string]string{"id": "1"}))
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		rr = httptest.NewRecorder()
+		accountHandler.GetAccount(rr, newAccountRequest(t, "GET", "/accounts/1", nil, 1, map[string]string{"id": "1"}))
+
+		var account models.Account
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &account))
+		assert.Equal(t, "Fidelity", account.Broker)
+	})
+
+	t.Run("Scoped to User", func(t *testing.T) {
+		vars := map[string]string{"id": "1"}
+
+		rr := httptest.NewRecorder()
+		accountHandler.GetAccount(rr, newAccountRequest(t, "GET", "/accounts/1", nil, 2, vars))
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+
+		body, _ := json.Marshal(AccountRequest{Name: "Mine now"})
+		rr = httptest.NewRecorder()
+		accountHandler.UpdateAccount(rr, newAccountRequest(t, "PUT", "/accounts/1", body, 2, vars))
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+
+		rr = httptest.NewRecorder()
+		accountHandler.DeleteAccount(rr, newAccountRequest(t, "DELETE", "/accounts/1", nil, 2, vars))
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		accountHandler.DeleteAccount(rr, newAccountRequest(t, "DELETE", "/accounts/1", nil, 1, map[string]string{"id": "1"}))
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+	})
+}
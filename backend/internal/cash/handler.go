@@ -0,0 +1,338 @@
+package cash
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/drewbuiltit/trading-journal/backend/internal/accounts"
+	"github.com/drewbuiltit/trading-journal/backend/internal/auth"
+	"github.com/drewbuiltit/trading-journal/backend/internal/instruments"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"github.com/gorilla/mux"
+	"github.com/shopspring/decimal"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type CashHandler struct {
+	Store store.Store
+}
+
+type CashTransactionRequest struct {
+	AccountID   *int            `json:"account_id,omitempty"`
+	Type        string          `json:"type"`
+	Symbol      string          `json:"symbol,omitempty"`
+	Amount      decimal.Decimal `json:"amount"`
+	Currency    string          `json:"currency,omitempty"`
+	PostedAt    time.Time       `json:"posted_at"`
+	Description string          `json:"description,omitempty"`
+}
+
+func (req *CashTransactionRequest) validate() error {
+	if !models.ValidCashType(strings.ToLower(req.Type)) {
+		return errors.New("type must be deposit, withdrawal, dividend, interest, fee or other")
+	}
+	if req.Amount.IsZero() {
+		return errors.New("amount is required")
+	}
+	if req.Currency != "" && !instruments.ValidCurrency(strings.ToUpper(req.Currency)) {
+		return errors.New("currency must be a three-letter currency code")
+	}
+	return nil
+}
+
+// apply copies the request onto transaction. A deposit always adds to the
+// account and a withdrawal always takes from it, whichever sign the amount
+// was given with; other types keep theirs, as interest or a dividend can be
+// charged as well as paid. Without a currency the transaction is in the
+// account's.
+func (req *CashTransactionRequest) apply(transaction *models.CashTransaction, account *models.Account) {
+	transaction.AccountID = req.AccountID
+	transaction.Type = strings.ToLower(req.Type)
+	transaction.Symbol = ""
+	if req.Symbol != "" {
+		transaction.Symbol = instruments.Normalize(req.Symbol)
+	}
+	transaction.Amount = req.Amount
+	switch transaction.Type {
+	case models.CashDeposit:
+		transaction.Amount = req.Amount.Abs()
+	case models.CashWithdrawal:
+		transaction.Amount = req.Amount.Abs().Neg()
+	}
+	transaction.Currency = strings.ToUpper(req.Currency)
+	if transaction.Currency == "" {
+		transaction.Currency = models.DefaultCurrency
+		if account != nil && account.Currency != "" {
+			transaction.Currency = account.Currency
+		}
+	}
+	transaction.PostedAt = req.PostedAt
+	if transaction.PostedAt.IsZero() {
+		transaction.PostedAt = time.Now().UTC()
+	}
+	transaction.Description = strings.TrimSpace(req.Description)
+}
+
+// ListTransactions returns the user's cash transactions oldest first,
+// optionally of one ?type= and for the accounts in ?account=.
+func (h *CashHandler) ListTransactions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	accountFilter, err := accounts.ParseFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	cashType := strings.ToLower(r.URL.Query().Get("type"))
+	if cashType != "" && !models.ValidCashType(cashType) {
+		http.Error(w, "type must be deposit, withdrawal, dividend, interest, fee or other", http.StatusBadRequest)
+		return
+	}
+
+	transactions, err := h.Store.ListCashTransactions(userID)
+	if err != nil {
+		http.Error(w, "Error listing cash transactions", http.StatusInternalServerError)
+		return
+	}
+
+	filtered := []models.CashTransaction{}
+	for _, transaction := range transactions {
+		if cashType != "" && transaction.Type != cashType {
+			continue
+		}
+		if !accountFilter.Match(transaction.AccountID) {
+			continue
+		}
+		filtered = append(filtered, transaction)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(filtered)
+}
+
+func (h *CashHandler) CreateTransaction(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	var req CashTransactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	if err := req.validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	account, ok := h.loadAccount(w, userID, req.AccountID)
+	if !ok {
+		return
+	}
+
+	transaction := &models.CashTransaction{UserID: userID}
+	req.apply(transaction, account)
+
+	if err := h.Store.CreateCashTransaction(transaction); err != nil {
+		http.Error(w, "Error creating cash transaction", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(transaction)
+}
+
+// UpdateTransaction replaces a cash transaction; one that was imported keeps
+// its fingerprint and batch.
+func (h *CashHandler) UpdateTransaction(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	transactionID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid cash transaction ID", http.StatusBadRequest)
+		return
+	}
+
+	var req CashTransactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	if err := req.validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	account, ok := h.loadAccount(w, userID, req.AccountID)
+	if !ok {
+		return
+	}
+
+	transaction := &models.CashTransaction{ID: transactionID, UserID: userID}
+	req.apply(transaction, account)
+
+	err = h.Store.UpdateCashTransaction(transaction)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "Cash transaction not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error updating cash transaction", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transaction)
+}
+
+func (h *CashHandler) DeleteTransaction(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	transactionID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid cash transaction ID", http.StatusBadRequest)
+		return
+	}
+
+	err = h.Store.DeleteCashTransaction(userID, transactionID)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "Cash transaction not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error deleting cash transaction", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetLedger returns the user's cash transactions oldest first, each with the
+// running balance of its account and currency, for the accounts in
+// ?account= and posted between ?from= and ?to=. Balances count every
+// transaction, including those before ?from=.
+func (h *CashHandler) GetLedger(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	accountFilter, err := accounts.ParseFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	from, to, err := parseRange(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	transactions, err := h.Store.ListCashTransactions(userID)
+	if err != nil {
+		http.Error(w, "Error listing cash transactions", http.StatusInternalServerError)
+		return
+	}
+
+	entries := []Entry{}
+	for _, entry := range Ledger(transactions) {
+		if !accountFilter.Match(entry.AccountID) {
+			continue
+		}
+		date := truncate(entry.PostedAt)
+		if (from != nil && date.Before(*from)) || (to != nil && date.After(*to)) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
+
+// GetReturns reports equity and its time- and money-weighted returns in the
+// user's base currency, for the accounts in ?account= and between ?from= and
+// ?to=; see ReturnsForUser.
+func (h *CashHandler) GetReturns(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	accountFilter, err := accounts.ParseFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	from, to, err := parseRange(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	returns, err := ReturnsForUser(h.Store, userID, accountFilter, from, to)
+	if err != nil {
+		http.Error(w, "Error computing returns", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(returns)
+}
+
+func (h *CashHandler) loadAccount(w http.ResponseWriter, userID int, accountID *int) (*models.Account, bool) {
+	if accountID == nil {
+		return nil, true
+	}
+	account, err := h.Store.GetAccount(userID, *accountID)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "Account not found", http.StatusBadRequest)
+		return nil, false
+	}
+	if err != nil {
+		http.Error(w, "Error fetching account", http.StatusInternalServerError)
+		return nil, false
+	}
+	return account, true
+}
+
+// parseRange reads ?from= and ?to= as inclusive YYYY-MM-DD dates, either of
+// which may be left out.
+func parseRange(query url.Values) (*time.Time, *time.Time, error) {
+	var bounds [2]*time.Time
+	for i, name := range []string{"from", "to"} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s must be YYYY-MM-DD", name)
+		}
+		bounds[i] = &date
+	}
+	if bounds[0] != nil && bounds[1] != nil && bounds[1].Before(*bounds[0]) {
+		return nil, nil, errors.New("to must not be before from")
+	}
+	return bounds[0], bounds[1], nil
+}
@@ -0,0 +1,159 @@
+package cash
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/drewbuiltit/trading-journal/backend/internal/auth"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"github.com/gorilla/mux"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newCashRequest(t *testing.T, method, url string, body []byte, userID int) *http.Request {
+	req, err := http.NewRequest(method, url, bytes.NewBuffer(body))
+	assert.NoError(t, err)
+	return req.WithContext(context.WithValue(req.Context(), auth.UserContextKey, userID))
+}
+
+func TestListTransactionsHandler(t *testing.T) {
+	cashHandler := &CashHandler{Store: store.NewMemoryStore()}
+	accountID := 1
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	for _, transaction := range []models.CashTransaction{
+		{UserID: 1, AccountID: &accountID, Type: models.CashDividend, Symbol: "AAPL", Amount: decimal.NewFromInt(24), PostedAt: day.AddDate(0, 0, 2)},
+		{UserID: 1, AccountID: &accountID, Type: models.CashDeposit, Amount: decimal.NewFromInt(5000), PostedAt: day},
+		{UserID: 1, Type: models.CashDeposit, Amount: decimal.NewFromInt(100), PostedAt: day},
+		{UserID: 2, Type: models.CashDeposit, Amount: decimal.NewFromInt(1), PostedAt: day},
+	} {
+		assert.NoError(t, cashHandler.Store.CreateCashTransaction(&transaction))
+	}
+
+	list := func(url string) []models.CashTransaction {
+		rr := httptest.NewRecorder()
+		cashHandler.ListTransactions(rr, newCashRequest(t, "GET", url, nil, 1))
+		assert.Equal(t, http.StatusOK, rr.Code, url)
+		var transactions []models.CashTransaction
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &transactions))
+		return transactions
+	}
+
+	transactions := list("/cash-transactions")
+	assert.Len(t, transactions, 3)
+	assert.Equal(t, models.CashDeposit, transactions[0].Type)
+	assert.Equal(t, models.CashDividend, transactions[2].Type)
+
+	assert.Len(t, list("/cash-transactions?type=deposit"), 2)
+	assert.Len(t, list("/cash-transactions?account=1"), 2)
+	assert.Len(t, list("/cash-transactions?account=none"), 1)
+
+	rr := httptest.NewRecorder()
+	cashHandler.ListTransactions(rr, newCashRequest(t, "GET", "/cash-transactions?type=bonus", nil, 1))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestCashTransactionCRUDHandler(t *testing.T) {
+	cashHandler := &CashHandler{Store: store.NewMemoryStore()}
+	account := &models.Account{UserID: 1, Name: "TFSA", Currency: "CAD"}
+	assert.NoError(t, cashHandler.Store.CreateAccount(account))
+
+	body := fmt.Sprintf(`{"account_id":%d,"type":"withdrawal","amount":"250","posted_at":"2024-05-01T00:00:00Z"}`, account.ID)
+	rr := httptest.NewRecorder()
+	cashHandler.CreateTransaction(rr, newCashRequest(t, "POST", "/cash-transactions", []byte(body), 1))
+	assert.Equal(t, http.StatusCreated, rr.Code)
+
+	var created models.CashTransaction
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &created))
+	assert.Equal(t, "-250", created.Amount.String(), "a withdrawal always takes money out")
+	assert.Equal(t, "CAD", created.Currency, "the account's currency is the default")
+
+	tests := map[string]string{
+		`{"type":"bonus","amount":"1"}`:                   "type must be deposit, withdrawal, dividend, interest, fee or other\n",
+		`{"type":"deposit","amount":"0"}`:                 "amount is required\n",
+		`{"type":"deposit","amount":"1","account_id":99}`: "Account not found\n",
+	}
+	for body, message := range tests {
+		rr = httptest.NewRecorder()
+		cashHandler.CreateTransaction(rr, newCashRequest(t, "POST", "/cash-transactions", []byte(body), 1))
+		assert.Equal(t, http.StatusBadRequest, rr.Code, body)
+		assert.Equal(t, message, rr.Body.String())
+	}
+
+	id := fmt.Sprint(created.ID)
+	update := func(body string, userID int) *httptest.ResponseRecorder {
+		req := mux.SetURLVars(newCashRequest(t, "PUT", "/cash-transactions/"+id, []byte(body), userID), map[string]string{"id": id})
+		rr := httptest.NewRecorder()
+		cashHandler.UpdateTransaction(rr, req)
+		return rr
+	}
+	rr = update(`{"type":"interest","amount":"-3.2","currency":"usd","posted_at":"2024-05-31T00:00:00Z"}`, 1)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	fetched, err := cashHandler.Store.GetCashTransaction(1, created.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "-3.2", fetched.Amount.String(), "interest can be charged")
+	assert.Equal(t, "USD", fetched.Currency)
+	assert.Equal(t, http.StatusNotFound, update(`{"type":"interest","amount":"1"}`, 2).Code)
+
+	remove := func(userID int) int {
+		req := mux.SetURLVars(newCashRequest(t, "DELETE", "/cash-transactions/"+id, nil, userID), map[string]string{"id": id})
+		rr := httptest.NewRecorder()
+		cashHandler.DeleteTransaction(rr, req)
+		return rr.Code
+	}
+	assert.Equal(t, http.StatusNotFound, remove(2))
+	assert.Equal(t, http.StatusNoContent, remove(1))
+	assert.Equal(t, http.StatusNotFound, remove(1))
+}
+
+func TestLedgerAndReturnsHandler(t *testing.T) {
+	cashHandler := &CashHandler{Store: store.NewMemoryStore()}
+	assert.NoError(t, cashHandler.Store.CreateUser(&models.User{Username: "jane_doe", Email: "jane@example.com"}))
+	accountID := 1
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, transaction := range []models.CashTransaction{
+		{UserID: 1, AccountID: &accountID, Type: models.CashDeposit, Amount: decimal.NewFromInt(1000), Currency: "USD", PostedAt: day},
+		{UserID: 1, AccountID: &accountID, Type: models.CashInterest, Amount: decimal.NewFromInt(5), Currency: "USD", PostedAt: day.AddDate(0, 1, 0)},
+		{UserID: 1, Type: models.CashDeposit, Amount: decimal.NewFromInt(100), Currency: "USD", PostedAt: day},
+	} {
+		assert.NoError(t, cashHandler.Store.CreateCashTransaction(&transaction))
+	}
+	for _, execution := range []models.Execution{
+		{UserID: 1, AccountID: &accountID, Symbol: "AAPL", Side: models.SideBuy, Quantity: decimal.NewFromInt(10), Price: decimal.NewFromInt(50), ExecutedAt: day.AddDate(0, 2, 0)},
+		{UserID: 1, AccountID: &accountID, Symbol: "AAPL", Side: models.SideSell, Quantity: decimal.NewFromInt(10), Price: decimal.NewFromInt(60), ExecutedAt: day.AddDate(1, 0, 0)},
+	} {
+		execution := execution
+		assert.NoError(t, cashHandler.Store.CreateExecution(&execution))
+	}
+
+	rr := httptest.NewRecorder()
+	cashHandler.GetLedger(rr, newCashRequest(t, "GET", "/cash-transactions/ledger?account=1&from=2024-01-15", nil, 1))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var entries []Entry
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &entries))
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "1005", entries[0].Balance.String(), "the balance counts transactions before the range")
+
+	rr = httptest.NewRecorder()
+	cashHandler.GetReturns(rr, newCashRequest(t, "GET", "/returns?account=1", nil, 1))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var returns Returns
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &returns))
+	assert.Equal(t, "USD", returns.BaseCurrency)
+	assert.Equal(t, "1000", returns.NetContributions.String())
+	assert.Equal(t, "5", returns.Income.String())
+	assert.Equal(t, "100", returns.RealizedPnL.String())
+	assert.Equal(t, "1105", returns.EndingEquity.String())
+	assert.Equal(t, "0.105", returns.TimeWeightedReturn.String())
+
+	rr = httptest.NewRecorder()
+	cashHandler.GetReturns(rr, newCashRequest(t, "GET", "/returns?from=2024-02-01&to=2024-01-01", nil, 1))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, "to must not be before from\n", rr.Body.String())
+}
@@ -0,0 +1,56 @@
+package cash
+
+import (
+	"fmt"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/shopspring/decimal"
+	"sort"
+	"strings"
+)
+
+// Entry is a cash transaction with the balance of its account, in its
+// currency, once it has posted.
+type Entry struct {
+	models.CashTransaction
+	Balance decimal.Decimal `json:"balance"`
+}
+
+// Ledger runs a balance through the transactions, oldest first, separately
+// for each account and currency. Transactions with no currency are taken to
+// be in the default currency.
+func Ledger(transactions []models.CashTransaction) []Entry {
+	sorted := make([]models.CashTransaction, len(transactions))
+	copy(sorted, transactions)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].PostedAt.Equal(sorted[j].PostedAt) {
+			return sorted[i].PostedAt.Before(sorted[j].PostedAt)
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	balances := make(map[string]decimal.Decimal)
+	entries := make([]Entry, 0, len(sorted))
+	for _, transaction := range sorted {
+		key := currencyOf(transaction)
+		if transaction.AccountID != nil {
+			key = fmt.Sprintf("%d:%s", *transaction.AccountID, key)
+		}
+		balances[key] = balances[key].Add(transaction.Amount)
+		entries = append(entries, Entry{CashTransaction: transaction, Balance: balances[key]})
+	}
+	return entries
+}
+
+// External reports whether a transaction moves money into or out of the
+// journal rather than earning or costing it, which return calculations treat
+// as a contribution instead of a gain.
+func External(transaction models.CashTransaction) bool {
+	return transaction.Type == models.CashDeposit || transaction.Type == models.CashWithdrawal
+}
+
+func currencyOf(transaction models.CashTransaction) string {
+	if transaction.Currency == "" {
+		return models.DefaultCurrency
+	}
+	return strings.ToUpper(transaction.Currency)
+}
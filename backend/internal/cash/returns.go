@@ -0,0 +1,264 @@
+package cash
+
+import (
+	"github.com/drewbuiltit/trading-journal/backend/internal/accounts"
+	"github.com/drewbuiltit/trading-journal/backend/internal/fx"
+	"github.com/drewbuiltit/trading-journal/backend/internal/lots"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/drewbuiltit/trading-journal/backend/internal/positions"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"github.com/shopspring/decimal"
+	"math"
+	"sort"
+	"time"
+)
+
+// EquityPoint is the equity at the end of a day on which it changed, and the
+// contributions, net of withdrawals, made that day.
+type EquityPoint struct {
+	Date   time.Time       `json:"date"`
+	Flow   decimal.Decimal `json:"flow"`
+	Equity decimal.Decimal `json:"equity"`
+}
+
+// Returns reports how equity moved over a period, in the base currency.
+// Equity is the sum of contributions, income and realized P&L; open
+// positions are carried at cost. The time-weighted return chains daily
+// returns so that the timing of contributions does not affect it, while the
+// money-weighted return is the annualized internal rate of return of the
+// contributions and the ending equity. Either is omitted when the period
+// does not define it.
+type Returns struct {
+	BaseCurrency        string           `json:"base_currency"`
+	From                *time.Time       `json:"from,omitempty"`
+	To                  *time.Time       `json:"to,omitempty"`
+	StartingEquity      decimal.Decimal  `json:"starting_equity"`
+	NetContributions    decimal.Decimal  `json:"net_contributions"`
+	Income              decimal.Decimal  `json:"income"`
+	RealizedPnL         decimal.Decimal  `json:"realized_pnl"`
+	EndingEquity        decimal.Decimal  `json:"ending_equity"`
+	TimeWeightedReturn  *decimal.Decimal `json:"time_weighted_return"`
+	MoneyWeightedReturn *decimal.Decimal `json:"money_weighted_return"`
+	// FXRateMissing is set when an amount had no rate into the base currency
+	// on its date and was left out.
+	FXRateMissing bool          `json:"fx_rate_missing,omitempty"`
+	Equity        []EquityPoint `json:"equity"`
+}
+
+// ReturnsForUser loads the user's cash transactions and positions in the
+// accounts the filter matches and computes their returns.
+func ReturnsForUser(s store.Store, userID int, filter accounts.Filter, from, to *time.Time) (Returns, error) {
+	config, err := lots.ConfigForUser(s, userID)
+	if err != nil {
+		return Returns{}, err
+	}
+	fills, err := positions.Fills(s, userID)
+	if err != nil {
+		return Returns{}, err
+	}
+	base, table, err := fx.ForUser(s, userID)
+	if err != nil {
+		return Returns{}, err
+	}
+	transactions, err := s.ListCashTransactions(userID)
+	if err != nil {
+		return Returns{}, err
+	}
+
+	built := []positions.Position{}
+	for _, position := range positions.ConvertPnL(positions.Build(fills, config), table, base) {
+		if filter.Match(position.AccountID) {
+			built = append(built, position)
+		}
+	}
+	matched := []models.CashTransaction{}
+	for _, transaction := range transactions {
+		if filter.Match(transaction.AccountID) {
+			matched = append(matched, transaction)
+		}
+	}
+	return ComputeReturns(matched, built, table, base, from, to), nil
+}
+
+// day is the change in equity on one calendar day, UTC.
+type day struct {
+	date     time.Time
+	flow     decimal.Decimal
+	income   decimal.Decimal
+	realized decimal.Decimal
+}
+
+// ComputeReturns works out the returns between from and to, both whole days
+// and either open-ended when nil, from the user's cash transactions and the
+// lot matches of their ungrouped positions, P&L already converted with
+// positions.ConvertPnL.
+func ComputeReturns(transactions []models.CashTransaction, built []positions.Position, table *fx.Table, base string, from, to *time.Time) Returns {
+	returns := Returns{
+		BaseCurrency:     base,
+		From:             from,
+		To:               to,
+		StartingEquity:   decimal.Zero,
+		NetContributions: decimal.Zero,
+		Income:           decimal.Zero,
+		RealizedPnL:      decimal.Zero,
+		Equity:           []EquityPoint{},
+	}
+
+	days := make(map[time.Time]*day)
+	on := func(at time.Time) *day {
+		date := truncate(at)
+		if days[date] == nil {
+			days[date] = &day{date: date}
+		}
+		return days[date]
+	}
+
+	for _, transaction := range transactions {
+		rate, ok := table.Rate(currencyOf(transaction), base, transaction.PostedAt)
+		if !ok {
+			returns.FXRateMissing = true
+			continue
+		}
+		amount := transaction.Amount.Mul(rate)
+		d := on(transaction.PostedAt)
+		if External(transaction) {
+			d.flow = d.flow.Add(amount)
+		} else {
+			d.income = d.income.Add(amount)
+		}
+	}
+	for _, position := range built {
+		if position.FXRateMissing {
+			returns.FXRateMissing = true
+		}
+		for _, match := range position.Matches {
+			if !match.FXRate.IsPositive() {
+				continue
+			}
+			d := on(match.ClosedAt)
+			d.realized = d.realized.Add(match.BaseNetPnL)
+		}
+	}
+
+	ordered := make([]*day, 0, len(days))
+	for _, d := range days {
+		ordered = append(ordered, d)
+	}
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i].date.Before(ordered[j].date) })
+
+	equity := decimal.Zero
+	growth := decimal.NewFromInt(1)
+	chained := false
+	flows := []flow{}
+	var first, last time.Time
+	for _, d := range ordered {
+		change := d.flow.Add(d.income).Add(d.realized)
+		if from != nil && d.date.Before(*from) {
+			equity = equity.Add(change)
+			returns.StartingEquity = equity
+			continue
+		}
+		if to != nil && d.date.After(*to) {
+			break
+		}
+		if first.IsZero() {
+			first = d.date
+		}
+		last = d.date
+
+		// Contributions are taken to arrive at the start of the day, so the
+		// day's return is on the equity before it plus that day's flow.
+		invested := equity.Add(d.flow)
+		equity = equity.Add(change)
+		if invested.IsPositive() {
+			growth = growth.Mul(equity.Div(invested))
+			chained = true
+		}
+
+		returns.NetContributions = returns.NetContributions.Add(d.flow)
+		returns.Income = returns.Income.Add(d.income)
+		returns.RealizedPnL = returns.RealizedPnL.Add(d.realized)
+		if !d.flow.IsZero() {
+			flows = append(flows, flow{date: d.date, amount: d.flow.Neg()})
+		}
+		returns.Equity = append(returns.Equity, EquityPoint{Date: d.date, Flow: d.flow, Equity: equity})
+	}
+	returns.EndingEquity = returns.StartingEquity.Add(returns.NetContributions).Add(returns.Income).Add(returns.RealizedPnL)
+
+	if chained {
+		twr := growth.Sub(decimal.NewFromInt(1)).Round(6)
+		returns.TimeWeightedReturn = &twr
+	}
+
+	start, end := first, last
+	if from != nil {
+		start = *from
+	}
+	if to != nil {
+		end = *to
+	}
+	if !returns.StartingEquity.IsZero() {
+		flows = append([]flow{{date: start, amount: returns.StartingEquity.Neg()}}, flows...)
+	}
+	flows = append(flows, flow{date: end, amount: returns.EndingEquity})
+	if mwr, ok := irr(flows); ok {
+		rate := decimal.NewFromFloat(mwr).Round(6)
+		returns.MoneyWeightedReturn = &rate
+	}
+
+	return returns
+}
+
+// flow is a cash flow from the investor's side: contributions are negative
+// and the ending equity positive.
+type flow struct {
+	date   time.Time
+	amount decimal.Decimal
+}
+
+// irr finds the annual rate that discounts the flows to zero by bisection.
+// It needs flows of both signs spread over at least a day.
+func irr(flows []flow) (float64, bool) {
+	if len(flows) < 2 || !flows[len(flows)-1].date.After(flows[0].date) {
+		return 0, false
+	}
+	positive, negative := false, false
+	for _, f := range flows {
+		positive = positive || f.amount.IsPositive()
+		negative = negative || f.amount.IsNegative()
+	}
+	if !positive || !negative {
+		return 0, false
+	}
+
+	npv := func(rate float64) float64 {
+		total := 0.0
+		for _, f := range flows {
+			years := f.date.Sub(flows[0].date).Hours() / 24 / 365
+			total += f.amount.InexactFloat64() / math.Pow(1+rate, years)
+		}
+		return total
+	}
+
+	low, high := -0.999999, 1.0
+	for npv(low)*npv(high) > 0 {
+		high *= 2
+		if high > 1e6 {
+			return 0, false
+		}
+	}
+	for i := 0; i < 200; i++ {
+		mid := (low + high) / 2
+		if npv(low)*npv(mid) <= 0 {
+			high = mid
+		} else {
+			low = mid
+		}
+	}
+	return (low + high) / 2, true
+}
+
+func truncate(at time.Time) time.Time {
+	at = at.UTC()
+	return time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,95 @@
+package cash
+
+import (
+	"github.com/drewbuiltit/trading-journal/backend/internal/fx"
+	"github.com/drewbuiltit/trading-journal/backend/internal/lots"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/drewbuiltit/trading-journal/backend/internal/positions"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func dec(value string) decimal.Decimal {
+	return decimal.RequireFromString(value)
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func realized(closedAt time.Time, pnl string) positions.Position {
+	return positions.Position{Matches: []lots.Match{{ClosedAt: closedAt, FXRate: dec("1"), BaseNetPnL: dec(pnl)}}}
+}
+
+func TestLedger(t *testing.T) {
+	ira := 1
+	entries := Ledger([]models.CashTransaction{
+		{ID: 3, AccountID: &ira, Type: models.CashWithdrawal, Amount: dec("-200"), Currency: "USD", PostedAt: date(2024, 3, 1)},
+		{ID: 1, AccountID: &ira, Type: models.CashDeposit, Amount: dec("1000"), Currency: "USD", PostedAt: date(2024, 1, 1)},
+		{ID: 2, Type: models.CashDeposit, Amount: dec("50"), PostedAt: date(2024, 2, 1)},
+		{ID: 4, AccountID: &ira, Type: models.CashDividend, Amount: dec("12.5"), Currency: "CAD", PostedAt: date(2024, 3, 2)},
+	})
+
+	assert.Len(t, entries, 4)
+	assert.Equal(t, 1, entries[0].ID)
+	assert.Equal(t, "1000", entries[0].Balance.String())
+	assert.Equal(t, "50", entries[1].Balance.String(), "unassigned cash has its own balance")
+	assert.Equal(t, "800", entries[2].Balance.String())
+	assert.Equal(t, "12.5", entries[3].Balance.String(), "each currency has its own balance")
+}
+
+func TestComputeReturns(t *testing.T) {
+	transactions := []models.CashTransaction{
+		{Type: models.CashDeposit, Amount: dec("1000"), PostedAt: date(2024, 1, 1)},
+		{Type: models.CashDeposit, Amount: dec("1100"), PostedAt: date(2024, 1, 20).Add(15 * time.Hour)},
+		{Type: models.CashDividend, Amount: dec("20"), PostedAt: date(2024, 1, 30)},
+	}
+	built := []positions.Position{realized(date(2024, 1, 10), "100"), realized(date(2024, 1, 30), "-240")}
+
+	returns := ComputeReturns(transactions, built, fx.NewTable(nil), "USD", nil, nil)
+	assert.Equal(t, "2100", returns.NetContributions.String())
+	assert.Equal(t, "20", returns.Income.String())
+	assert.Equal(t, "-140", returns.RealizedPnL.String())
+	assert.Equal(t, "1980", returns.EndingEquity.String())
+	assert.Len(t, returns.Equity, 4)
+	assert.Equal(t, "2200", returns.Equity[2].Equity.String())
+
+	// 1.1 on the first 1,000, then 0.9 on 2,200: the second deposit's
+	// size does not change the time-weighted return.
+	assert.Equal(t, "-0.01", returns.TimeWeightedReturn.String())
+	assert.True(t, returns.MoneyWeightedReturn.IsNegative(), "more money was lost than made")
+
+	from := date(2024, 1, 15)
+	returns = ComputeReturns(transactions, built, fx.NewTable(nil), "USD", &from, nil)
+	assert.Equal(t, "1100", returns.StartingEquity.String())
+	assert.Equal(t, "1100", returns.NetContributions.String())
+	assert.Equal(t, "-0.1", returns.TimeWeightedReturn.String())
+}
+
+func TestComputeReturns_MoneyWeighted(t *testing.T) {
+	transactions := []models.CashTransaction{{Type: models.CashDeposit, Amount: dec("1000"), PostedAt: date(2023, 1, 1)}}
+	built := []positions.Position{realized(date(2024, 1, 1), "100")}
+
+	returns := ComputeReturns(transactions, built, fx.NewTable(nil), "USD", nil, nil)
+	assert.Equal(t, "0.1", returns.TimeWeightedReturn.String())
+	assert.Equal(t, "0.1", returns.MoneyWeightedReturn.String())
+
+	returns = ComputeReturns(nil, nil, fx.NewTable(nil), "USD", nil, nil)
+	assert.Nil(t, returns.TimeWeightedReturn)
+	assert.Nil(t, returns.MoneyWeightedReturn)
+	assert.Empty(t, returns.Equity)
+}
+
+func TestComputeReturns_ConvertsCurrencies(t *testing.T) {
+	table := fx.NewTable([]models.FXRate{{Date: date(2024, 1, 1), Currency: "EUR", Quote: "USD", Rate: dec("1.1")}})
+	transactions := []models.CashTransaction{
+		{Type: models.CashDeposit, Amount: dec("1000"), Currency: "EUR", PostedAt: date(2024, 1, 2)},
+		{Type: models.CashDeposit, Amount: dec("1000"), Currency: "JPY", PostedAt: date(2024, 1, 2)},
+	}
+
+	returns := ComputeReturns(transactions, nil, table, "USD", nil, nil)
+	assert.Equal(t, "1100", returns.NetContributions.String())
+	assert.True(t, returns.FXRateMissing)
+}
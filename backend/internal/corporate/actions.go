@@ -0,0 +1,198 @@
+package corporate
+
+import (
+	"errors"
+	"fmt"
+	"github.com/drewbuiltit/trading-journal/backend/internal/instruments"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/shopspring/decimal"
+	"sort"
+	"strings"
+)
+
+// Normalize validates an action and puts its fields into canonical form:
+// symbols normalized, reverse_split and rename read as split and
+// symbol_change, and a symbol change given a ratio of one.
+func Normalize(action *models.CorporateAction) error {
+	action.Type = strings.ToLower(strings.TrimSpace(action.Type))
+	switch action.Type {
+	case "reverse_split":
+		action.Type = models.ActionSplit
+	case "rename":
+		action.Type = models.ActionSymbolChange
+	}
+	action.Symbol = strings.TrimSpace(action.Symbol)
+	action.NewSymbol = strings.TrimSpace(action.NewSymbol)
+	action.Note = strings.TrimSpace(action.Note)
+
+	if action.Symbol == "" {
+		return errors.New("symbol is required")
+	}
+	if action.Date.IsZero() {
+		return errors.New("date is required")
+	}
+	action.Symbol = instruments.Normalize(action.Symbol)
+	if action.NewSymbol != "" {
+		action.NewSymbol = instruments.Normalize(action.NewSymbol)
+	}
+	if action.Cash.IsNegative() {
+		return errors.New("cash must not be negative")
+	}
+
+	switch action.Type {
+	case models.ActionSplit:
+		if !hasRatio(*action) {
+			return errors.New("a split needs a positive ratio")
+		}
+		if action.NewSymbol != "" || !action.Cash.IsZero() {
+			return errors.New("a split takes no new_symbol or cash")
+		}
+	case models.ActionSymbolChange:
+		if action.NewSymbol == "" || action.NewSymbol == action.Symbol {
+			return errors.New("a symbol change needs a different new_symbol")
+		}
+		if !action.Cash.IsZero() {
+			return errors.New("a symbol change takes no cash")
+		}
+		action.NewShares, action.OldShares = 1, 1
+	case models.ActionMerger:
+		stock := hasRatio(*action)
+		cash := action.Cash.IsPositive()
+		if stock == cash {
+			return errors.New("a merger needs either a ratio and new_symbol or cash, not both")
+		}
+		if stock && (action.NewSymbol == "" || action.NewSymbol == action.Symbol) {
+			return errors.New("a stock merger needs a different new_symbol")
+		}
+		if cash {
+			action.NewShares, action.OldShares = 0, 0
+			action.NewSymbol = ""
+		}
+	default:
+		return fmt.Errorf("type must be %s, %s or %s", models.ActionSplit, models.ActionSymbolChange, models.ActionMerger)
+	}
+	return nil
+}
+
+func hasRatio(action models.CorporateAction) bool {
+	return action.NewShares > 0 && action.OldShares > 0
+}
+
+// Apply restates fills in the terms of the shares they became, so that
+// positions and lots match across the actions. Fills in an action's symbol
+// executed before its date are adjusted: a split scales quantity by the
+// ratio and price, and any planned prices, by its inverse, leaving the value
+// of each fill unchanged; a symbol change or stock merger also moves them to
+// the new symbol. A cash merger instead closes whatever is still held at the
+// cash price with a fill on the action's date. Actions apply in date order,
+// so a renamed symbol picks up later actions under its new name. The fills
+// passed in are not modified.
+func Apply(fills []models.Execution, actions []models.CorporateAction) []models.Execution {
+	if len(actions) == 0 {
+		return fills
+	}
+
+	adjusted := make([]models.Execution, len(fills))
+	copy(adjusted, fills)
+
+	ordered := make([]models.CorporateAction, len(actions))
+	copy(ordered, actions)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Date.Before(ordered[j].Date)
+	})
+
+	for _, action := range ordered {
+		if action.Type == models.ActionMerger && !hasRatio(action) {
+			adjusted = append(adjusted, cashOut(adjusted, action)...)
+			continue
+		}
+		for i := range adjusted {
+			fill := &adjusted[i]
+			if fill.Symbol != action.Symbol || !fill.ExecutedAt.Before(action.Date) {
+				continue
+			}
+			if action.NewShares != action.OldShares {
+				newShares, oldShares := decimal.NewFromInt(action.NewShares), decimal.NewFromInt(action.OldShares)
+				// Multiplying before dividing keeps whole results exact.
+				fill.Quantity = fill.Quantity.Mul(newShares).Div(oldShares)
+				fill.Price = fill.Price.Mul(oldShares).Div(newShares)
+				fill.Plan = fill.Plan.Adjust(oldShares, newShares)
+			}
+			if action.NewSymbol != "" {
+				fill.Symbol = action.NewSymbol
+			}
+		}
+	}
+	return adjusted
+}
+
+// holding is the net signed quantity held in one account, or one spread,
+// before a cash merger.
+type holding struct {
+	accountID *int
+	spreadID  *int
+	currency  string
+	quantity  decimal.Decimal
+}
+
+// cashOut returns the fills that close each holding of the action's symbol
+// at its cash price.
+func cashOut(fills []models.Execution, action models.CorporateAction) []models.Execution {
+	holdings := make(map[string]*holding)
+	for _, fill := range fills {
+		if fill.Symbol != action.Symbol || !fill.ExecutedAt.Before(action.Date) {
+			continue
+		}
+		key := holdingKey(fill.AccountID, fill.SpreadID)
+		if holdings[key] == nil {
+			holdings[key] = &holding{accountID: fill.AccountID, spreadID: fill.SpreadID, currency: fill.Currency}
+		}
+		quantity := fill.Quantity
+		if fill.Side == models.SideSell {
+			quantity = quantity.Neg()
+		}
+		holdings[key].quantity = holdings[key].quantity.Add(quantity)
+	}
+
+	keys := make([]string, 0, len(holdings))
+	for key := range holdings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	closes := []models.Execution{}
+	for _, key := range keys {
+		h := holdings[key]
+		if h.quantity.IsZero() {
+			continue
+		}
+		fill := models.Execution{
+			AccountID:  h.accountID,
+			SpreadID:   h.spreadID,
+			Symbol:     action.Symbol,
+			Side:       models.SideSell,
+			Action:     models.ActionSell,
+			Quantity:   h.quantity.Abs(),
+			Price:      action.Cash,
+			Currency:   h.currency,
+			ExecutedAt: action.Date,
+		}
+		if h.quantity.IsNegative() {
+			fill.Side = models.SideBuy
+			fill.Action = models.ActionBuyToCover
+		}
+		closes = append(closes, fill)
+	}
+	return closes
+}
+
+func holdingKey(accountID, spreadID *int) string {
+	key := ""
+	if accountID != nil {
+		key = fmt.Sprint(*accountID)
+	}
+	if spreadID != nil {
+		key = fmt.Sprintf("%s/%d", key, *spreadID)
+	}
+	return key
+}
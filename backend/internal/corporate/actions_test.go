@@ -0,0 +1,133 @@
+package corporate
+
+import (
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func dec(value string) decimal.Decimal {
+	return decimal.RequireFromString(value)
+}
+
+func day(d int) time.Time {
+	return time.Date(2024, 6, d, 0, 0, 0, 0, time.UTC)
+}
+
+func fill(id int, symbol, side, quantity, price string, executedAt time.Time) models.Execution {
+	return models.Execution{ID: id, UserID: 1, Symbol: symbol, Side: side, Quantity: dec(quantity), Price: dec(price), ExecutedAt: executedAt}
+}
+
+func TestApply_Split(t *testing.T) {
+	fills := []models.Execution{
+		fill(1, "NVDA", models.SideBuy, "10", "1200", day(3).Add(14*time.Hour)),
+		fill(2, "NVDA", models.SideSell, "40", "310", day(10).Add(14*time.Hour)),
+		fill(3, "AMD", models.SideBuy, "10", "160", day(3).Add(14*time.Hour)),
+	}
+	stop := dec("1100")
+	fills[0].StopLoss = &stop
+	actions := []models.CorporateAction{{Date: day(10), Type: models.ActionSplit, Symbol: "NVDA", NewShares: 4, OldShares: 1}}
+
+	adjusted := Apply(fills, actions)
+	assert.Equal(t, "40", adjusted[0].Quantity.String())
+	assert.Equal(t, "300", adjusted[0].Price.String())
+	assert.Equal(t, "275", adjusted[0].StopLoss.String(), "planned prices split with the price")
+	assert.Equal(t, "40", adjusted[1].Quantity.String(), "fills from the ex-date on are already in new shares")
+	assert.Equal(t, "10", adjusted[2].Quantity.String())
+	assert.Equal(t, "10", fills[0].Quantity.String(), "the fills passed in are left alone")
+	assert.Equal(t, "1100", fills[0].StopLoss.String())
+}
+
+func TestApply_ReverseSplitIsExact(t *testing.T) {
+	fills := []models.Execution{
+		fill(1, "XYZ", models.SideBuy, "300", "1", day(3)),
+		fill(2, "XYZ", models.SideSell, "100", "3.2", day(20)),
+		fill(3, "ABC", models.SideBuy, "300", "10", day(3)),
+	}
+	actions := []models.CorporateAction{
+		{Date: day(10), Type: models.ActionSplit, Symbol: "XYZ", NewShares: 1, OldShares: 3},
+		{Date: day(10), Type: models.ActionSplit, Symbol: "ABC", NewShares: 2, OldShares: 3},
+	}
+
+	adjusted := Apply(fills, actions)
+	assert.Equal(t, "100", adjusted[0].Quantity.String(), "no dust is left to sell")
+	assert.Equal(t, "3", adjusted[0].Price.String())
+	assert.Equal(t, "200", adjusted[2].Quantity.String())
+	assert.Equal(t, "15", adjusted[2].Price.String())
+}
+
+func TestApply_ReverseSplitThenRename(t *testing.T) {
+	fills := []models.Execution{
+		fill(1, "OLD", models.SideBuy, "100", "1.5", day(3)),
+		fill(2, "NEW", models.SideSell, "10", "16", day(20)),
+	}
+	actions := []models.CorporateAction{
+		{Date: day(15), Type: models.ActionSymbolChange, Symbol: "OLD", NewSymbol: "NEW", NewShares: 1, OldShares: 1},
+		{Date: day(10), Type: models.ActionSplit, Symbol: "OLD", NewShares: 1, OldShares: 10},
+	}
+
+	adjusted := Apply(fills, actions)
+	assert.Equal(t, "NEW", adjusted[0].Symbol)
+	assert.Equal(t, "10", adjusted[0].Quantity.String())
+	assert.Equal(t, "15", adjusted[0].Price.String())
+}
+
+func TestApply_StockMerger(t *testing.T) {
+	fills := []models.Execution{fill(1, "ATVI", models.SideBuy, "100", "90", day(3))}
+	actions := []models.CorporateAction{{Date: day(10), Type: models.ActionMerger, Symbol: "ATVI", NewSymbol: "MSFT", NewShares: 1, OldShares: 4}}
+
+	adjusted := Apply(fills, actions)
+	assert.Equal(t, "MSFT", adjusted[0].Symbol)
+	assert.Equal(t, "25", adjusted[0].Quantity.String())
+	assert.Equal(t, "360", adjusted[0].Price.String())
+}
+
+func TestApply_CashMergerClosesHoldings(t *testing.T) {
+	ira := 7
+	long := fill(1, "VMW", models.SideBuy, "30", "140", day(3))
+	long.AccountID = &ira
+	fills := []models.Execution{
+		long,
+		fill(2, "VMW", models.SideSell, "10", "150", day(4)),
+		fill(3, "VMW", models.SideBuy, "5", "151", day(5)),
+		fill(4, "VMW", models.SideSell, "5", "152", day(6)),
+	}
+	actions := []models.CorporateAction{{Date: day(10), Type: models.ActionMerger, Symbol: "VMW", Cash: dec("142.5")}}
+
+	adjusted := Apply(fills, actions)
+	assert.Len(t, adjusted, 6)
+
+	short := adjusted[4]
+	assert.Nil(t, short.AccountID)
+	assert.Equal(t, models.SideBuy, short.Side, "a short holding is bought back")
+	assert.Equal(t, "10", short.Quantity.String())
+	assert.Equal(t, day(10), short.ExecutedAt)
+
+	closed := adjusted[5]
+	assert.Equal(t, &ira, closed.AccountID)
+	assert.Equal(t, models.SideSell, closed.Side)
+	assert.Equal(t, "30", closed.Quantity.String())
+	assert.Equal(t, "142.5", closed.Price.String())
+}
+
+func TestNormalize(t *testing.T) {
+	action := models.CorporateAction{Date: day(1), Type: "Rename", Symbol: "fb", NewSymbol: "meta"}
+	assert.NoError(t, Normalize(&action))
+	assert.Equal(t, models.ActionSymbolChange, action.Type)
+	assert.Equal(t, "FB", action.Symbol)
+	assert.Equal(t, "META", action.NewSymbol)
+	assert.Equal(t, [2]int64{1, 1}, [2]int64{action.NewShares, action.OldShares})
+
+	tests := map[string]models.CorporateAction{
+		"symbol is required":                                             {Date: day(1), Type: models.ActionSplit, NewShares: 2, OldShares: 1},
+		"a split needs a positive ratio":                                 {Date: day(1), Type: models.ActionSplit, Symbol: "AAPL"},
+		"a symbol change needs a different new_symbol":                   {Date: day(1), Type: models.ActionSymbolChange, Symbol: "AAPL", NewSymbol: "aapl"},
+		"a merger needs either a ratio and new_symbol or cash, not both": {Date: day(1), Type: models.ActionMerger, Symbol: "X", NewSymbol: "Y", NewShares: 1, OldShares: 1, Cash: dec("5")},
+		"type must be split, symbol_change or merger":                    {Date: day(1), Type: "spinoff", Symbol: "X"},
+	}
+	for message, action := range tests {
+		assert.EqualError(t, Normalize(&action), message)
+	}
+}
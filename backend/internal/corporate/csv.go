@@ -0,0 +1,146 @@
+package corporate
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/shopspring/decimal"
+	"io"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// columnNames maps the accepted header names to the CorporateAction field
+// they fill.
+var columnNames = map[string]string{
+	"date":       "date",
+	"ex_date":    "date",
+	"type":       "type",
+	"action":     "type",
+	"symbol":     "symbol",
+	"new_symbol": "new_symbol",
+	"ratio":      "ratio",
+	"cash":       "cash",
+	"note":       "note",
+}
+
+// ParseCSV reads corporate actions from CSV with a header row naming the
+// date, type and symbol columns and, as the actions need them, new_symbol,
+// ratio, cash and note, in any order. Dates are YYYY-MM-DD. A ratio is
+// either new:old, as in 4:1 or 1:10, or the number of new shares per old.
+func ParseCSV(r io.Reader) ([]models.CorporateAction, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("CSV is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		if field, ok := columnNames[strings.ToLower(strings.TrimSpace(name))]; ok {
+			columns[field] = i
+		}
+	}
+	for _, field := range []string{"date", "type", "symbol"} {
+		if _, ok := columns[field]; !ok {
+			return nil, fmt.Errorf("CSV header is missing the %s column", field)
+		}
+	}
+
+	actions := []models.CorporateAction{}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		value := func(field string) string {
+			i, ok := columns[field]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		date, err := time.Parse("2006-01-02", value("date"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: date must be YYYY-MM-DD", line)
+		}
+		newShares, oldShares, err := ParseRatio(value("ratio"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		cash := decimal.Zero
+		if value("cash") != "" {
+			cash, err = decimal.NewFromString(value("cash"))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: cash must be a number", line)
+			}
+		}
+
+		action := models.CorporateAction{
+			Date:      date,
+			Type:      value("type"),
+			Symbol:    value("symbol"),
+			NewSymbol: value("new_symbol"),
+			NewShares: newShares,
+			OldShares: oldShares,
+			Cash:      cash,
+			Note:      value("note"),
+		}
+		if err := Normalize(&action); err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		actions = append(actions, action)
+	}
+
+	return actions, nil
+}
+
+// ParseRatio reads a ratio written as new:old or new/old, or as a plain
+// number of new shares per old share, and returns it as the number of new
+// shares for a number of old shares, in lowest terms. Blank is zero for zero.
+func ParseRatio(value string) (int64, int64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, 0, nil
+	}
+	invalid := errors.New("ratio must be a positive number or new:old")
+	parts := strings.FieldsFunc(value, func(r rune) bool { return r == ':' || r == '/' })
+	if len(parts) == 1 && !strings.ContainsAny(value, ":/") {
+		parts = append(parts, "1")
+	}
+	if len(parts) != 2 {
+		return 0, 0, invalid
+	}
+	shares, err := decimal.NewFromString(strings.TrimSpace(parts[0]))
+	if err != nil || !shares.IsPositive() {
+		return 0, 0, invalid
+	}
+	old, err := decimal.NewFromString(strings.TrimSpace(parts[1]))
+	if err != nil || !old.IsPositive() {
+		return 0, 0, invalid
+	}
+
+	// Scale both sides to whole numbers, then reduce.
+	places := max(-shares.Exponent(), -old.Exponent(), 0)
+	numerator := shares.Shift(places).BigInt()
+	denominator := old.Shift(places).BigInt()
+	gcd := new(big.Int).GCD(nil, nil, numerator, denominator)
+	numerator.Quo(numerator, gcd)
+	denominator.Quo(denominator, gcd)
+	if !numerator.IsInt64() || !denominator.IsInt64() {
+		return 0, 0, invalid
+	}
+	return numerator.Int64(), denominator.Int64(), nil
+}
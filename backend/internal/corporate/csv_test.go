@@ -0,0 +1,72 @@
+package corporate
+
+import (
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestParseCSV(t *testing.T) {
+	input := "Date,Type,Symbol,New_Symbol,Ratio,Cash,Note\n" +
+		"2024-06-10,split,nvda,,10:1,,ten for one\n" +
+		"2024-06-12,reverse_split,SIRI,,1:10,,\n" +
+		"2022-06-09,rename,FB,META,,,\n" +
+		"2023-11-22,merger,VMW,,,142.5,\n"
+	actions, err := ParseCSV(strings.NewReader(input))
+	assert.NoError(t, err)
+	assert.Len(t, actions, 4)
+
+	assert.Equal(t, "NVDA", actions[0].Symbol)
+	assert.Equal(t, int64(10), actions[0].NewShares)
+	assert.Equal(t, int64(1), actions[0].OldShares)
+	assert.Equal(t, "ten for one", actions[0].Note)
+	assert.Equal(t, models.ActionSplit, actions[1].Type)
+	assert.Equal(t, int64(1), actions[1].NewShares)
+	assert.Equal(t, int64(10), actions[1].OldShares)
+	assert.Equal(t, models.ActionSymbolChange, actions[2].Type)
+	assert.Equal(t, "META", actions[2].NewSymbol)
+	assert.Equal(t, "142.5", actions[3].Cash.String())
+
+	actions, err = ParseCSV(strings.NewReader("symbol,date,type,ratio\nAAPL,2020-08-31,split,4\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), actions[0].NewShares)
+	assert.Equal(t, int64(1), actions[0].OldShares)
+}
+
+func TestParseRatio(t *testing.T) {
+	tests := map[string][2]int64{
+		"4:1":   {4, 1},
+		"1/3":   {1, 3},
+		"6:9":   {2, 3},
+		"0.1":   {1, 10},
+		"1.5":   {3, 2},
+		"2.5:1": {5, 2},
+		"":      {0, 0},
+	}
+	for value, want := range tests {
+		newShares, oldShares, err := ParseRatio(value)
+		assert.NoError(t, err, value)
+		assert.Equal(t, want, [2]int64{newShares, oldShares}, value)
+	}
+
+	for _, value := range []string{"x", "0:1", "1:0", "-2", "1:2:3"} {
+		_, _, err := ParseRatio(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestParseCSV_Errors(t *testing.T) {
+	tests := map[string]string{
+		"":                    "CSV is empty",
+		"date,symbol,ratio\n": "CSV header is missing the type column",
+		"date,type,symbol,ratio\n08/31/2020,split,AAPL,4\n":                          "line 2: date must be YYYY-MM-DD",
+		"date,type,symbol,ratio\n2020-08-31,split,AAPL,4\n2020-08-31,split,TSLA,x\n": "line 3: ratio must be a positive number or new:old",
+		"date,type,symbol,ratio\n2020-08-31,split,AAPL,\n":                           "line 2: a split needs a positive ratio",
+	}
+
+	for input, message := range tests {
+		_, err := ParseCSV(strings.NewReader(input))
+		assert.EqualError(t, err, message)
+	}
+}
@@ -0,0 +1,174 @@
+package corporate
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/drewbuiltit/trading-journal/backend/internal/auth"
+	"github.com/drewbuiltit/trading-journal/backend/internal/instruments"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"github.com/gorilla/mux"
+	"github.com/shopspring/decimal"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type CorporateActionHandler struct {
+	Store store.Store
+}
+
+// CorporateActionRequest takes the ratio as text so that it can be written
+// as 4:1 as well as 4.
+type CorporateActionRequest struct {
+	Date      string          `json:"date"`
+	Type      string          `json:"type"`
+	Symbol    string          `json:"symbol"`
+	NewSymbol string          `json:"new_symbol,omitempty"`
+	Ratio     string          `json:"ratio,omitempty"`
+	Cash      decimal.Decimal `json:"cash"`
+	Note      string          `json:"note,omitempty"`
+}
+
+type ImportResponse struct {
+	Imported int `json:"imported"`
+}
+
+func (req *CorporateActionRequest) validate() error {
+	if _, err := time.Parse("2006-01-02", req.Date); err != nil {
+		return errors.New("date must be YYYY-MM-DD")
+	}
+	if _, _, err := ParseRatio(req.Ratio); err != nil {
+		return err
+	}
+	var action models.CorporateAction
+	req.apply(&action)
+	return Normalize(&action)
+}
+
+func (req *CorporateActionRequest) apply(action *models.CorporateAction) {
+	action.Date, _ = time.Parse("2006-01-02", req.Date)
+	action.Type = req.Type
+	action.Symbol = req.Symbol
+	action.NewSymbol = req.NewSymbol
+	action.NewShares, action.OldShares, _ = ParseRatio(req.Ratio)
+	action.Cash = req.Cash
+	action.Note = req.Note
+	Normalize(action)
+}
+
+// ListActions returns the user's corporate actions in date order, optionally
+// those of one ?symbol=, old or new.
+func (h *CorporateActionHandler) ListActions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	symbol := r.URL.Query().Get("symbol")
+	if symbol != "" {
+		symbol = instruments.Normalize(symbol)
+	}
+
+	actions, err := h.Store.ListCorporateActions(userID)
+	if err != nil {
+		http.Error(w, "Error listing corporate actions", http.StatusInternalServerError)
+		return
+	}
+
+	filtered := []models.CorporateAction{}
+	for _, action := range actions {
+		if symbol != "" && action.Symbol != symbol && action.NewSymbol != symbol {
+			continue
+		}
+		filtered = append(filtered, action)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(filtered)
+}
+
+// CreateAction records one corporate action, replacing any of the same type
+// for the symbol on that date.
+func (h *CorporateActionHandler) CreateAction(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	var req CorporateActionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	if err := req.validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	action := models.CorporateAction{UserID: userID}
+	req.apply(&action)
+
+	actions := []models.CorporateAction{action}
+	if err := h.Store.SaveCorporateActions(userID, actions); err != nil {
+		http.Error(w, "Error saving corporate action", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(actions[0])
+}
+
+// ImportActions loads corporate actions from a CSV request body; see
+// ParseCSV for the format. Actions already recorded for the same date, type
+// and symbol are replaced.
+func (h *CorporateActionHandler) ImportActions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	actions, err := ParseCSV(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Store.SaveCorporateActions(userID, actions); err != nil {
+		http.Error(w, "Error saving corporate actions", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ImportResponse{Imported: len(actions)})
+}
+
+func (h *CorporateActionHandler) DeleteAction(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	actionID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid corporate action ID", http.StatusBadRequest)
+		return
+	}
+
+	err = h.Store.DeleteCorporateAction(userID, actionID)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "Corporate action not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error deleting corporate action", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,69 @@
+package corporate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/drewbuiltit/trading-journal/backend/internal/auth"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newActionRequest(t *testing.T, method, url string, body []byte, userID int) *http.Request {
+	req, err := http.NewRequest(method, url, bytes.NewBuffer(body))
+	assert.NoError(t, err)
+	return req.WithContext(context.WithValue(req.Context(), auth.UserContextKey, userID))
+}
+
+func TestCorporateActionHandler(t *testing.T) {
+	actionHandler := &CorporateActionHandler{Store: store.NewMemoryStore()}
+
+	rr := httptest.NewRecorder()
+	actionHandler.CreateAction(rr, newActionRequest(t, "POST", "/corporate-actions", []byte(`{"date":"2020-08-31","type":"split","symbol":"aapl","ratio":"4:1"}`), 1))
+	assert.Equal(t, http.StatusCreated, rr.Code)
+
+	var created models.CorporateAction
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &created))
+	assert.Equal(t, "AAPL", created.Symbol)
+	assert.Equal(t, int64(4), created.NewShares)
+	assert.Equal(t, int64(1), created.OldShares)
+
+	rr = httptest.NewRecorder()
+	actionHandler.CreateAction(rr, newActionRequest(t, "POST", "/corporate-actions", []byte(`{"date":"2020-08-31","type":"split","symbol":"AAPL"}`), 1))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, "a split needs a positive ratio\n", rr.Body.String())
+
+	csv := "date,type,symbol,new_symbol\n2022-06-09,symbol_change,FB,META\n"
+	rr = httptest.NewRecorder()
+	actionHandler.ImportActions(rr, newActionRequest(t, "POST", "/corporate-actions/import", []byte(csv), 1))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "{\"imported\":1}\n", rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	actionHandler.ListActions(rr, newActionRequest(t, "GET", "/corporate-actions?symbol=meta", nil, 1))
+	var actions []models.CorporateAction
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &actions))
+	assert.Len(t, actions, 1, "a symbol matches actions that renamed to it")
+	assert.Equal(t, "FB", actions[0].Symbol)
+
+	rr = httptest.NewRecorder()
+	actionHandler.ListActions(rr, newActionRequest(t, "GET", "/corporate-actions", nil, 2))
+	assert.Equal(t, "[]\n", rr.Body.String())
+
+	del := func(id string, userID int) int {
+		req := mux.SetURLVars(newActionRequest(t, "DELETE", "/corporate-actions/"+id, nil, userID), map[string]string{"id": id})
+		rr := httptest.NewRecorder()
+		actionHandler.DeleteAction(rr, req)
+		return rr.Code
+	}
+	assert.Equal(t, http.StatusBadRequest, del("abc", 1))
+	assert.Equal(t, http.StatusNotFound, del(fmt.Sprint(created.ID), 2))
+	assert.Equal(t, http.StatusNoContent, del(fmt.Sprint(created.ID), 1))
+	assert.Equal(t, http.StatusNotFound, del(fmt.Sprint(created.ID), 1))
+}
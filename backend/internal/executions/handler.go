@@ -0,0 +1,355 @@
+package executions
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/drewbuiltit/trading-journal/backend/internal/accounts"
+	"github.com/drewbuiltit/trading-journal/backend/internal/auth"
+	"github.com/drewbuiltit/trading-journal/backend/internal/instruments"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/drewbuiltit/trading-journal/backend/internal/positions"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"github.com/gorilla/mux"
+	"github.com/shopspring/decimal"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type ExecutionHandler struct {
+	Store store.Store
+}
+
+type ExecutionRequest struct {
+	Symbol     string          `json:"symbol"`
+	Side       string          `json:"side,omitempty"`
+	Action     string          `json:"action,omitempty"`
+	Quantity   decimal.Decimal `json:"quantity"`
+	Price      decimal.Decimal `json:"price"`
+	Commission decimal.Decimal `json:"commission"`
+	Fees       decimal.Decimal `json:"fees"`
+	Multiplier decimal.Decimal `json:"multiplier"`
+	Currency   string          `json:"currency,omitempty"`
+	SpreadID   *int            `json:"spread_id,omitempty"`
+	AccountID  *int            `json:"account_id,omitempty"`
+	ExecutedAt time.Time       `json:"executed_at"`
+	Strategy   string          `json:"strategy,omitempty"`
+	Tags       []string        `json:"tags,omitempty"`
+	models.Plan
+}
+
+func (req *ExecutionRequest) validate() error {
+	if strings.TrimSpace(req.Symbol) == "" {
+		return errors.New("symbol is required")
+	}
+	action := strings.ToLower(req.Action)
+	if action != "" && !models.ValidAction(action) {
+		return errors.New("action must be buy, sell, sell_short or buy_to_cover")
+	}
+	side := strings.ToLower(req.Side)
+	if side == "" && action != "" {
+		side = models.ActionSide(action)
+	}
+	if side != models.SideBuy && side != models.SideSell {
+		return errors.New("side must be buy or sell")
+	}
+	if action != "" && models.ActionSide(action) != side {
+		return errors.New("side does not match action")
+	}
+	if !req.Quantity.IsPositive() {
+		return errors.New("quantity must be positive")
+	}
+	if req.Price.IsNegative() {
+		return errors.New("price must not be negative")
+	}
+	if req.Commission.IsNegative() {
+		return errors.New("commission must not be negative")
+	}
+	if req.Fees.IsNegative() {
+		return errors.New("fees must not be negative")
+	}
+	if req.Multiplier.IsNegative() {
+		return errors.New("multiplier must not be negative")
+	}
+	if req.Currency != "" && !instruments.ValidCurrency(strings.ToUpper(req.Currency)) {
+		return errors.New("currency must be a three-letter currency code")
+	}
+	// Without an action the fill is taken to open a position on its side.
+	direction := models.DirectionLong
+	if action != "" {
+		direction = models.ActionDirection(action)
+	} else if side == models.SideSell {
+		direction = models.DirectionShort
+	}
+	return req.Plan.Validate(direction)
+}
+
+// apply copies the request onto execution. Without an explicit currency the
+// fill is priced in the instrument's currency, or else the account's.
+func (req *ExecutionRequest) apply(execution *models.Execution, account *models.Account) {
+	execution.Symbol = instruments.Normalize(req.Symbol)
+	execution.Action = strings.ToLower(req.Action)
+	execution.Side = strings.ToLower(req.Side)
+	if execution.Side == "" {
+		execution.Side = models.ActionSide(execution.Action)
+	}
+	execution.Quantity = req.Quantity
+	execution.Price = req.Price
+	execution.Commission = req.Commission
+	execution.Fees = req.Fees
+	execution.Multiplier = req.Multiplier
+	if !execution.Multiplier.IsPositive() {
+		execution.Multiplier = instruments.Lookup(execution.Symbol).Multiplier
+	}
+	execution.Currency = strings.ToUpper(req.Currency)
+	if execution.Currency == "" {
+		fallback := ""
+		if account != nil {
+			fallback = account.Currency
+		}
+		execution.Currency = instruments.CurrencyFor(execution.Symbol, fallback)
+	}
+	execution.SpreadID = req.SpreadID
+	execution.AccountID = req.AccountID
+	execution.ExecutedAt = req.ExecutedAt
+	if execution.ExecutedAt.IsZero() {
+		execution.ExecutedAt = time.Now().UTC()
+	}
+	execution.Strategy = req.Strategy
+	execution.Tags = models.NormalizeTags(req.Tags)
+	execution.Plan = req.Plan
+}
+
+func (h *ExecutionHandler) CreateExecution(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	var req ExecutionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	if err := req.validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !h.validSpread(w, userID, req.SpreadID) {
+		return
+	}
+	account, ok := h.loadAccount(w, userID, req.AccountID)
+	if !ok {
+		return
+	}
+
+	execution := &models.Execution{UserID: userID}
+	req.apply(execution, account)
+	if !h.closes(w, userID, *execution) {
+		return
+	}
+
+	if err := h.Store.CreateExecution(execution); err != nil {
+		http.Error(w, "Error creating execution", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(execution)
+}
+
+func (h *ExecutionHandler) ListExecutions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	accountFilter, err := accounts.ParseFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	executions, err := h.Store.ListExecutions(userID)
+	if err != nil {
+		http.Error(w, "Error listing executions", http.StatusInternalServerError)
+		return
+	}
+
+	symbol := r.URL.Query().Get("symbol")
+	if symbol != "" {
+		symbol = instruments.Normalize(symbol)
+	}
+	filtered := []models.Execution{}
+	for _, execution := range executions {
+		if symbol != "" && execution.Symbol != symbol {
+			continue
+		}
+		if !accountFilter.Match(execution.AccountID) {
+			continue
+		}
+		filtered = append(filtered, execution)
+	}
+	executions = filtered
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(executions)
+}
+
+func (h *ExecutionHandler) GetExecution(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	executionID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid execution ID", http.StatusBadRequest)
+		return
+	}
+
+	execution, err := h.Store.GetExecution(userID, executionID)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "Execution not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error fetching execution", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(execution)
+}
+
+func (h *ExecutionHandler) UpdateExecution(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	executionID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid execution ID", http.StatusBadRequest)
+		return
+	}
+
+	var req ExecutionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	if err := req.validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !h.validSpread(w, userID, req.SpreadID) {
+		return
+	}
+	account, ok := h.loadAccount(w, userID, req.AccountID)
+	if !ok {
+		return
+	}
+
+	execution := &models.Execution{ID: executionID, UserID: userID}
+	req.apply(execution, account)
+	if !h.closes(w, userID, *execution) {
+		return
+	}
+
+	err = h.Store.UpdateExecution(execution)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "Execution not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error updating execution", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(execution)
+}
+
+func (h *ExecutionHandler) DeleteExecution(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	executionID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid execution ID", http.StatusBadRequest)
+		return
+	}
+
+	err = h.Store.DeleteExecution(userID, executionID)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "Execution not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error deleting execution", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// validSpread checks that a referenced spread belongs to the user, writing
+// the error response when it does not.
+func (h *ExecutionHandler) validSpread(w http.ResponseWriter, userID int, spreadID *int) bool {
+	if spreadID == nil {
+		return true
+	}
+	_, err := h.Store.GetSpread(userID, *spreadID)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "Spread not found", http.StatusBadRequest)
+		return false
+	}
+	if err != nil {
+		http.Error(w, "Error fetching spread", http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
+
+// closes checks that a sell or buy to cover has a position to close,
+// writing the error response when it does not.
+func (h *ExecutionHandler) closes(w http.ResponseWriter, userID int, execution models.Execution) bool {
+	err := positions.CheckClose(h.Store, userID, execution)
+	if errors.Is(err, positions.ErrOverclose) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	if err != nil {
+		http.Error(w, "Error checking open positions", http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
+
+// loadAccount fetches a referenced account if it belongs to the user,
+// writing the error response when it does not.
+func (h *ExecutionHandler) loadAccount(w http.ResponseWriter, userID int, accountID *int) (*models.Account, bool) {
+	if accountID == nil {
+		return nil, true
+	}
+	account, err := h.Store.GetAccount(userID, *accountID)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "Account not found", http.StatusBadRequest)
+		return nil, false
+	}
+	if err != nil {
+		http.Error(w, "Error fetching account", http.StatusInternalServerError)
+		return nil, false
+	}
+	return account, true
+}
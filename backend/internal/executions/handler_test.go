@@ -0,0 +1,175 @@
+package executions
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/drewbuiltit/trading-journal/backend/internal/auth"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"github.com/gorilla/mux"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func dec(value string) decimal.Decimal {
+	return decimal.RequireFromString(value)
+}
+
+func setupTestExecutionHandler() *ExecutionHandler {
+	return &ExecutionHandler{
+		Store: store.NewMemoryStore(),
+	}
+}
+
+func newExecutionRequest(t *testing.T, method, url string, body []byte, userID int, vars map[string]string) *http.Request {
+	req, err := http.NewRequest(method, url, bytes.NewBuffer(body))
+	assert.NoError(t, err)
+	req = req.WithContext(context.WithValue(req.Context(), auth.UserContextKey, userID))
+	if vars != nil {
+		req = mux.SetURLVars(req, vars)
+	}
+	return req
+}
+
+func TestCreateExecutionHandler(t *testing.T) {
+	executionHandler := setupTestExecutionHandler()
+
+	t.Run("Successful Creation", func(t *testing.T) {
+		body, _ := json.Marshal(ExecutionRequest{
+			Symbol:     "aapl",
+			Side:       "BUY",
+			Quantity:   dec("100"),
+			Price:      dec("187.25"),
+			Fees:       dec("1"),
+			ExecutedAt: time.Date(2024, 5, 1, 14, 30, 0, 0, time.UTC),
+		})
+
+		rr := httptest.NewRecorder()
+		executionHandler.CreateExecution(rr, newExecutionRequest(t, "POST", "/executions", body, 1, nil))
+		assert.Equal(t, http.StatusCreated, rr.Code)
+
+		var execution models.Execution
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &execution))
+		assert.Equal(t, "AAPL", execution.Symbol)
+		assert.Equal(t, models.SideBuy, execution.Side)
+		assert.Equal(t, 1, execution.UserID)
+	})
+
+	t.Run("Creation with Invalid Side", func(t *testing.T) {
+		body, _ := json.Marshal(ExecutionRequest{Symbol: "AAPL", Side: "hold", Quantity: dec("1"), Price: dec("1")})
+
+		rr := httptest.NewRecorder()
+		executionHandler.CreateExecution(rr, newExecutionRequest(t, "POST", "/executions", body, 1, nil))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, "side must be buy or sell\n", rr.Body.String())
+	})
+
+	t.Run("Side Derived from Action", func(t *testing.T) {
+		body, _ := json.Marshal(ExecutionRequest{Symbol: "TSLA", Action: "sell_short", Quantity: dec("10"), Price: dec("250")})
+
+		rr := httptest.NewRecorder()
+		executionHandler.CreateExecution(rr, newExecutionRequest(t, "POST", "/executions", body, 1, nil))
+		assert.Equal(t, http.StatusCreated, rr.Code)
+
+		var execution models.Execution
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &execution))
+		assert.Equal(t, models.SideSell, execution.Side)
+		assert.Equal(t, models.ActionSellShort, execution.Action)
+	})
+
+	t.Run("Closing Action Beyond the Position", func(t *testing.T) {
+		for _, tt := range []struct {
+			symbol, action, quantity string
+			code                     int
+			message                  string
+		}{
+			{"TSLA", "buy_to_cover", "15", http.StatusBadRequest, "closing fill is more than the position held: 10 TSLA held short\n"},
+			{"MSFT", "sell", "5", http.StatusBadRequest, "closing fill is more than the position held: 0 MSFT held long\n"},
+			{"TSLA", "buy_to_cover", "10", http.StatusCreated, ""},
+		} {
+			body, _ := json.Marshal(ExecutionRequest{Symbol: tt.symbol, Action: tt.action, Quantity: dec(tt.quantity), Price: dec("240")})
+
+			rr := httptest.NewRecorder()
+			executionHandler.CreateExecution(rr, newExecutionRequest(t, "POST", "/executions", body, 1, nil))
+			assert.Equal(t, tt.code, rr.Code)
+			if tt.message != "" {
+				assert.Equal(t, tt.message, rr.Body.String())
+			}
+		}
+	})
+
+	t.Run("Creation with Conflicting Side", func(t *testing.T) {
+		body, _ := json.Marshal(ExecutionRequest{Symbol: "TSLA", Side: "sell", Action: "buy_to_cover", Quantity: dec("10"), Price: dec("250")})
+
+		rr := httptest.NewRecorder()
+		executionHandler.CreateExecution(rr, newExecutionRequest(t, "POST", "/executions", body, 1, nil))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, "side does not match action\n", rr.Body.String())
+	})
+
+	t.Run("Creation with Target on the Wrong Side", func(t *testing.T) {
+		body := []byte(`{"symbol": "TSLA", "side": "sell", "quantity": "10", "price": "250", "stop_loss": "260", "profit_target": "270"}`)
+
+		rr := httptest.NewRecorder()
+		executionHandler.CreateExecution(rr, newExecutionRequest(t, "POST", "/executions", body, 1, nil))
+		assert.Equal(t, http.StatusBadRequest, rr.Code, "a sell opens a short, whose target is below its stop")
+		assert.Equal(t, "stop_loss and profit_target are on the wrong sides\n", rr.Body.String())
+	})
+
+	t.Run("Option Symbol and Multiplier", func(t *testing.T) {
+		body, _ := json.Marshal(ExecutionRequest{Symbol: "aapl240119c00190000", Side: "buy", Quantity: dec("2"), Price: dec("3.10")})
+
+		rr := httptest.NewRecorder()
+		executionHandler.CreateExecution(rr, newExecutionRequest(t, "POST", "/executions", body, 1, nil))
+		assert.Equal(t, http.StatusCreated, rr.Code)
+
+		var execution models.Execution
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &execution))
+		assert.Equal(t, "AAPL  240119C00190000", execution.Symbol)
+		assert.Equal(t, "100", execution.Multiplier.String())
+	})
+
+	t.Run("Creation with Unknown Spread", func(t *testing.T) {
+		spreadID := 99
+		body, _ := json.Marshal(ExecutionRequest{Symbol: "SPY   240621P00500000", Side: "sell", Quantity: dec("1"), Price: dec("4"), SpreadID: &spreadID})
+
+		rr := httptest.NewRecorder()
+		executionHandler.CreateExecution(rr, newExecutionRequest(t, "POST", "/executions", body, 1, nil))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, "Spread not found\n", rr.Body.String())
+	})
+
+	t.Run("Creation with Non-Positive Quantity", func(t *testing.T) {
+		body, _ := json.Marshal(ExecutionRequest{Symbol: "AAPL", Side: "sell", Quantity: dec("-5"), Price: dec("1")})
+
+		rr := httptest.NewRecorder()
+		executionHandler.CreateExecution(rr, newExecutionRequest(t, "POST", "/executions", body, 1, nil))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, "quantity must be positive\n", rr.Body.String())
+	})
+}
+
+func TestExecutionHandlerScopesToUser(t *testing.T) {
+	executionHandler := setupTestExecutionHandler()
+
+	execution := &models.Execution{UserID: 1, Symbol: "AAPL", Side: models.SideBuy, Quantity: dec("1"), Price: dec("1"), ExecutedAt: time.Now()}
+	assert.NoError(t, executionHandler.Store.CreateExecution(execution))
+	vars := map[string]string{"id": "1"}
+
+	rr := httptest.NewRecorder()
+	executionHandler.GetExecution(rr, newExecutionRequest(t, "GET", "/executions/1", nil, 2, vars))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	rr = httptest.NewRecorder()
+	executionHandler.DeleteExecution(rr, newExecutionRequest(t, "DELETE", "/executions/1", nil, 2, vars))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	rr = httptest.NewRecorder()
+	executionHandler.DeleteExecution(rr, newExecutionRequest(t, "DELETE", "/executions/1", nil, 1, vars))
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+}
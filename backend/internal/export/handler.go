@@ -0,0 +1,222 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/drewbuiltit/trading-journal/backend/internal/accounts"
+	"github.com/drewbuiltit/trading-journal/backend/internal/auth"
+	"github.com/drewbuiltit/trading-journal/backend/internal/instruments"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/drewbuiltit/trading-journal/backend/internal/positions"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+)
+
+type ExportHandler struct {
+	Store store.Store
+}
+
+// ExportFills streams the user's fills, executions and trades alike, oldest
+// first as ?format=csv (the default) or jsonl. ?symbol= and ?account= filter
+// them as they do the executions listing.
+func (h *ExportHandler) ExportFills(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	format, ok := parseFormat(w, r)
+	if !ok {
+		return
+	}
+	accountFilter, err := accounts.ParseFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	fills, err := h.loadFills(userID, r.URL.Query(), accountFilter)
+	if err != nil {
+		http.Error(w, "Error listing fills", http.StatusInternalServerError)
+		return
+	}
+
+	stream(w, "fills", format, fillColumns, fills, fillRow)
+}
+
+// ExportPositions streams the user's positions as ?format=csv (the default)
+// or jsonl, filtered as the positions listing is. JSON Lines carry every
+// field of the listing, lot matches included.
+func (h *ExportHandler) ExportPositions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	format, ok := parseFormat(w, r)
+	if !ok {
+		return
+	}
+	filter, err := positions.ParseFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	built, err := h.loadPositions(userID, filter)
+	if err != nil {
+		http.Error(w, "Error building positions", http.StatusInternalServerError)
+		return
+	}
+
+	stream(w, "positions", format, positionColumns, built, positionRow)
+}
+
+// ExportWorkbook returns an XLSX workbook with a sheet each for fills,
+// positions and summary statistics. The listing filters apply, with all but
+// ?symbol= and ?account= narrowing only the positions.
+func (h *ExportHandler) ExportWorkbook(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	filter, err := positions.ParseFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	fills, err := h.loadFills(userID, r.URL.Query(), filter.Accounts)
+	if err != nil {
+		http.Error(w, "Error listing fills", http.StatusInternalServerError)
+		return
+	}
+	built, err := h.loadPositions(userID, filter)
+	if err != nil {
+		http.Error(w, "Error building positions", http.StatusInternalServerError)
+		return
+	}
+
+	fillSheet := sheet{name: "Fills", rows: []row{header(fillColumns)}}
+	for _, fill := range fills {
+		fillSheet.rows = append(fillSheet.rows, fillRow(fill))
+	}
+	positionSheet := sheet{name: "Positions", rows: []row{header(positionColumns)}}
+	for _, position := range built {
+		positionSheet.rows = append(positionSheet.rows, positionRow(position))
+	}
+	summarySheet := sheet{name: "Summary", rows: summarize(fills, built)}
+
+	w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	w.Header().Set("Content-Disposition", `attachment; filename="journal.xlsx"`)
+	writeWorkbook(w, []sheet{fillSheet, positionSheet, summarySheet})
+}
+
+// loadFills returns the user's executions and trades as fills, oldest first,
+// matching ?symbol= and the account filter.
+func (h *ExportHandler) loadFills(userID int, query url.Values, accountFilter accounts.Filter) ([]models.Execution, error) {
+	all, err := positions.Fills(h.Store, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	symbol := query.Get("symbol")
+	if symbol != "" {
+		symbol = instruments.Normalize(symbol)
+	}
+	fills := []models.Execution{}
+	for _, fill := range all {
+		if symbol != "" && fill.Symbol != symbol {
+			continue
+		}
+		if !accountFilter.Match(fill.AccountID) {
+			continue
+		}
+		fills = append(fills, fill)
+	}
+
+	sort.SliceStable(fills, func(i, j int) bool {
+		return fills[i].ExecutedAt.Before(fills[j].ExecutedAt)
+	})
+	return fills, nil
+}
+
+func (h *ExportHandler) loadPositions(userID int, filter positions.Filter) ([]positions.Position, error) {
+	built, _, err := positions.ForUser(h.Store, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	matched := []positions.Position{}
+	for _, position := range built {
+		if filter.Match(position) {
+			matched = append(matched, position)
+		}
+	}
+	return matched, nil
+}
+
+func parseFormat(w http.ResponseWriter, r *http.Request) (string, bool) {
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	switch format {
+	case "":
+		return FormatCSV, true
+	case FormatCSV, FormatJSONL:
+		return format, true
+	}
+	http.Error(w, "format must be csv or jsonl", http.StatusBadRequest)
+	return "", false
+}
+
+// stream writes records as JSON Lines, or as CSV rows under a header of
+// columns, a line at a time so that a long history is not buffered whole.
+func stream[T any](w http.ResponseWriter, name, format string, columns []string, records []T, toRow func(T) row) {
+	if format == FormatJSONL {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.jsonl"`, name))
+		encoder := json.NewEncoder(w)
+		for _, record := range records {
+			if err := encoder.Encode(record); err != nil {
+				return
+			}
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, name))
+	writer := csv.NewWriter(w)
+	writer.Write(columns)
+	for _, record := range records {
+		values := toRow(record)
+		line := make([]string, len(values))
+		for i, value := range values {
+			line[i] = text(value)
+		}
+		if err := writer.Write(line); err != nil {
+			return
+		}
+	}
+	writer.Flush()
+}
+
+func header(columns []string) row {
+	values := make(row, len(columns))
+	for i, column := range columns {
+		values[i] = column
+	}
+	return values
+}
@@ -0,0 +1,153 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"github.com/drewbuiltit/trading-journal/backend/internal/auth"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/drewbuiltit/trading-journal/backend/internal/positions"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+var start = time.Date(2024, 5, 1, 14, 30, 0, 0, time.UTC)
+
+func newExportHandler(t *testing.T) *ExportHandler {
+	exportHandler := &ExportHandler{Store: store.NewMemoryStore()}
+	assert.NoError(t, exportHandler.Store.CreateUser(&models.User{Username: "jane_doe", Email: "jane@example.com"}))
+	account := &models.Account{UserID: 1, Name: "Broker"}
+	assert.NoError(t, exportHandler.Store.CreateAccount(account))
+
+	for _, execution := range []models.Execution{
+		{UserID: 1, AccountID: &account.ID, Symbol: "AAPL", Side: models.SideBuy, Quantity: decimal.NewFromInt(10), Price: decimal.NewFromInt(100), Commission: decimal.NewFromInt(1), ExecutedAt: start},
+		{UserID: 1, AccountID: &account.ID, Symbol: "AAPL", Side: models.SideSell, Quantity: decimal.NewFromInt(10), Price: decimal.NewFromInt(110), Commission: decimal.NewFromInt(1), ExecutedAt: start.Add(time.Hour)},
+		{UserID: 1, Symbol: "MSFT", Side: models.SideBuy, Quantity: decimal.NewFromInt(5), Price: decimal.NewFromInt(400), ExecutedAt: start.Add(2 * time.Hour)},
+		{UserID: 2, Symbol: "NVDA", Side: models.SideBuy, Quantity: decimal.NewFromInt(1), Price: decimal.NewFromInt(900), ExecutedAt: start},
+	} {
+		execution := execution
+		assert.NoError(t, exportHandler.Store.CreateExecution(&execution))
+	}
+	assert.NoError(t, exportHandler.Store.CreateTrade(&models.Trade{
+		UserID: 1, Symbol: "AMD", Direction: models.DirectionShort, Action: models.ActionSellShort,
+		Quantity: decimal.NewFromInt(20), Price: decimal.NewFromInt(150), TradeDate: start.Add(30 * time.Minute),
+	}))
+	return exportHandler
+}
+
+func get(t *testing.T, handler func(http.ResponseWriter, *http.Request), url string) *httptest.ResponseRecorder {
+	req, err := http.NewRequest("GET", url, nil)
+	assert.NoError(t, err)
+	req = req.WithContext(context.WithValue(req.Context(), auth.UserContextKey, 1))
+
+	rr := httptest.NewRecorder()
+	handler(rr, req)
+	return rr
+}
+
+func TestExportFills(t *testing.T) {
+	exportHandler := newExportHandler(t)
+
+	t.Run("CSV", func(t *testing.T) {
+		rr := get(t, exportHandler.ExportFills, "/export/fills")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "text/csv", rr.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="fills.csv"`, rr.Header().Get("Content-Disposition"))
+
+		rows, err := csv.NewReader(rr.Body).ReadAll()
+		assert.NoError(t, err)
+		assert.Len(t, rows, 5)
+		assert.Equal(t, fillColumns, rows[0])
+		assert.Equal(t, []string{"1", "", "1", "AAPL", "buy", "", "10", "100", "1", "0", "0", "", "2024-05-01T14:30:00Z"}, rows[1])
+		assert.Equal(t, "AMD", rows[2][3], "trades are exported as fills in time order")
+		assert.Equal(t, "", rows[2][0])
+		assert.Equal(t, "1", rows[2][1])
+		assert.Equal(t, "sell_short", rows[2][5])
+	})
+
+	t.Run("JSON Lines", func(t *testing.T) {
+		rr := get(t, exportHandler.ExportFills, "/export/fills?format=jsonl&symbol=aapl")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "application/x-ndjson", rr.Header().Get("Content-Type"))
+
+		lines := strings.Split(strings.TrimSpace(rr.Body.String()), "\n")
+		assert.Len(t, lines, 2)
+		var fill models.Execution
+		assert.NoError(t, json.Unmarshal([]byte(lines[1]), &fill))
+		assert.Equal(t, models.SideSell, fill.Side)
+	})
+
+	t.Run("Filter by Account", func(t *testing.T) {
+		rows, err := csv.NewReader(get(t, exportHandler.ExportFills, "/export/fills?account=none").Body).ReadAll()
+		assert.NoError(t, err)
+		assert.Len(t, rows, 3)
+	})
+
+	t.Run("Bad Requests", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, get(t, exportHandler.ExportFills, "/export/fills?format=xml").Code)
+		assert.Equal(t, http.StatusBadRequest, get(t, exportHandler.ExportFills, "/export/fills?account=x").Code)
+	})
+}
+
+func TestExportPositions(t *testing.T) {
+	exportHandler := newExportHandler(t)
+
+	rr := get(t, exportHandler.ExportPositions, "/export/positions?status=closed")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	rows, err := csv.NewReader(rr.Body).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, rows, 2)
+	assert.Equal(t, positionColumns, rows[0])
+	assert.Equal(t, "AAPL", rows[1][1])
+	assert.Equal(t, "2024-05-01T15:30:00Z", rows[1][13])
+	assert.Equal(t, "98", rows[1][19], "net P&L")
+
+	rr = get(t, exportHandler.ExportPositions, "/export/positions?format=jsonl&direction=short")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var position positions.Position
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &position))
+	assert.Equal(t, "AMD", position.Symbol)
+
+	assert.Equal(t, http.StatusBadRequest, get(t, exportHandler.ExportPositions, "/export/positions?status=pending").Code)
+}
+
+func TestExportWorkbook(t *testing.T) {
+	exportHandler := newExportHandler(t)
+
+	rr := get(t, exportHandler.ExportWorkbook, "/export/workbook")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", rr.Header().Get("Content-Type"))
+
+	parts := readWorkbook(t, rr.Body.Bytes())
+	assert.Contains(t, parts["xl/workbook.xml"], `<sheet name="Fills" sheetId="1" r:id="rId1"/><sheet name="Positions" sheetId="2" r:id="rId2"/><sheet name="Summary" sheetId="3" r:id="rId3"/>`)
+	assert.Contains(t, parts["xl/worksheets/sheet1.xml"], `<c r="D2" t="inlineStr"><is><t xml:space="preserve">AAPL</t></is></c>`)
+	assert.Contains(t, parts["xl/worksheets/sheet1.xml"], `<c r="H2"><v>100</v></c>`, "prices are numbers")
+	assert.Contains(t, parts["xl/worksheets/sheet2.xml"], `<row r="4">`)
+	assert.Contains(t, parts["xl/worksheets/sheet3.xml"], `<c r="A8" t="inlineStr"><is><t xml:space="preserve">win_rate</t></is></c><c r="B8"><v>1</v></c>`)
+
+	assert.Equal(t, http.StatusBadRequest, get(t, exportHandler.ExportWorkbook, "/export/workbook?direction=up").Code)
+}
+
+func readWorkbook(t *testing.T, data []byte) map[string]string {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	assert.NoError(t, err)
+
+	parts := make(map[string]string)
+	for _, file := range archive.File {
+		f, err := file.Open()
+		assert.NoError(t, err)
+		body, err := io.ReadAll(f)
+		assert.NoError(t, err)
+		parts[file.Name] = string(body)
+	}
+	return parts
+}
@@ -0,0 +1,84 @@
+package export
+
+import (
+	"fmt"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/drewbuiltit/trading-journal/backend/internal/positions"
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+var fillColumns = []string{
+	"execution_id", "trade_id", "account_id", "symbol", "side", "action",
+	"quantity", "price", "commission", "fees", "multiplier", "currency",
+	"executed_at",
+}
+
+var positionColumns = []string{
+	"account_id", "symbol", "underlying", "kind", "direction", "status",
+	"quantity", "open_quantity", "multiplier", "currency", "entry_average",
+	"exit_average", "opened_at", "closed_at", "holding_seconds", "commission",
+	"fees", "borrow_cost", "gross_pnl", "net_pnl", "base_currency",
+	"base_net_pnl",
+}
+
+// A row holds cell values as strings, numbers or times so that the workbook
+// can store numbers as numbers; blank cells are nil.
+type row []any
+
+func fillRow(fill models.Execution) row {
+	return row{
+		optionalID(fill.ID), optionalID(fill.TradeID), accountID(fill.AccountID),
+		fill.Symbol, fill.Side, fill.Action,
+		fill.Quantity, fill.Price, fill.Commission, fill.Fees, fill.Multiplier, fill.Currency,
+		fill.ExecutedAt,
+	}
+}
+
+func positionRow(position positions.Position) row {
+	var closedAt any
+	if position.ClosedAt != nil {
+		closedAt = *position.ClosedAt
+	}
+	return row{
+		accountID(position.AccountID), position.Symbol, position.Underlying, position.Kind,
+		position.Direction, position.Status,
+		position.Quantity, position.OpenQuantity, position.Multiplier, position.Currency,
+		position.EntryAverage, position.ExitAverage,
+		position.OpenedAt, closedAt, position.HoldingSeconds,
+		position.Commission, position.Fees, position.BorrowCost,
+		position.GrossPnL, position.NetPnL, position.BaseCurrency, position.BaseNetPnL,
+	}
+}
+
+// optionalID leaves out the zero ID of a fill that is a trade, or of a trade
+// that is an execution.
+func optionalID(id int) any {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+func accountID(id *int) any {
+	if id == nil {
+		return nil
+	}
+	return *id
+}
+
+// text formats a cell for CSV.
+func text(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case decimal.Decimal:
+		return v.String()
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	default:
+		return fmt.Sprint(v)
+	}
+}
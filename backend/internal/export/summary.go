@@ -0,0 +1,53 @@
+package export
+
+import (
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/drewbuiltit/trading-journal/backend/internal/positions"
+	"github.com/drewbuiltit/trading-journal/backend/internal/stats"
+	"github.com/shopspring/decimal"
+)
+
+// summarize returns the workbook's summary sheet: counts, win rate and P&L
+// in the base currency across the exported positions. The win rate is the
+// one the stats endpoint reports, and positions without a rate into the base
+// currency are counted apart rather than in the P&L.
+func summarize(fills []models.Execution, built []positions.Position) []row {
+	summary := stats.Compute(built)
+	var open, unconverted int
+	gross, net := decimal.Zero, decimal.Zero
+	base := summary.BaseCurrency
+	for _, position := range built {
+		if base == "" {
+			base = position.BaseCurrency
+		}
+		if position.Status == positions.StatusOpen {
+			open++
+		}
+		if position.FXRateMissing {
+			unconverted++
+			continue
+		}
+		gross = gross.Add(position.BaseGrossPnL)
+		net = net.Add(position.BaseNetPnL)
+	}
+
+	var winRate any
+	if summary.WinRate != nil {
+		winRate = *summary.WinRate
+	}
+	return []row{
+		{"metric", "value"},
+		{"fills", len(fills)},
+		{"positions", len(built)},
+		{"open_positions", open},
+		{"closed_positions", len(built) - open},
+		{"winners", summary.Wins},
+		{"losers", summary.Losses},
+		{"win_rate", winRate},
+		{"base_currency", base},
+		{"gross_pnl", gross},
+		{"costs", gross.Sub(net)},
+		{"net_pnl", net},
+		{"unconverted_positions", unconverted},
+	}
+}
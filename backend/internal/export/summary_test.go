@@ -0,0 +1,34 @@
+package export
+
+import (
+	"github.com/drewbuiltit/trading-journal/backend/internal/positions"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestSummarize_Unconverted(t *testing.T) {
+	closedAt := time.Date(2024, 5, 1, 15, 30, 0, 0, time.UTC)
+	closed := func(pnl int64, missing bool) positions.Position {
+		return positions.Position{
+			Status:        positions.StatusClosed,
+			ClosedAt:      &closedAt,
+			BaseCurrency:  "USD",
+			BaseGrossPnL:  decimal.NewFromInt(pnl),
+			BaseNetPnL:    decimal.NewFromInt(pnl),
+			FXRateMissing: missing,
+		}
+	}
+
+	rows := summarize(nil, []positions.Position{closed(100, false), closed(0, true)})
+	values := make(map[string]any)
+	for _, r := range rows[1:] {
+		values[r[0].(string)] = r[1]
+	}
+	assert.Equal(t, 2, values["closed_positions"])
+	assert.Equal(t, 1, values["winners"])
+	assert.Equal(t, "1", values["win_rate"].(decimal.Decimal).String(), "the unconverted position is not a loss")
+	assert.Equal(t, "100", values["net_pnl"].(decimal.Decimal).String())
+	assert.Equal(t, 1, values["unconverted_positions"])
+}
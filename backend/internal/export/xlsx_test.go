@@ -0,0 +1,25 @@
+package export
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestColumn(t *testing.T) {
+	assert.Equal(t, "A", column(0))
+	assert.Equal(t, "Z", column(25))
+	assert.Equal(t, "AA", column(26))
+	assert.Equal(t, "AZ", column(51))
+	assert.Equal(t, "BA", column(52))
+}
+
+func TestWriteWorkbook(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, writeWorkbook(&buf, []sheet{{name: "P&L", rows: []row{{"a<b", nil, 3}}}}))
+
+	parts := readWorkbook(t, buf.Bytes())
+	assert.Contains(t, parts["[Content_Types].xml"], `/xl/worksheets/sheet1.xml`)
+	assert.Contains(t, parts["xl/workbook.xml"], `<sheet name="P&amp;L"`)
+	assert.Contains(t, parts["xl/worksheets/sheet1.xml"], `<c r="A1" t="inlineStr"><is><t xml:space="preserve">a&lt;b</t></is></c><c r="C1"><v>3</v></c>`)
+}
@@ -0,0 +1,121 @@
+package fees
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/drewbuiltit/trading-journal/backend/internal/auth"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"github.com/shopspring/decimal"
+	"net/http"
+	"strconv"
+)
+
+type FeeHandler struct {
+	Store store.Store
+}
+
+type ScheduleRequest struct {
+	CommissionPerShare decimal.Decimal `json:"commission_per_share"`
+	CommissionPerOrder decimal.Decimal `json:"commission_per_order"`
+	CommissionMinimum  decimal.Decimal `json:"commission_minimum"`
+	RegulatoryFeeRate  decimal.Decimal `json:"regulatory_fee_rate"`
+	BorrowRate         decimal.Decimal `json:"borrow_rate"`
+	MarginRate         decimal.Decimal `json:"margin_rate"`
+}
+
+// GetSchedule returns the schedule of the account given by ?account=, or the
+// user's default schedule without one.
+func (h *FeeHandler) GetSchedule(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+	accountID, ok := h.accountOf(w, r, userID)
+	if !ok {
+		return
+	}
+
+	schedule, err := h.Store.GetFeeSchedule(userID, accountID)
+	if errors.Is(err, store.ErrNotFound) {
+		schedule = &models.FeeSchedule{UserID: userID, AccountID: accountID}
+	} else if err != nil {
+		http.Error(w, "Error fetching fee schedule", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(schedule)
+}
+
+// SaveSchedule replaces the schedule of the account given by ?account=, or
+// the user's default schedule without one. A margin_rate is charged on
+// the whole of every long position held overnight, so it is only meant for
+// accounts that trade on margin.
+func (h *FeeHandler) SaveSchedule(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+	accountID, ok := h.accountOf(w, r, userID)
+	if !ok {
+		return
+	}
+
+	var req ScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	if req.CommissionPerShare.IsNegative() || req.CommissionPerOrder.IsNegative() || req.CommissionMinimum.IsNegative() ||
+		req.RegulatoryFeeRate.IsNegative() || req.BorrowRate.IsNegative() || req.MarginRate.IsNegative() {
+		http.Error(w, "Fee schedule values must not be negative", http.StatusBadRequest)
+		return
+	}
+
+	schedule := &models.FeeSchedule{
+		UserID:             userID,
+		AccountID:          accountID,
+		CommissionPerShare: req.CommissionPerShare,
+		CommissionPerOrder: req.CommissionPerOrder,
+		CommissionMinimum:  req.CommissionMinimum,
+		RegulatoryFeeRate:  req.RegulatoryFeeRate,
+		BorrowRate:         req.BorrowRate,
+		MarginRate:         req.MarginRate,
+	}
+
+	if err := h.Store.SaveFeeSchedule(schedule); err != nil {
+		http.Error(w, "Error saving fee schedule", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(schedule)
+}
+
+// accountOf reads the optional ?account=, writing the error response when it
+// is not one of the user's accounts.
+func (h *FeeHandler) accountOf(w http.ResponseWriter, r *http.Request, userID int) (*int, bool) {
+	value := r.URL.Query().Get("account")
+	if value == "" {
+		return nil, true
+	}
+	accountID, err := strconv.Atoi(value)
+	if err != nil {
+		http.Error(w, "Invalid account ID", http.StatusBadRequest)
+		return nil, false
+	}
+
+	_, err = h.Store.GetAccount(userID, accountID)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "Account not found", http.StatusBadRequest)
+		return nil, false
+	}
+	if err != nil {
+		http.Error(w, "Error fetching account", http.StatusInternalServerError)
+		return nil, false
+	}
+	return &accountID, true
+}
@@ -0,0 +1,86 @@
+package fees
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/drewbuiltit/trading-journal/backend/internal/auth"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newFeeRequest(t *testing.T, method, query string, body []byte) *http.Request {
+	req, err := http.NewRequest(method, "/fee-schedule"+query, bytes.NewBuffer(body))
+	assert.NoError(t, err)
+	return req.WithContext(context.WithValue(req.Context(), auth.UserContextKey, 1))
+}
+
+func TestFeeScheduleHandlers(t *testing.T) {
+	feeHandler := &FeeHandler{Store: store.NewMemoryStore()}
+
+	t.Run("Empty Schedule by Default", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		feeHandler.GetSchedule(rr, newFeeRequest(t, "GET", "", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var schedule models.FeeSchedule
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &schedule))
+		assert.True(t, schedule.CommissionPerShare.IsZero())
+	})
+
+	t.Run("Successful Save", func(t *testing.T) {
+		body, _ := json.Marshal(ScheduleRequest{CommissionPerShare: dec("0.005"), CommissionMinimum: dec("1"), BorrowRate: dec("0.03")})
+
+		rr := httptest.NewRecorder()
+		feeHandler.SaveSchedule(rr, newFeeRequest(t, "PUT", "", body))
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		schedule, err := feeHandler.Store.GetFeeSchedule(1, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "0.005", schedule.CommissionPerShare.String())
+		assert.Equal(t, "0.03", schedule.BorrowRate.String())
+	})
+
+	t.Run("Per Account", func(t *testing.T) {
+		account := &models.Account{UserID: 1, Name: "Margin"}
+		assert.NoError(t, feeHandler.Store.CreateAccount(account))
+		body, _ := json.Marshal(ScheduleRequest{CommissionPerOrder: dec("0.65"), MarginRate: dec("0.08")})
+
+		rr := httptest.NewRecorder()
+		feeHandler.SaveSchedule(rr, newFeeRequest(t, "PUT", fmt.Sprintf("?account=%d", account.ID), body))
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		schedule, err := feeHandler.Store.GetFeeSchedule(1, &account.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "0.65", schedule.CommissionPerOrder.String())
+		assert.Equal(t, "0.08", schedule.MarginRate.String())
+		schedule, err = feeHandler.Store.GetFeeSchedule(1, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "0.005", schedule.CommissionPerShare.String(), "the default schedule is left alone")
+
+		rr = httptest.NewRecorder()
+		feeHandler.GetSchedule(rr, newFeeRequest(t, "GET", fmt.Sprintf("?account=%d", account.ID), nil))
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), schedule))
+		assert.Equal(t, "0.65", schedule.CommissionPerOrder.String())
+	})
+
+	t.Run("Unknown Account", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		feeHandler.GetSchedule(rr, newFeeRequest(t, "GET", "?account=99", nil))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Negative Values", func(t *testing.T) {
+		body, _ := json.Marshal(ScheduleRequest{CommissionPerOrder: dec("-1")})
+
+		rr := httptest.NewRecorder()
+		feeHandler.SaveSchedule(rr, newFeeRequest(t, "PUT", "", body))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
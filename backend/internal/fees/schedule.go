@@ -0,0 +1,58 @@
+package fees
+
+import (
+	"errors"
+	"github.com/drewbuiltit/trading-journal/backend/internal/instruments"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"github.com/shopspring/decimal"
+)
+
+// ScheduleFor returns the fee schedule that applies to the account: its own,
+// failing that the user's default, and nil when the user has neither.
+func ScheduleFor(s store.Store, userID int, accountID *int) (*models.FeeSchedule, error) {
+	if accountID != nil {
+		schedule, err := s.GetFeeSchedule(userID, accountID)
+		if !errors.Is(err, store.ErrNotFound) {
+			return schedule, err
+		}
+	}
+	schedule, err := s.GetFeeSchedule(userID, nil)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, nil
+	}
+	return schedule, err
+}
+
+// Commission is what the schedule charges for a fill: a per-order charge plus
+// a per-share charge, subject to the minimum.
+func Commission(schedule *models.FeeSchedule, execution *models.Execution) decimal.Decimal {
+	commission := schedule.CommissionPerOrder.Add(schedule.CommissionPerShare.Mul(execution.Quantity))
+	if commission.IsZero() {
+		return decimal.Zero
+	}
+	return decimal.Max(commission, schedule.CommissionMinimum)
+}
+
+// RegulatoryFees is the exchange and regulatory charge for a fill, which
+// brokers only pass on for sells. It is charged on the full notional, so an
+// option contract counts its multiplier.
+func RegulatoryFees(schedule *models.FeeSchedule, execution *models.Execution) decimal.Decimal {
+	if execution.Side != models.SideSell {
+		return decimal.Zero
+	}
+	return execution.Quantity.Mul(execution.Price).Mul(instruments.Multiplier(*execution)).Mul(schedule.RegulatoryFeeRate)
+}
+
+// Apply fills in the cost components an import source did not provide.
+func Apply(schedule *models.FeeSchedule, execution *models.Execution, hasCommission, hasFees bool) {
+	if schedule == nil {
+		return
+	}
+	if !hasCommission {
+		execution.Commission = Commission(schedule, execution)
+	}
+	if !hasFees {
+		execution.Fees = RegulatoryFees(schedule, execution)
+	}
+}
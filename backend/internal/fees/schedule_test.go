@@ -0,0 +1,77 @@
+package fees
+
+import (
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func dec(value string) decimal.Decimal {
+	return decimal.RequireFromString(value)
+}
+
+func TestCommission(t *testing.T) {
+	schedule := &models.FeeSchedule{CommissionPerShare: dec("0.005"), CommissionMinimum: dec("1")}
+
+	assert.Equal(t, "1", Commission(schedule, &models.Execution{Quantity: dec("100")}).String(), "Small orders should pay the minimum")
+	assert.Equal(t, "5", Commission(schedule, &models.Execution{Quantity: dec("1000")}).String())
+	assert.True(t, Commission(&models.FeeSchedule{}, &models.Execution{Quantity: dec("1000")}).IsZero(), "An empty schedule should charge nothing")
+}
+
+func TestRegulatoryFees(t *testing.T) {
+	schedule := &models.FeeSchedule{RegulatoryFeeRate: dec("0.0000278")}
+
+	sell := &models.Execution{Side: models.SideSell, Quantity: dec("100"), Price: dec("50")}
+	buy := &models.Execution{Side: models.SideBuy, Quantity: dec("100"), Price: dec("50")}
+
+	assert.Equal(t, "0.139", RegulatoryFees(schedule, sell).String())
+	assert.True(t, RegulatoryFees(schedule, buy).IsZero(), "Buys should not pay regulatory fees")
+}
+
+func TestApply(t *testing.T) {
+	schedule := &models.FeeSchedule{CommissionPerOrder: dec("1"), RegulatoryFeeRate: dec("0.001")}
+
+	t.Run("Fills Missing Columns", func(t *testing.T) {
+		execution := &models.Execution{Side: models.SideSell, Quantity: dec("10"), Price: dec("100")}
+		Apply(schedule, execution, false, false)
+
+		assert.Equal(t, "1", execution.Commission.String())
+		assert.Equal(t, "1", execution.Fees.String())
+	})
+
+	t.Run("Keeps Provided Columns", func(t *testing.T) {
+		execution := &models.Execution{Side: models.SideSell, Quantity: dec("10"), Price: dec("100"), Commission: dec("0.35")}
+		Apply(schedule, execution, true, false)
+
+		assert.Equal(t, "0.35", execution.Commission.String())
+		assert.Equal(t, "1", execution.Fees.String())
+	})
+
+	t.Run("Without Schedule", func(t *testing.T) {
+		execution := &models.Execution{Side: models.SideSell, Quantity: dec("10"), Price: dec("100")}
+		Apply(nil, execution, false, false)
+
+		assert.True(t, execution.Commission.IsZero())
+	})
+}
+
+func TestScheduleFor(t *testing.T) {
+	s := store.NewMemoryStore()
+	margin, cash := 1, 2
+
+	schedule, err := ScheduleFor(s, 1, &margin)
+	assert.NoError(t, err)
+	assert.Nil(t, schedule, "no schedule means no costs are filled in")
+
+	assert.NoError(t, s.SaveFeeSchedule(&models.FeeSchedule{UserID: 1, CommissionPerOrder: dec("1")}))
+	assert.NoError(t, s.SaveFeeSchedule(&models.FeeSchedule{UserID: 1, AccountID: &margin, CommissionPerOrder: dec("0.65")}))
+
+	schedule, err = ScheduleFor(s, 1, &margin)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.65", schedule.CommissionPerOrder.String())
+	schedule, err = ScheduleFor(s, 1, &cash)
+	assert.NoError(t, err)
+	assert.Equal(t, "1", schedule.CommissionPerOrder.String())
+}
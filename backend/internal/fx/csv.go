@@ -0,0 +1,81 @@
+package fx
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/drewbuiltit/trading-journal/backend/internal/instruments"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/shopspring/decimal"
+	"io"
+	"strings"
+	"time"
+)
+
+// columnNames maps the accepted header names to the FXRate field they fill.
+var columnNames = map[string]string{
+	"date":     "date",
+	"currency": "currency",
+	"from":     "currency",
+	"base":     "currency",
+	"quote":    "quote",
+	"to":       "quote",
+	"rate":     "rate",
+}
+
+// ParseCSV reads daily rates from CSV with a header row naming the date,
+// currency (or from), quote (or to) and rate columns, in any order. Dates are
+// YYYY-MM-DD.
+func ParseCSV(r io.Reader) ([]models.FXRate, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("CSV is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		if field, ok := columnNames[strings.ToLower(strings.TrimSpace(name))]; ok {
+			columns[field] = i
+		}
+	}
+	for _, field := range []string{"date", "currency", "quote", "rate"} {
+		if _, ok := columns[field]; !ok {
+			return nil, fmt.Errorf("CSV header is missing the %s column", field)
+		}
+	}
+
+	rates := []models.FXRate{}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		date, err := time.Parse("2006-01-02", strings.TrimSpace(record[columns["date"]]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: date must be YYYY-MM-DD", line)
+		}
+		currency := strings.ToUpper(strings.TrimSpace(record[columns["currency"]]))
+		quote := strings.ToUpper(strings.TrimSpace(record[columns["quote"]]))
+		if !instruments.ValidCurrency(currency) || !instruments.ValidCurrency(quote) {
+			return nil, fmt.Errorf("line %d: currencies must be three-letter codes", line)
+		}
+		rate, err := decimal.NewFromString(strings.TrimSpace(record[columns["rate"]]))
+		if err != nil || !rate.IsPositive() {
+			return nil, fmt.Errorf("line %d: rate must be a positive number", line)
+		}
+
+		rates = append(rates, models.FXRate{Date: date, Currency: currency, Quote: quote, Rate: rate})
+	}
+
+	return rates, nil
+}
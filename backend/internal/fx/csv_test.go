@@ -0,0 +1,37 @@
+package fx
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestParseCSV(t *testing.T) {
+	rates, err := ParseCSV(strings.NewReader("Date,From,To,Rate\n2024-05-01,gbp,usd,1.2501\n2024-05-02, EUR, USD, 1.0795\n"))
+	assert.NoError(t, err)
+	assert.Len(t, rates, 2)
+	assert.Equal(t, "GBP", rates[0].Currency)
+	assert.Equal(t, "USD", rates[0].Quote)
+	assert.Equal(t, "1.2501", rates[0].Rate.String())
+	assert.Equal(t, day(2), rates[1].Date)
+
+	rates, err = ParseCSV(strings.NewReader("rate,quote,currency,date\n155.2,JPY,USD,2024-05-01\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "USD", rates[0].Currency)
+	assert.Equal(t, "JPY", rates[0].Quote)
+}
+
+func TestParseCSV_Errors(t *testing.T) {
+	tests := map[string]string{
+		"":                     "CSV is empty",
+		"date,currency,rate\n": "CSV header is missing the quote column",
+		"date,currency,quote,rate\n05/01/2024,GBP,USD,1.2\n":                        "line 2: date must be YYYY-MM-DD",
+		"date,currency,quote,rate\n2024-05-01,POUND,USD,1.2\n":                      "line 2: currencies must be three-letter codes",
+		"date,currency,quote,rate\n2024-05-01,GBP,USD,1.2\n2024-05-02,GBP,USD,-1\n": "line 3: rate must be a positive number",
+	}
+
+	for input, message := range tests {
+		_, err := ParseCSV(strings.NewReader(input))
+		assert.EqualError(t, err, message)
+	}
+}
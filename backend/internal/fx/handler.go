@@ -0,0 +1,135 @@
+package fx
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/drewbuiltit/trading-journal/backend/internal/auth"
+	"github.com/drewbuiltit/trading-journal/backend/internal/instruments"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"net/http"
+	"strings"
+)
+
+type FXHandler struct {
+	Store store.Store
+}
+
+type BaseCurrencyRequest struct {
+	BaseCurrency string `json:"base_currency"`
+}
+
+type BaseCurrencyResponse struct {
+	BaseCurrency string `json:"base_currency"`
+}
+
+type ImportResponse struct {
+	Imported int `json:"imported"`
+}
+
+func (h *FXHandler) GetBaseCurrency(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	user, err := h.Store.GetUserByID(userID)
+	if err != nil {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(BaseCurrencyResponse{BaseCurrency: BaseCurrencyOf(user)})
+}
+
+func (h *FXHandler) SetBaseCurrency(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	var req BaseCurrencyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	currency := strings.ToUpper(strings.TrimSpace(req.BaseCurrency))
+	if !instruments.ValidCurrency(currency) {
+		http.Error(w, "base_currency must be a three-letter currency code", http.StatusBadRequest)
+		return
+	}
+
+	err := h.Store.UpdateBaseCurrency(userID, currency)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error updating base currency", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(BaseCurrencyResponse{BaseCurrency: currency})
+}
+
+// ListRates returns the user's loaded rates, optionally for one currency
+// and quote.
+func (h *FXHandler) ListRates(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	currency := strings.ToUpper(r.URL.Query().Get("currency"))
+	quote := strings.ToUpper(r.URL.Query().Get("quote"))
+
+	rates, err := h.Store.ListFXRates(userID)
+	if err != nil {
+		http.Error(w, "Error listing FX rates", http.StatusInternalServerError)
+		return
+	}
+
+	filtered := []models.FXRate{}
+	for _, rate := range rates {
+		if currency != "" && rate.Currency != currency {
+			continue
+		}
+		if quote != "" && rate.Quote != quote {
+			continue
+		}
+		filtered = append(filtered, rate)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(filtered)
+}
+
+// ImportRates loads daily rates from a CSV request body; see ParseCSV for
+// the format. Rates for a day and pair that were already loaded are replaced.
+func (h *FXHandler) ImportRates(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	rates, err := ParseCSV(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Store.SaveFXRates(userID, rates); err != nil {
+		http.Error(w, "Error saving FX rates", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ImportResponse{Imported: len(rates)})
+}
@@ -0,0 +1,71 @@
+package fx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/drewbuiltit/trading-journal/backend/internal/auth"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newFXRequest(t *testing.T, method, url string, body []byte, userID int) *http.Request {
+	req, err := http.NewRequest(method, url, bytes.NewBuffer(body))
+	assert.NoError(t, err)
+	return req.WithContext(context.WithValue(req.Context(), auth.UserContextKey, userID))
+}
+
+func TestBaseCurrencyHandler(t *testing.T) {
+	fxHandler := &FXHandler{Store: store.NewMemoryStore()}
+	assert.NoError(t, fxHandler.Store.CreateUser(&models.User{Username: "jane_doe", Email: "jane@example.com"}))
+
+	rr := httptest.NewRecorder()
+	fxHandler.GetBaseCurrency(rr, newFXRequest(t, "GET", "/base-currency", nil, 1))
+	assert.Equal(t, "{\"base_currency\":\"USD\"}\n", rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	fxHandler.SetBaseCurrency(rr, newFXRequest(t, "PUT", "/base-currency", []byte(`{"base_currency":"gbp"}`), 1))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	rr = httptest.NewRecorder()
+	fxHandler.GetBaseCurrency(rr, newFXRequest(t, "GET", "/base-currency", nil, 1))
+	assert.Equal(t, "{\"base_currency\":\"GBP\"}\n", rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	fxHandler.SetBaseCurrency(rr, newFXRequest(t, "PUT", "/base-currency", []byte(`{"base_currency":"pounds"}`), 1))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestImportRatesHandler(t *testing.T) {
+	fxHandler := &FXHandler{Store: store.NewMemoryStore()}
+
+	csv := "date,currency,quote,rate\n2024-05-01,GBP,USD,1.25\n2024-05-01,EUR,USD,1.08\n"
+	rr := httptest.NewRecorder()
+	fxHandler.ImportRates(rr, newFXRequest(t, "POST", "/fx-rates/import", []byte(csv), 1))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "{\"imported\":2}\n", rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	fxHandler.ImportRates(rr, newFXRequest(t, "POST", "/fx-rates/import", []byte("date,currency,quote,rate\n2024-05-01,GBP,USD,1.26\n"), 1))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	rr = httptest.NewRecorder()
+	fxHandler.ListRates(rr, newFXRequest(t, "GET", "/fx-rates?currency=gbp", nil, 1))
+
+	var rates []models.FXRate
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &rates))
+	assert.Len(t, rates, 1, "re-importing a day replaces its rate")
+	assert.Equal(t, "1.26", rates[0].Rate.String())
+
+	rr = httptest.NewRecorder()
+	fxHandler.ListRates(rr, newFXRequest(t, "GET", "/fx-rates", nil, 2))
+	assert.Equal(t, "[]\n", rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	fxHandler.ImportRates(rr, newFXRequest(t, "POST", "/fx-rates/import", []byte("date,rate\n"), 1))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
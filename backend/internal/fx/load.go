@@ -0,0 +1,28 @@
+package fx
+
+import (
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+)
+
+func BaseCurrencyOf(user *models.User) string {
+	if user == nil || user.BaseCurrency == "" {
+		return models.DefaultCurrency
+	}
+	return user.BaseCurrency
+}
+
+// ForUser loads the user's base currency and rate table.
+func ForUser(s store.Store, userID int) (string, *Table, error) {
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	rates, err := s.ListFXRates(userID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return BaseCurrencyOf(user), NewTable(rates), nil
+}
@@ -0,0 +1,95 @@
+package fx
+
+import (
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/shopspring/decimal"
+	"sort"
+	"strings"
+	"time"
+)
+
+// minorUnits are currencies some exchanges quote in that are a hundredth of
+// a major currency, such as pence on the LSE.
+var minorUnits = map[string]string{
+	"GBX": "GBP",
+	"ZAC": "ZAR",
+	"ILA": "ILS",
+}
+
+var hundred = decimal.NewFromInt(100)
+
+type pair struct {
+	from, to string
+}
+
+// Table answers conversion questions from a set of daily rates.
+type Table struct {
+	rates map[pair][]models.FXRate
+}
+
+func NewTable(rates []models.FXRate) *Table {
+	table := &Table{rates: make(map[pair][]models.FXRate)}
+	for _, rate := range rates {
+		key := pair{strings.ToUpper(rate.Currency), strings.ToUpper(rate.Quote)}
+		table.rates[key] = append(table.rates[key], rate)
+	}
+	for _, series := range table.rates {
+		sort.Slice(series, func(i, j int) bool { return series[i].Date.Before(series[j].Date) })
+	}
+	return table
+}
+
+// Rate is how many units of to one unit of from bought on the given day,
+// using the latest rate on or before it. Inverse rates are used when only the
+// opposite pair was loaded, and USD is used as a bridge between two other
+// currencies.
+func (t *Table) Rate(from, to string, on time.Time) (decimal.Decimal, bool) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	if from == to {
+		return decimal.NewFromInt(1), true
+	}
+	if major, ok := minorUnits[from]; ok {
+		rate, ok := t.Rate(major, to, on)
+		return rate.Div(hundred), ok
+	}
+	if major, ok := minorUnits[to]; ok {
+		rate, ok := t.Rate(from, major, on)
+		return rate.Mul(hundred), ok
+	}
+
+	if rate, ok := t.pairRate(from, to, on); ok {
+		return rate, true
+	}
+	if from != models.DefaultCurrency && to != models.DefaultCurrency {
+		first, ok := t.pairRate(from, models.DefaultCurrency, on)
+		if !ok {
+			return decimal.Zero, false
+		}
+		second, ok := t.pairRate(models.DefaultCurrency, to, on)
+		if !ok {
+			return decimal.Zero, false
+		}
+		return first.Mul(second), true
+	}
+	return decimal.Zero, false
+}
+
+func (t *Table) pairRate(from, to string, on time.Time) (decimal.Decimal, bool) {
+	if rate, ok := t.latest(pair{from, to}, on); ok {
+		return rate, true
+	}
+	if rate, ok := t.latest(pair{to, from}, on); ok && !rate.IsZero() {
+		return decimal.NewFromInt(1).Div(rate), true
+	}
+	return decimal.Zero, false
+}
+
+func (t *Table) latest(key pair, on time.Time) (decimal.Decimal, bool) {
+	series := t.rates[key]
+	day := time.Date(on.Year(), on.Month(), on.Day(), 0, 0, 0, 0, time.UTC)
+	i := sort.Search(len(series), func(i int) bool { return series[i].Date.After(day) })
+	if i == 0 {
+		return decimal.Zero, false
+	}
+	return series[i-1].Rate, true
+}
@@ -0,0 +1,43 @@
+package fx
+
+import (
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func day(d int) time.Time {
+	return time.Date(2024, 5, d, 0, 0, 0, 0, time.UTC)
+}
+
+func rate(d int, currency, quote, value string) models.FXRate {
+	return models.FXRate{Date: day(d), Currency: currency, Quote: quote, Rate: decimal.RequireFromString(value)}
+}
+
+func TestTable_Rate(t *testing.T) {
+	table := NewTable([]models.FXRate{
+		rate(2, "GBP", "USD", "1.25"),
+		rate(1, "GBP", "USD", "1.20"),
+		rate(1, "USD", "JPY", "155"),
+		rate(1, "EUR", "USD", "1.08"),
+	})
+
+	lookup := func(from, to string, on time.Time) string {
+		value, ok := table.Rate(from, to, on)
+		if !ok {
+			return "missing"
+		}
+		return value.String()
+	}
+
+	assert.Equal(t, "1", lookup("USD", "USD", day(1)))
+	assert.Equal(t, "1.2", lookup("GBP", "USD", day(1).Add(15*time.Hour)), "a rate applies for the whole day")
+	assert.Equal(t, "1.25", lookup("GBP", "USD", day(9)), "the latest earlier rate carries forward")
+	assert.Equal(t, "missing", lookup("GBP", "USD", day(1).Add(-time.Hour)))
+	assert.Equal(t, "0.8", lookup("USD", "GBP", day(2)), "inverse pairs are derived")
+	assert.Equal(t, "193.75", lookup("GBP", "JPY", day(2)), "USD bridges two other currencies")
+	assert.Equal(t, "0.0125", lookup("GBX", "USD", day(2)), "pence are a hundredth of a pound")
+	assert.Equal(t, "missing", lookup("CHF", "USD", day(2)))
+}
@@ -0,0 +1,93 @@
+package imports
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/drewbuiltit/trading-journal/backend/internal/auth"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"github.com/gorilla/mux"
+	"net/http"
+	"strconv"
+)
+
+func (h *ImportHandler) ListBatches(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	batches, err := h.Store.ListImportBatches(userID)
+	if err != nil {
+		http.Error(w, "Error listing imports", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(batches)
+}
+
+func (h *ImportHandler) GetBatch(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	batchID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid import ID", http.StatusBadRequest)
+		return
+	}
+
+	batch, err := h.Store.GetImportBatch(userID, batchID)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "Import not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error fetching import", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(batch)
+}
+
+// DeleteBatch rolls an import back, deleting every execution it created.
+// A batch that busted or corrected fills is refused with 409, as the fills
+// it deleted or overwrote cannot be restored.
+func (h *ImportHandler) DeleteBatch(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	batchID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid import ID", http.StatusBadRequest)
+		return
+	}
+
+	batch, err := h.Store.GetImportBatch(userID, batchID)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "Import not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error fetching import", http.StatusInternalServerError)
+		return
+	}
+	if batch.Busted > 0 || batch.Corrected > 0 {
+		http.Error(w, "Import busted or corrected earlier fills and cannot be rolled back", http.StatusConflict)
+		return
+	}
+
+	if err := h.Store.DeleteImportBatch(userID, batchID); err != nil {
+		http.Error(w, "Error rolling back import", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,405 @@
+package imports
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/drewbuiltit/trading-journal/backend/internal/instruments"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/shopspring/decimal"
+	"io"
+	"strings"
+	"time"
+)
+
+// previewRows is how many data rows a preview shows.
+const previewRows = 5
+
+// formatTokens maps date and time tokens onto Go layout elements, longest
+// first so that MM is not read as two Ms.
+var formatTokens = []struct{ token, layout string }{
+	{"YYYY", "2006"},
+	{"MMMM", "January"},
+	{"MMM", "Jan"},
+	{"YY", "06"},
+	{"MM", "01"},
+	{"DD", "02"},
+	{"HH", "15"},
+	{"hh", "03"},
+	{"mm", "04"},
+	{"ss", "05"},
+	{"M", "1"},
+	{"D", "2"},
+	{"h", "3"},
+	{"A", "PM"},
+	{"a", "pm"},
+}
+
+// dateFormats are tried in order when suggesting a template for a file.
+var dateFormats = []string{
+	"YYYY-MM-DD HH:mm:ss",
+	"YYYY-MM-DDTHH:mm:ss",
+	"YYYY-MM-DD HH:mm",
+	"YYYY-MM-DD",
+	"M/D/YYYY h:mm:ss A",
+	"M/D/YYYY HH:mm:ss",
+	"M/D/YYYY HH:mm",
+	"M/D/YYYY",
+	"MM/DD/YY",
+	"DD.MM.YYYY HH:mm:ss",
+	"DD.MM.YYYY",
+	"YYYYMMDD",
+}
+
+// columnGuesses lists header names commonly used for each template column.
+var columnGuesses = []struct {
+	field string
+	names []string
+}{
+	{"symbol", []string{"symbol", "ticker", "instrument", "contract", "security"}},
+	{"side", []string{"side", "action", "buy/sell", "b/s", "type", "transaction type"}},
+	{"quantity", []string{"quantity", "qty", "shares", "filled qty", "filled", "size", "amount"}},
+	{"price", []string{"price", "fill price", "avg price", "execution price", "trade price", "avg fill price"}},
+	{"date", []string{"date/time", "datetime", "date", "trade date", "time", "executed at", "filled at", "fill time"}},
+	{"time", []string{"time", "trade time", "execution time"}},
+	{"commission", []string{"commission", "commissions", "comm", "commission/fee"}},
+	{"fees", []string{"fees", "fee", "reg fees", "exchange fees", "other fees"}},
+	{"currency", []string{"currency", "ccy", "curr"}},
+	{"id", []string{"execution id", "exec id", "fill id", "trade id", "id"}},
+}
+
+type Preview struct {
+	Columns   []string           `json:"columns"`
+	Rows      [][]string         `json:"rows"`
+	Suggested models.CSVTemplate `json:"suggested"`
+}
+
+// Layout converts a template date or time format into a Go time layout.
+func Layout(format string) string {
+	var b strings.Builder
+	for i := 0; i < len(format); {
+		matched := false
+		for _, t := range formatTokens {
+			if strings.HasPrefix(format[i:], t.token) {
+				b.WriteString(t.layout)
+				i += len(t.token)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			b.WriteByte(format[i])
+			i++
+		}
+	}
+	return b.String()
+}
+
+// PreviewCSV returns the header and first rows of a CSV file with a
+// template guessed from the header names and the first row's values.
+func PreviewCSV(data []byte) (*Preview, error) {
+	reader := newCSVReader(data)
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("CSV is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %v", err)
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+
+	preview := &Preview{Columns: header, Rows: [][]string{}}
+	for len(preview.Rows) < previewRows {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %v", err)
+		}
+		preview.Rows = append(preview.Rows, row)
+	}
+
+	preview.Suggested = suggest(header, preview.Rows)
+	return preview, nil
+}
+
+func suggest(header []string, rows [][]string) models.CSVTemplate {
+	template := models.CSVTemplate{}
+	used := make(map[string]bool)
+	columns := map[string]*string{
+		"symbol":     &template.SymbolColumn,
+		"side":       &template.SideColumn,
+		"quantity":   &template.QuantityColumn,
+		"price":      &template.PriceColumn,
+		"date":       &template.DateColumn,
+		"time":       &template.TimeColumn,
+		"commission": &template.CommissionColumn,
+		"fees":       &template.FeesColumn,
+		"currency":   &template.CurrencyColumn,
+		"id":         &template.IDColumn,
+	}
+	for _, guess := range columnGuesses {
+		for _, name := range guess.names {
+			if column, ok := findColumn(header, name); ok && !used[column] {
+				*columns[guess.field] = column
+				used[column] = true
+				break
+			}
+		}
+	}
+
+	if len(rows) == 0 || template.DateColumn == "" {
+		return template
+	}
+	index := indexOf(header, template.DateColumn)
+	if index >= len(rows[0]) {
+		return template
+	}
+	sample := strings.TrimSpace(rows[0][index])
+	for _, format := range dateFormats {
+		if _, err := time.Parse(Layout(format), sample); err == nil {
+			template.DateFormat = format
+			break
+		}
+	}
+	if template.TimeColumn != "" {
+		template.TimeFormat = "HH:mm:ss"
+	}
+	return template
+}
+
+// csvFormat describes how to read fills from a CSV file: the header name of
+// each column read, the time layouts to try in order and, for platform
+// exports, how to turn the file's symbols into the journal's.
+type csvFormat struct {
+	columns map[string]string
+	layouts []string
+	symbol  func(string) (string, error)
+}
+
+// ParseCSV reads fills from a CSV file laid out as the template describes.
+// Times are read in loc. Sides may be spelled out (Buy, Sold, Sell Short, Buy
+// to Cover) or abbreviated to B and S; without a side column a negative
+// quantity is a sale.
+func ParseCSV(data []byte, template *models.CSVTemplate, loc *time.Location) ([]Record, error) {
+	layout := Layout(template.DateFormat)
+	if template.TimeColumn != "" {
+		timeFormat := template.TimeFormat
+		if timeFormat == "" {
+			timeFormat = "HH:mm:ss"
+		}
+		layout += " " + Layout(timeFormat)
+	}
+
+	return parseCSV(data, csvFormat{
+		columns: map[string]string{
+			"symbol":     template.SymbolColumn,
+			"side":       template.SideColumn,
+			"quantity":   template.QuantityColumn,
+			"price":      template.PriceColumn,
+			"date":       template.DateColumn,
+			"time":       template.TimeColumn,
+			"commission": template.CommissionColumn,
+			"fees":       template.FeesColumn,
+			"currency":   template.CurrencyColumn,
+			"id":         template.IDColumn,
+		},
+		layouts: []string{layout},
+	}, loc)
+}
+
+func parseCSV(data []byte, format csvFormat, loc *time.Location) ([]Record, error) {
+	reader := newCSVReader(data)
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("CSV is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %v", err)
+	}
+
+	columns := make(map[string]int)
+	for field, name := range format.columns {
+		if name == "" {
+			continue
+		}
+		column, ok := findColumn(header, name)
+		if !ok {
+			return nil, fmt.Errorf("CSV has no column named %q", name)
+		}
+		columns[field] = indexOf(header, column)
+	}
+
+	records := []Record{}
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// The reader moves on to the next row after a malformed one.
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, fmt.Errorf("invalid CSV: %v", err)
+			}
+			records = append(records, Record{Line: parseErr.Line, Err: err})
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+		if blank(row) {
+			continue
+		}
+
+		get := func(field string) string {
+			index, ok := columns[field]
+			if !ok || index >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[index])
+		}
+		record := Record{Line: line, Account: get("account"), ExecID: get("id")}
+		_, record.HasCommission = columns["commission"]
+		_, record.HasFees = columns["fees"]
+		record.Execution, record.Err = csvExecution(get, format.layouts, loc)
+		if record.Err == nil && format.symbol != nil {
+			record.Execution.Symbol, record.Err = format.symbol(record.Execution.Symbol)
+		}
+		records = append(records, record)
+	}
+	if len(records) == 0 {
+		return nil, errors.New("CSV has no rows")
+	}
+	return records, nil
+}
+
+func csvExecution(get func(string) string, layouts []string, loc *time.Location) (models.Execution, error) {
+	var execution models.Execution
+
+	execution.Symbol = get("symbol")
+	if execution.Symbol == "" {
+		return execution, errors.New("symbol is required")
+	}
+
+	quantity, err := parseAmount(get("quantity"))
+	if err != nil || quantity.IsZero() {
+		return execution, errors.New("quantity must be a non-zero number")
+	}
+	execution.Quantity = quantity.Abs()
+
+	side := get("side")
+	if side == "" {
+		execution.Side = models.SideBuy
+		if quantity.IsNegative() {
+			execution.Side = models.SideSell
+		}
+	} else if execution.Side, execution.Action = parseSide(side); execution.Side == "" {
+		return execution, fmt.Errorf("unrecognised side %q", side)
+	}
+
+	execution.Price, err = parseAmount(get("price"))
+	if err != nil || execution.Price.IsNegative() {
+		return execution, errors.New("price must not be negative")
+	}
+	if execution.Commission, err = parseAmount(get("commission")); err != nil {
+		return execution, errors.New("commission must be a number")
+	}
+	execution.Commission = execution.Commission.Abs()
+	if execution.Fees, err = parseAmount(get("fees")); err != nil {
+		return execution, errors.New("fees must be a number")
+	}
+	execution.Fees = execution.Fees.Abs()
+
+	execution.Currency = strings.ToUpper(get("currency"))
+	if execution.Currency != "" && !instruments.ValidCurrency(execution.Currency) {
+		return execution, errors.New("currency must be a three-letter currency code")
+	}
+
+	value := get("date")
+	if t := get("time"); t != "" {
+		value += " " + t
+	}
+	for _, layout := range layouts {
+		if executedAt, err := time.ParseInLocation(layout, value, loc); err == nil {
+			execution.ExecutedAt = executedAt.UTC()
+			return execution, nil
+		}
+	}
+	return execution, fmt.Errorf("date %q does not match the expected format", value)
+}
+
+// parseSide reads a side as brokers write it, returning the action too when
+// the wording names one.
+func parseSide(value string) (string, string) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch {
+	// "Buy to Cover Short" and "Short Cover" buy back a short.
+	case strings.Contains(value, "cover"):
+		return models.SideBuy, models.ActionBuyToCover
+	case strings.Contains(value, "short"):
+		return models.SideSell, models.ActionSellShort
+	case strings.HasPrefix(value, "b"):
+		return models.SideBuy, ""
+	case strings.HasPrefix(value, "s"):
+		return models.SideSell, ""
+	}
+	return "", ""
+}
+
+// parseAmount reads a number that may carry a currency sign, thousands
+// separators or accounting parentheses for negatives. A blank value is zero.
+func parseAmount(value string) (decimal.Decimal, error) {
+	value = strings.TrimSpace(value)
+	negative := strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")")
+	value = strings.Trim(value, "()")
+	value = strings.NewReplacer(",", "", "$", "", " ", "").Replace(value)
+	if value == "" {
+		return decimal.Zero, nil
+	}
+	amount, err := decimal.NewFromString(value)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if negative {
+		amount = amount.Neg()
+	}
+	return amount, nil
+}
+
+func newCSVReader(data []byte) *csv.Reader {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	return reader
+}
+
+// findColumn finds a header name ignoring case and surrounding space.
+func findColumn(header []string, name string) (string, bool) {
+	for _, column := range header {
+		if strings.EqualFold(strings.TrimSpace(column), strings.TrimSpace(name)) {
+			return column, true
+		}
+	}
+	return "", false
+}
+
+func indexOf(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
+
+func blank(row []string) bool {
+	for _, value := range row {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
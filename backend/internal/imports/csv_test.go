@@ -0,0 +1,123 @@
+package imports
+
+import (
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var brokerTemplate = models.CSVTemplate{
+	Name:             "Broker",
+	SymbolColumn:     "Ticker",
+	SideColumn:       "Action",
+	QuantityColumn:   "Qty",
+	PriceColumn:      "Fill Price",
+	DateColumn:       "Date",
+	TimeColumn:       "Time",
+	CommissionColumn: "Comm",
+	FeesColumn:       "fees",
+	DateFormat:       "MM/DD/YYYY",
+	TimeFormat:       "HH:mm:ss",
+}
+
+func TestLayout(t *testing.T) {
+	assert.Equal(t, "2006-01-02 15:04:05", Layout("YYYY-MM-DD HH:mm:ss"))
+	assert.Equal(t, "1/2/06 3:04 PM", Layout("M/D/YY h:mm A"))
+	assert.Equal(t, "02 Jan 2006", Layout("DD MMM YYYY"))
+}
+
+func TestPreviewCSV(t *testing.T) {
+	preview, err := PreviewCSV(readFixture(t, "broker.csv"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Date", "Time", "Ticker", "Action", "Qty", "Fill Price", "Comm", "Fees"}, preview.Columns)
+	assert.Len(t, preview.Rows, 5)
+
+	suggested := preview.Suggested
+	assert.Equal(t, "Ticker", suggested.SymbolColumn)
+	assert.Equal(t, "Action", suggested.SideColumn)
+	assert.Equal(t, "Qty", suggested.QuantityColumn)
+	assert.Equal(t, "Fill Price", suggested.PriceColumn)
+	assert.Equal(t, "Date", suggested.DateColumn)
+	assert.Equal(t, "Time", suggested.TimeColumn)
+	assert.Equal(t, "Comm", suggested.CommissionColumn)
+	assert.Equal(t, "Fees", suggested.FeesColumn)
+	assert.Equal(t, "M/D/YYYY", suggested.DateFormat)
+	assert.Equal(t, "HH:mm:ss", suggested.TimeFormat)
+
+	_, err = PreviewCSV(nil)
+	assert.EqualError(t, err, "CSV is empty")
+}
+
+func TestParseCSV(t *testing.T) {
+	records, err := ParseCSV(readFixture(t, "broker.csv"), &brokerTemplate, time.UTC)
+	assert.NoError(t, err)
+	assert.Len(t, records, 7, "blank lines are ignored")
+
+	buy := records[0].Execution
+	assert.Equal(t, 2, records[0].Line)
+	assert.Equal(t, "AAPL", buy.Symbol)
+	assert.Equal(t, models.SideBuy, buy.Side)
+	assert.Equal(t, "170.25", buy.Price.String())
+	assert.Equal(t, time.Date(2024, 5, 1, 9, 31, 2, 0, time.UTC), buy.ExecutedAt)
+
+	sell := records[1].Execution
+	assert.Equal(t, models.SideSell, sell.Side)
+	assert.Equal(t, "1172.5", sell.Price.String())
+	assert.Equal(t, "1", sell.Commission.String())
+	assert.Equal(t, "0.03", sell.Fees.String())
+
+	assert.Equal(t, models.ActionSellShort, records[2].Execution.Action)
+	assert.Equal(t, 6, records[3].Line)
+	assert.Equal(t, models.ActionBuyToCover, records[3].Execution.Action)
+
+	assert.EqualError(t, records[4].Err, "symbol is required")
+	assert.EqualError(t, records[5].Err, `unrecognised side "Transfer"`)
+	assert.EqualError(t, records[6].Err, `date "2024-05-03 10:07:00" does not match the expected format`)
+}
+
+func TestParseCSV_SignedQuantity(t *testing.T) {
+	template := models.CSVTemplate{SymbolColumn: "symbol", QuantityColumn: "qty", PriceColumn: "price", DateColumn: "time", DateFormat: "YYYY-MM-DDTHH:mm:ss"}
+	newYork, err := time.LoadLocation("America/New_York")
+	assert.NoError(t, err)
+
+	records, err := ParseCSV([]byte("symbol,qty,price,time\nNVDA,-5,900,2024-05-01T09:30:00\n"), &template, newYork)
+	assert.NoError(t, err)
+	assert.Equal(t, models.SideSell, records[0].Execution.Side)
+	assert.Equal(t, "5", records[0].Execution.Quantity.String())
+	assert.Equal(t, time.Date(2024, 5, 1, 13, 30, 0, 0, time.UTC), records[0].Execution.ExecutedAt)
+
+	_, err = ParseCSV([]byte("symbol,quantity,price,time\n"), &template, time.UTC)
+	assert.EqualError(t, err, `CSV has no column named "qty"`)
+
+	_, err = ParseCSV([]byte("symbol,qty,price,time\n"), &template, time.UTC)
+	assert.EqualError(t, err, "CSV has no rows")
+}
+
+func TestParseCSV_MalformedRow(t *testing.T) {
+	template := models.CSVTemplate{SymbolColumn: "symbol", QuantityColumn: "qty", PriceColumn: "price", DateColumn: "date", DateFormat: "YYYY-MM-DD"}
+
+	records, err := ParseCSV([]byte("symbol,qty,price,date\nAA\"PL,10,170,2024-05-01\nMSFT,5,400,2024-05-01\n"), &template, time.UTC)
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+	assert.Equal(t, 2, records[0].Line)
+	assert.ErrorContains(t, records[0].Err, "bare \" in non-quoted-field")
+	assert.NoError(t, records[1].Err, "rows after a malformed one are still read")
+	assert.Equal(t, 3, records[1].Line)
+}
+
+func TestParseSide(t *testing.T) {
+	for value, want := range map[string][2]string{
+		"Buy":                {models.SideBuy, ""},
+		"SELL":               {models.SideSell, ""},
+		"Sell Short":         {models.SideSell, models.ActionSellShort},
+		"Short":              {models.SideSell, models.ActionSellShort},
+		"Buy to Cover":       {models.SideBuy, models.ActionBuyToCover},
+		"Buy to Cover Short": {models.SideBuy, models.ActionBuyToCover},
+		"Short Cover":        {models.SideBuy, models.ActionBuyToCover},
+		"Transfer":           {"", ""},
+	} {
+		side, action := parseSide(value)
+		assert.Equal(t, want, [2]string{side, action}, value)
+	}
+}
@@ -0,0 +1,291 @@
+package imports
+
+import (
+	"errors"
+	"fmt"
+	"github.com/drewbuiltit/trading-journal/backend/internal/instruments"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/shopspring/decimal"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fixMessage is a FIX message as tag number to value. Repeating groups are
+// flattened with the first occurrence of a tag winning, which is enough for
+// the tags a fill is read from.
+type fixMessage map[string]string
+
+// fixRef points at the fill an ExecID stands for: the ExecID the fill is
+// imported under and, when it is in this file, the index of its record.
+type fixRef struct {
+	execID string
+	index  int
+}
+
+// ParseFIX reads the fills in a FIX 4.2 or 4.4 log, such as a drop copy
+// session's. Every ExecutionReport (35=8) that reports a fill, partial or
+// complete, becomes a record keyed by its ExecID; order acknowledgements,
+// cancels and status reports are passed over, as is every other message
+// type. Busts and corrections name the fill they change by ExecRefID: one in
+// the same file is resolved here, and one imported earlier is left to Import.
+// FIX times are UTC, so no zone is needed.
+func ParseFIX(data []byte, _ *time.Location) ([]Record, error) {
+	records := []Record{}
+	refs := make(map[string]fixRef)
+	found := false
+	for i, line := range strings.Split(string(data), "\n") {
+		start := strings.Index(line, "8=FIX")
+		if start < 0 {
+			continue
+		}
+		found = true
+
+		message := parseFIXMessage(line[start:])
+		if message["35"] != "8" {
+			continue
+		}
+		record, ok := fixRecord(message, i+1)
+		if !ok {
+			continue
+		}
+		if record.Err != nil {
+			records = append(records, record)
+			continue
+		}
+
+		execID := message["17"]
+		if !record.Bust && !record.Correction {
+			if _, seen := refs[execID]; !seen {
+				refs[execID] = fixRef{execID: execID, index: len(records)}
+			}
+			records = append(records, record)
+			continue
+		}
+
+		target, ok := refs[message["19"]]
+		if !ok {
+			target = fixRef{execID: message["19"], index: -1}
+		}
+		record.ExecID = target.execID
+		if target.index >= 0 {
+			prior := &records[target.index]
+			prior.Err = nil
+			if record.Bust {
+				prior.Skip = fmt.Sprintf("busted on line %d", record.Line)
+				record.Skip = fmt.Sprintf("busts the fill on line %d", prior.Line)
+			} else {
+				prior.Skip = fmt.Sprintf("corrected on line %d", record.Line)
+			}
+		}
+		if record.Bust {
+			target.index = -1
+		} else {
+			target.index = len(records)
+		}
+		refs[target.execID] = target
+		refs[message["19"]] = target
+		refs[execID] = target
+		records = append(records, record)
+	}
+
+	if !found {
+		return nil, errors.New("file has no FIX messages")
+	}
+	return records, nil
+}
+
+// parseFIXMessage splits a message on the SOH delimiter, or on the | or ^A
+// that logs often print in its place.
+func parseFIXMessage(line string) fixMessage {
+	line = strings.TrimRight(line, "\r")
+	delimiter := "|"
+	switch {
+	case strings.Contains(line, "\x01"):
+		delimiter = "\x01"
+	case strings.Contains(line, "^A"):
+		delimiter = "^A"
+	}
+
+	message := make(fixMessage)
+	for _, field := range strings.Split(line, delimiter) {
+		tag, value, ok := strings.Cut(field, "=")
+		if _, err := strconv.Atoi(tag); !ok || err != nil {
+			continue
+		}
+		if _, seen := message[tag]; !seen {
+			message[tag] = strings.TrimSpace(value)
+		}
+	}
+	return message
+}
+
+// fixRecord reads an ExecutionReport, reporting false for those that neither
+// fill nor change a fill. FIX 4.2 says what a report does with
+// ExecTransType (20) and FIX 4.4 with ExecType (150).
+func fixRecord(message fixMessage, line int) (Record, bool) {
+	record := Record{Line: line, Account: message["1"], ExecID: message["17"], HasCommission: message["12"] != ""}
+	record.Execution.Symbol = strings.ToUpper(message["55"])
+
+	switch {
+	case message["20"] == "1" || message["150"] == "H":
+		record.Bust = true
+	case message["20"] == "2" || message["150"] == "G":
+		record.Correction = true
+	case message["20"] == "3":
+		return record, false
+	case message["150"] == "1" || message["150"] == "2" || message["150"] == "F":
+	default:
+		return record, false
+	}
+
+	if version := message["8"]; version != "FIX.4.2" && version != "FIX.4.4" {
+		record.Err = fmt.Errorf("%s messages are not supported", version)
+		return record, true
+	}
+	if record.ExecID == "" {
+		record.Err = errors.New("ExecID (17) is required")
+		return record, true
+	}
+	if (record.Bust || record.Correction) && message["19"] == "" {
+		record.Err = errors.New("ExecRefID (19) is required to bust or correct a fill")
+		return record, true
+	}
+	if record.Bust {
+		return record, true
+	}
+
+	execution, err := fixExecution(message)
+	if err != nil {
+		record.Err = err
+		return record, true
+	}
+	record.Execution = execution
+	return record, true
+}
+
+func fixExecution(message fixMessage) (models.Execution, error) {
+	var execution models.Execution
+
+	symbol, err := fixSymbol(message)
+	if err != nil {
+		return execution, err
+	}
+	execution.Symbol = symbol
+
+	execution.Quantity, err = decimal.NewFromString(message["32"])
+	if err != nil || !execution.Quantity.IsPositive() {
+		return execution, errors.New("LastQty (32) must be a positive number")
+	}
+	execution.Price, err = decimal.NewFromString(message["31"])
+	if err != nil || execution.Price.IsNegative() {
+		return execution, errors.New("LastPx (31) must not be negative")
+	}
+
+	switch message["54"] {
+	case "1", "3":
+		execution.Side = models.SideBuy
+	case "2", "4":
+		execution.Side = models.SideSell
+	case "5", "6":
+		execution.Side = models.SideSell
+		execution.Action = models.ActionSellShort
+	default:
+		return execution, fmt.Errorf("side %q is not a buy or a sell", message["54"])
+	}
+	if execution.Action == "" {
+		switch message["77"] {
+		case "O":
+			execution.Action = models.ActionBuy
+			if execution.Side == models.SideSell {
+				execution.Action = models.ActionSellShort
+			}
+		case "C":
+			execution.Action = models.ActionSell
+			if execution.Side == models.SideBuy {
+				execution.Action = models.ActionBuyToCover
+			}
+		}
+	}
+
+	// CommType 1 is per unit, 2 a percentage of the fill's value and 3, or
+	// none, an amount. The others, such as points, have no amount the
+	// journal can work out.
+	if message["12"] != "" {
+		execution.Commission, err = decimal.NewFromString(message["12"])
+		if err != nil {
+			return execution, errors.New("Commission (12) must be a number")
+		}
+		execution.Commission = execution.Commission.Abs()
+		switch message["13"] {
+		case "", "3":
+		case "1":
+			execution.Commission = execution.Commission.Mul(execution.Quantity)
+		case "2":
+			notional := execution.Quantity.Mul(execution.Price).Mul(instruments.Multiplier(execution))
+			execution.Commission = notional.Mul(execution.Commission).Div(decimal.NewFromInt(100))
+		default:
+			return execution, fmt.Errorf("CommType (13) %q is not supported", message["13"])
+		}
+	}
+
+	execution.Currency = strings.ToUpper(message["15"])
+	if execution.Currency != "" && !instruments.ValidCurrency(execution.Currency) {
+		return execution, errors.New("Currency (15) must be a three-letter currency code")
+	}
+
+	value := message["60"]
+	if value == "" {
+		value = message["52"]
+	}
+	execution.ExecutedAt, err = time.Parse("20060102-15:04:05", value)
+	if err != nil {
+		return execution, fmt.Errorf("unrecognised TransactTime %q", value)
+	}
+	return execution, nil
+}
+
+// fixSymbol returns the symbol as the journal spells it, building futures
+// and option symbols from the contract tags when Symbol (55) is only the
+// root or underlying.
+func fixSymbol(message fixMessage) (string, error) {
+	symbol := strings.ToUpper(message["55"])
+	if symbol == "" {
+		return "", errors.New("Symbol (55) is required")
+	}
+
+	maturity := message["200"]
+	switch strings.ToUpper(message["167"]) {
+	case "FUT":
+		if len(maturity) >= 6 {
+			year, _ := strconv.Atoi(maturity[:4])
+			month, _ := strconv.Atoi(maturity[4:6])
+			if month >= 1 && month <= 12 {
+				return futureSymbol(fmt.Sprintf("%s%c%02d", symbol, monthCodes[month-1], year%100), symbol+" "+maturity)
+			}
+		}
+		return futureSymbol(symbol, symbol)
+	case "OPT":
+		if _, err := instruments.ParseOCC(symbol); err == nil {
+			return symbol, nil
+		}
+		date := message["541"]
+		if date == "" && len(maturity) == 8 {
+			date = maturity
+		}
+		if date == "" && len(maturity) == 6 && message["205"] != "" {
+			date = fmt.Sprintf("%s%02s", maturity, message["205"])
+		}
+		expiry, err := time.Parse("20060102", date)
+		strike, strikeErr := decimal.NewFromString(message["202"])
+		if err != nil || strikeErr != nil || (message["201"] != "0" && message["201"] != "1") {
+			return "", fmt.Errorf("option on %s is missing its expiry, strike or put/call", symbol)
+		}
+		right := models.RightCall
+		if message["201"] == "0" {
+			right = models.RightPut
+		}
+		return instruments.FormatOCC(models.Instrument{Underlying: symbol, Expiry: &expiry, Strike: strike, Right: right}), nil
+	}
+	return symbol, nil
+}
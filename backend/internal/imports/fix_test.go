@@ -0,0 +1,163 @@
+package imports
+
+import (
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseFIX(t *testing.T) {
+	records, err := ParseFIX(readFixture(t, "dropcopy.log"), time.UTC)
+	assert.NoError(t, err)
+	assert.Len(t, records, 10)
+
+	partial := records[0]
+	assert.Equal(t, 4, partial.Line)
+	assert.Equal(t, "DC123", partial.Account)
+	assert.Equal(t, "E1", partial.ExecID)
+	assert.Equal(t, "ESM24", partial.Execution.Symbol)
+	assert.Equal(t, models.SideBuy, partial.Execution.Side)
+	assert.Equal(t, "1", partial.Execution.Quantity.String(), "a partial fill is its last quantity, not the cumulative one")
+	assert.Equal(t, "5100.25", partial.Execution.Price.String())
+	assert.Equal(t, "2.15", partial.Execution.Commission.String())
+	assert.Equal(t, time.Date(2024, 5, 1, 13, 30, 15, 123000000, time.UTC), partial.Execution.ExecutedAt)
+	assert.Equal(t, "2", records[1].Execution.Quantity.String())
+	assert.Equal(t, "E2", records[2].ExecID, "a resent fill keeps its ExecID")
+
+	assert.Equal(t, "corrected on line 9", records[3].Skip)
+	correction := records[4]
+	assert.True(t, correction.Correction)
+	assert.Equal(t, "E3", correction.ExecID, "a correction is imported under the fill's ExecID")
+	assert.Equal(t, "170.05", correction.Execution.Price.String())
+	assert.Equal(t, "0.5", correction.Execution.Commission.String(), "per-unit commission")
+	assert.Equal(t, "USD", correction.Execution.Currency)
+
+	assert.Equal(t, "busted on line 11", records[5].Skip)
+	assert.Equal(t, "busts the fill on line 10", records[6].Skip)
+	assert.True(t, records[7].Bust)
+	assert.Equal(t, "OLD1", records[7].ExecID)
+	assert.Empty(t, records[7].Skip, "a bust of a fill from another file is left to Import")
+
+	option := records[8].Execution
+	assert.Equal(t, "SPY   240621C00500000", option.Symbol)
+	assert.Equal(t, models.ActionSellShort, option.Action)
+
+	assert.EqualError(t, records[9].Err, `contract "ZZ 202406" is not a known futures contract`)
+}
+
+func TestParseFIX_Delimiters(t *testing.T) {
+	message := "8=FIX.4.4|35=8|17=A1|31=10.5|32=3|54=2|55=IBM|60=20240501-14:00:00|150=F|"
+	for _, delimiter := range []string{"|", "\x01", "^A"} {
+		records, err := ParseFIX([]byte(strings.ReplaceAll(message, "|", delimiter)), time.UTC)
+		assert.NoError(t, err)
+		if assert.Len(t, records, 1) {
+			assert.NoError(t, records[0].Err)
+			assert.Equal(t, "IBM", records[0].Execution.Symbol)
+			assert.Equal(t, models.SideSell, records[0].Execution.Side)
+		}
+	}
+}
+
+func TestParseFIX_PercentCommission(t *testing.T) {
+	records, err := ParseFIX([]byte(strings.Join([]string{
+		"8=FIX.4.4|35=8|17=P1|12=0.1|13=2|31=50|32=200|54=1|55=IBM|60=20240501-14:00:00|150=F|",
+		"8=FIX.4.4|35=8|17=P2|12=0.1|13=2|31=5100|32=1|54=1|55=ES|167=FUT|200=202406|60=20240501-14:00:00|150=F|",
+	}, "\n")), time.UTC)
+	assert.NoError(t, err)
+	assert.Equal(t, "10", records[0].Execution.Commission.String(), "0.1% of 200 at 50")
+	assert.True(t, records[0].HasCommission)
+	assert.False(t, records[0].HasFees)
+	assert.Equal(t, "255", records[1].Execution.Commission.String(), "the notional takes in the contract multiplier")
+}
+
+func TestParseFIX_Rejects(t *testing.T) {
+	_, err := ParseFIX([]byte("Date,Symbol\n2024-05-01,AAPL\n"), time.UTC)
+	assert.EqualError(t, err, "file has no FIX messages")
+
+	records, err := ParseFIX([]byte(strings.Join([]string{
+		"8=FIX.4.0|35=8|17=A1|20=0|31=10|32=1|54=1|55=IBM|60=20240501-14:00:00|150=2|",
+		"8=FIX.4.4|35=8|31=10|32=1|54=1|55=IBM|60=20240501-14:00:00|150=F|",
+		"8=FIX.4.4|35=8|17=A3|150=H|",
+		"8=FIX.4.4|35=8|17=A4|31=10|32=0|54=1|55=IBM|60=20240501-14:00:00|150=F|",
+		"8=FIX.4.4|35=8|17=A5|31=10|32=1|54=8|55=IBM|60=20240501-14:00:00|150=F|",
+		"8=FIX.4.4|35=8|17=A6|31=10|32=1|54=1|55=SPY|60=20240501-14:00:00|150=F|167=OPT|202=500|",
+		"8=FIX.4.4|35=8|17=A7|12=1|13=6|31=10|32=1|54=1|55=IBM|60=20240501-14:00:00|150=F|",
+	}, "\n")), time.UTC)
+	assert.NoError(t, err)
+	assert.Len(t, records, 7)
+	assert.EqualError(t, records[0].Err, "FIX.4.0 messages are not supported")
+	assert.EqualError(t, records[1].Err, "ExecID (17) is required")
+	assert.EqualError(t, records[2].Err, "ExecRefID (19) is required to bust or correct a fill")
+	assert.EqualError(t, records[3].Err, "LastQty (32) must be a positive number")
+	assert.EqualError(t, records[4].Err, `side "8" is not a buy or a sell`)
+	assert.EqualError(t, records[5].Err, "option on SPY is missing its expiry, strike or put/call")
+	assert.EqualError(t, records[6].Err, `CommType (13) "6" is not supported`)
+}
+
+func TestImport_FIXBustsAndCorrections(t *testing.T) {
+	s := store.NewMemoryStore()
+	account := &models.Account{UserID: 1, Name: "Algo", Number: "DC123"}
+	assert.NoError(t, s.CreateAccount(account))
+	_, err := Import(s, 1, account, models.SourceFIX, []Record{{Line: 1, ExecID: "OLD1", Execution: models.Execution{
+		Symbol:     "XYZ",
+		Side:       models.SideBuy,
+		Quantity:   decimal.NewFromInt(10),
+		Price:      decimal.RequireFromString("99.5"),
+		ExecutedAt: time.Date(2024, 4, 30, 14, 0, 0, 0, time.UTC),
+	}}})
+	assert.NoError(t, err)
+
+	records, err := ParseFIX(readFixture(t, "dropcopy.log"), time.UTC)
+	assert.NoError(t, err)
+	report, err := Import(s, 1, account, models.SourceFIX, records)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, report.Created)
+	assert.Equal(t, 1, report.Duplicates)
+	assert.Equal(t, 3, report.Skipped)
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, 1, report.Busted)
+	assert.Equal(t, StatusBusted, report.Rows[7].Status)
+	assert.Equal(t, "XYZ", report.Rows[7].Symbol)
+	batch, err := s.GetImportBatch(1, report.BatchID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, batch.Busted, "the batch keeps the count of fills it busted")
+
+	executions, err := s.ListExecutions(1)
+	assert.NoError(t, err)
+	symbols := []string{}
+	for _, execution := range executions {
+		symbols = append(symbols, execution.Symbol)
+	}
+	assert.Equal(t, []string{"ESM24", "ESM24", "AAPL", "SPY   240621C00500000"}, symbols)
+	assert.Equal(t, "170.05", executions[2].Price.String())
+
+	// Importing the log again changes nothing.
+	report, err = Import(s, 1, account, models.SourceFIX, records)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, report.Created)
+	assert.Equal(t, 5, report.Duplicates)
+	assert.Equal(t, 0, report.Busted)
+	assert.Equal(t, "no imported fill OLD1 to bust", report.Rows[7].Message)
+
+	// A correction in a later log updates the fill in place.
+	corrections, err := ParseFIX(readFixture(t, "dropcopy_corrections.log"), time.UTC)
+	assert.NoError(t, err)
+	report, err = Import(s, 1, account, models.SourceFIX, corrections)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.Corrected)
+	batch, err = s.GetImportBatch(1, report.BatchID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, batch.Corrected)
+	corrected, err := s.GetExecution(1, report.Rows[0].ExecutionID)
+	assert.NoError(t, err)
+	assert.Equal(t, "5100", corrected.Price.String())
+	assert.Equal(t, executions[0].ID, corrected.ID)
+
+	report, err = Import(s, 1, account, models.SourceFIX, corrections)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.Duplicates)
+}
@@ -0,0 +1,201 @@
+package imports
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/drewbuiltit/trading-journal/backend/internal/auth"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxFileSize bounds an uploaded statement.
+const maxFileSize = 32 << 20
+
+type ImportHandler struct {
+	Store store.Store
+}
+
+// ImportIBKR imports an Interactive Brokers Flex Query from the request body
+// into the account given by ?account=. ?timezone= names the zone the query
+// reports times in and defaults to UTC.
+func (h *ImportHandler) ImportIBKR(w http.ResponseWriter, r *http.Request) {
+	h.importFile(w, r, models.SourceIBKR, ParseIBKR)
+}
+
+// ImportOFX imports an OFX or QFX investment statement from the request body
+// into the account given by ?account=. ?timezone= is the zone for times the
+// file gives without one and defaults to UTC.
+func (h *ImportHandler) ImportOFX(w http.ResponseWriter, r *http.Request) {
+	h.importFile(w, r, models.SourceOFX, ParseOFX)
+}
+
+// ImportFIX imports the fills in a FIX 4.2 or 4.4 log from the request body
+// into the account given by ?account=. FIX times are UTC, so ?timezone= is
+// not used.
+func (h *ImportHandler) ImportFIX(w http.ResponseWriter, r *http.Request) {
+	h.importFile(w, r, models.SourceFIX, ParseFIX)
+}
+
+// ImportNinjaTrader imports a NinjaTrader executions export; see
+// ImportIBKR for the parameters.
+func (h *ImportHandler) ImportNinjaTrader(w http.ResponseWriter, r *http.Request) {
+	h.importFile(w, r, models.SourceNinjaTrader, ParseNinjaTrader)
+}
+
+// ImportTradovate imports a Tradovate fills export; see ImportIBKR for the
+// parameters.
+func (h *ImportHandler) ImportTradovate(w http.ResponseWriter, r *http.Request) {
+	h.importFile(w, r, models.SourceTradovate, ParseTradovate)
+}
+
+// ImportTradeStation imports a TradeStation trade history export; see
+// ImportIBKR for the parameters.
+func (h *ImportHandler) ImportTradeStation(w http.ResponseWriter, r *http.Request) {
+	h.importFile(w, r, models.SourceTradeStation, ParseTradeStation)
+}
+
+// importFile reads the file in the request body with parse and imports it
+// into the account given by ?account=, reading times without a zone in
+// ?timezone=.
+func (h *ImportHandler) importFile(w http.ResponseWriter, r *http.Request, source string, parse func([]byte, *time.Location) ([]Record, error)) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	account, ok := h.loadAccount(w, r, userID)
+	if !ok {
+		return
+	}
+	loc, err := time.LoadLocation(r.URL.Query().Get("timezone"))
+	if err != nil {
+		http.Error(w, "timezone must be an IANA time zone name", http.StatusBadRequest)
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxFileSize))
+	if err != nil {
+		http.Error(w, "Error reading file", http.StatusBadRequest)
+		return
+	}
+	records, err := parse(data, loc)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.save(w, userID, account, source, records)
+}
+
+// PreviewCSV returns the columns and first rows of the CSV in the request
+// body, with a suggested template to start the mapping from.
+func (h *ImportHandler) PreviewCSV(w http.ResponseWriter, r *http.Request) {
+	if _, ok := auth.UserIDFromContext(r.Context()); !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxFileSize))
+	if err != nil {
+		http.Error(w, "Error reading file", http.StatusBadRequest)
+		return
+	}
+	preview, err := PreviewCSV(data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(preview)
+}
+
+// ImportCSV imports the CSV in the request body into the account given by
+// ?account=, reading it with the saved template given by ?template=.
+// ?timezone= is the zone of the file's times and defaults to UTC.
+func (h *ImportHandler) ImportCSV(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	account, ok := h.loadAccount(w, r, userID)
+	if !ok {
+		return
+	}
+	templateID, err := strconv.Atoi(r.URL.Query().Get("template"))
+	if err != nil {
+		http.Error(w, "Invalid template ID", http.StatusBadRequest)
+		return
+	}
+	template, err := h.Store.GetCSVTemplate(userID, templateID)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "Template not found", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error fetching template", http.StatusInternalServerError)
+		return
+	}
+	loc, err := time.LoadLocation(r.URL.Query().Get("timezone"))
+	if err != nil {
+		http.Error(w, "timezone must be an IANA time zone name", http.StatusBadRequest)
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxFileSize))
+	if err != nil {
+		http.Error(w, "Error reading file", http.StatusBadRequest)
+		return
+	}
+	records, err := ParseCSV(data, template, loc)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.save(w, userID, account, models.SourceCSV, records)
+}
+
+func (h *ImportHandler) save(w http.ResponseWriter, userID int, account *models.Account, source string, records []Record) {
+	report, err := Import(h.Store, userID, account, source, records)
+	if err != nil {
+		http.Error(w, "Error importing file", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// loadAccount fetches the account named by ?account=, writing the error
+// response when it is missing or not the user's.
+func (h *ImportHandler) loadAccount(w http.ResponseWriter, r *http.Request, userID int) (*models.Account, bool) {
+	value := r.URL.Query().Get("account")
+	if value == "" {
+		http.Error(w, "account is required", http.StatusBadRequest)
+		return nil, false
+	}
+	accountID, err := strconv.Atoi(value)
+	if err != nil {
+		http.Error(w, "Invalid account ID", http.StatusBadRequest)
+		return nil, false
+	}
+
+	account, err := h.Store.GetAccount(userID, accountID)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "Account not found", http.StatusBadRequest)
+		return nil, false
+	}
+	if err != nil {
+		http.Error(w, "Error fetching account", http.StatusInternalServerError)
+		return nil, false
+	}
+	return account, true
+}
@@ -0,0 +1,308 @@
+package imports
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/drewbuiltit/trading-journal/backend/internal/auth"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"github.com/gorilla/mux"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func newImportRequest(t *testing.T, url string, body []byte, userID int) *http.Request {
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
+	assert.NoError(t, err)
+	return req.WithContext(context.WithValue(req.Context(), auth.UserContextKey, userID))
+}
+
+func TestImportIBKRHandler(t *testing.T) {
+	importHandler := &ImportHandler{Store: store.NewMemoryStore()}
+	account := &models.Account{UserID: 1, Name: "IBKR", Number: "U1234567", Currency: "USD"}
+	assert.NoError(t, importHandler.Store.CreateAccount(account))
+
+	rr := httptest.NewRecorder()
+	importHandler.ImportIBKR(rr, newImportRequest(t, "/imports/ibkr?account=1&timezone=America/New_York", readFixture(t, "ibkr_trades.xml"), 1))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var report Report
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+	assert.Equal(t, 4, report.Created)
+	assert.Equal(t, 3, report.Skipped)
+	assert.Equal(t, 1, report.Failed)
+	assert.Len(t, report.Rows, 8)
+	assert.Equal(t, StatusCreated, report.Rows[0].Status)
+	assert.NotZero(t, report.Rows[0].ExecutionID)
+	assert.Equal(t, "ESM24", report.Rows[4].Symbol)
+
+	executions, err := importHandler.Store.ListExecutions(1)
+	assert.NoError(t, err)
+	assert.Len(t, executions, 4)
+	for _, execution := range executions {
+		assert.Equal(t, account.ID, *execution.AccountID)
+		assert.Equal(t, "USD", execution.Currency)
+	}
+}
+
+func TestImportIBKRHandler_OtherAccount(t *testing.T) {
+	importHandler := &ImportHandler{Store: store.NewMemoryStore()}
+	assert.NoError(t, importHandler.Store.CreateAccount(&models.Account{UserID: 1, Name: "Other", Number: "U7654321"}))
+
+	rr := httptest.NewRecorder()
+	importHandler.ImportIBKR(rr, newImportRequest(t, "/imports/ibkr?account=1", readFixture(t, "ibkr_trades.csv"), 1))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var report Report
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+	assert.Equal(t, 0, report.Created)
+	assert.Equal(t, 3, report.Failed)
+	assert.Equal(t, "row belongs to account U1234567", report.Rows[0].Message)
+}
+
+func TestImportIBKRHandler_BadRequests(t *testing.T) {
+	importHandler := &ImportHandler{Store: store.NewMemoryStore()}
+	assert.NoError(t, importHandler.Store.CreateAccount(&models.Account{UserID: 1, Name: "IBKR"}))
+
+	tests := map[string]string{
+		"/imports/ibkr":                         "account is required\n",
+		"/imports/ibkr?account=abc":             "Invalid account ID\n",
+		"/imports/ibkr?account=1":               "file is empty\n",
+		"/imports/ibkr?account=1&timezone=Mars": "timezone must be an IANA time zone name\n",
+	}
+	for url, message := range tests {
+		rr := httptest.NewRecorder()
+		importHandler.ImportIBKR(rr, newImportRequest(t, url, nil, 1))
+		assert.Equal(t, http.StatusBadRequest, rr.Code, url)
+		assert.Equal(t, message, rr.Body.String(), url)
+	}
+
+	rr := httptest.NewRecorder()
+	importHandler.ImportIBKR(rr, newImportRequest(t, "/imports/ibkr?account=1", readFixture(t, "ibkr_trades.xml"), 2))
+	assert.Equal(t, "Account not found\n", rr.Body.String())
+}
+
+func TestTemplateHandlers(t *testing.T) {
+	importHandler := &ImportHandler{Store: store.NewMemoryStore()}
+
+	body, _ := json.Marshal(brokerTemplate)
+	rr := httptest.NewRecorder()
+	importHandler.CreateTemplate(rr, newImportRequest(t, "/imports/csv/templates", body, 1))
+	assert.Equal(t, http.StatusCreated, rr.Code)
+
+	var template models.CSVTemplate
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &template))
+	assert.Equal(t, 1, template.ID)
+	assert.Equal(t, "Ticker", template.SymbolColumn)
+
+	rr = httptest.NewRecorder()
+	importHandler.CreateTemplate(rr, newImportRequest(t, "/imports/csv/templates", []byte(`{"name":"Empty"}`), 1))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, "symbol, quantity, price and date columns are required\n", rr.Body.String())
+
+	req := mux.SetURLVars(newImportRequest(t, "/imports/csv/templates/1", []byte(`{"name":"Renamed","symbol_column":"Ticker","quantity_column":"Qty","price_column":"Fill Price","date_column":"Date","date_format":"MM/DD/YYYY"}`), 1), map[string]string{"id": "1"})
+	rr = httptest.NewRecorder()
+	importHandler.UpdateTemplate(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	rr = httptest.NewRecorder()
+	importHandler.ListTemplates(rr, newImportRequest(t, "/imports/csv/templates", nil, 1))
+	var templates []models.CSVTemplate
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &templates))
+	assert.Len(t, templates, 1)
+	assert.Equal(t, "Renamed", templates[0].Name)
+	assert.Empty(t, templates[0].SideColumn)
+
+	rr = httptest.NewRecorder()
+	importHandler.GetTemplate(rr, mux.SetURLVars(newImportRequest(t, "/imports/csv/templates/1", nil, 2), map[string]string{"id": "1"}))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	rr = httptest.NewRecorder()
+	importHandler.DeleteTemplate(rr, mux.SetURLVars(newImportRequest(t, "/imports/csv/templates/1", nil, 1), map[string]string{"id": "1"}))
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+}
+
+func TestImportCSVHandler(t *testing.T) {
+	importHandler := &ImportHandler{Store: store.NewMemoryStore()}
+	assert.NoError(t, importHandler.Store.CreateAccount(&models.Account{UserID: 1, Name: "Broker", Currency: "USD"}))
+	template := brokerTemplate
+	template.UserID = 1
+	assert.NoError(t, importHandler.Store.CreateCSVTemplate(&template))
+
+	rr := httptest.NewRecorder()
+	importHandler.PreviewCSV(rr, newImportRequest(t, "/imports/csv/preview", readFixture(t, "broker.csv"), 1))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	rr = httptest.NewRecorder()
+	importHandler.ImportCSV(rr, newImportRequest(t, "/imports/csv?account=1&template=1&timezone=America/New_York", readFixture(t, "broker.csv"), 1))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var report Report
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+	assert.Equal(t, 4, report.Created)
+	assert.Equal(t, 3, report.Failed)
+
+	executions, err := importHandler.Store.ListExecutions(1)
+	assert.NoError(t, err)
+	assert.Len(t, executions, 4)
+
+	rr = httptest.NewRecorder()
+	importHandler.ImportCSV(rr, newImportRequest(t, "/imports/csv?account=1&template=1", readFixture(t, "broker.csv"), 2))
+	assert.Equal(t, "Account not found\n", rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	importHandler.ImportCSV(rr, newImportRequest(t, "/imports/csv?account=1&template=9", readFixture(t, "broker.csv"), 1))
+	assert.Equal(t, "Template not found\n", rr.Body.String())
+}
+
+func TestBatchHandlers(t *testing.T) {
+	importHandler := &ImportHandler{Store: store.NewMemoryStore()}
+	assert.NoError(t, importHandler.Store.CreateAccount(&models.Account{UserID: 1, Name: "IBKR", Number: "U1234567"}))
+
+	for i := 0; i < 2; i++ {
+		rr := httptest.NewRecorder()
+		importHandler.ImportIBKR(rr, newImportRequest(t, "/imports/ibkr?account=1", readFixture(t, "ibkr_trades.xml"), 1))
+		assert.Equal(t, http.StatusOK, rr.Code)
+	}
+
+	rr := httptest.NewRecorder()
+	importHandler.ListBatches(rr, newImportRequest(t, "/imports", nil, 1))
+	var batches []models.ImportBatch
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &batches))
+	assert.Len(t, batches, 2)
+	assert.Equal(t, 2, batches[0].ID)
+	assert.Equal(t, models.SourceIBKR, batches[0].Source)
+	assert.Equal(t, 0, batches[0].Created)
+	assert.Equal(t, 4, batches[0].Duplicates)
+	assert.Equal(t, 4, batches[1].Created)
+
+	rr = httptest.NewRecorder()
+	importHandler.GetBatch(rr, mux.SetURLVars(newImportRequest(t, "/imports/1", nil, 2), map[string]string{"id": "1"}))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	rr = httptest.NewRecorder()
+	importHandler.DeleteBatch(rr, mux.SetURLVars(newImportRequest(t, "/imports/1", nil, 1), map[string]string{"id": "1"}))
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+
+	executions, err := importHandler.Store.ListExecutions(1)
+	assert.NoError(t, err)
+	assert.Empty(t, executions)
+
+	rr = httptest.NewRecorder()
+	importHandler.DeleteBatch(rr, mux.SetURLVars(newImportRequest(t, "/imports/1", nil, 1), map[string]string{"id": "1"}))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestDeleteBatch_Amendments(t *testing.T) {
+	importHandler := &ImportHandler{Store: store.NewMemoryStore()}
+	first, err := Import(importHandler.Store, 1, nil, models.SourceFIX, []Record{fillRecord(1, "E1"), fillRecord(2, "E2")})
+	assert.NoError(t, err)
+
+	bust := fillRecord(1, "E1")
+	bust.Bust = true
+	correction := fillRecord(2, "E2")
+	correction.Correction = true
+	correction.Execution.Price = decimal.NewFromInt(171)
+	for _, record := range []Record{bust, correction} {
+		report, err := Import(importHandler.Store, 1, nil, models.SourceFIX, []Record{record})
+		assert.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		importHandler.DeleteBatch(rr, mux.SetURLVars(newImportRequest(t, "/imports", nil, 1), map[string]string{"id": strconv.Itoa(report.BatchID)}))
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		_, err = importHandler.Store.GetImportBatch(1, report.BatchID)
+		assert.NoError(t, err, "the batch is kept")
+	}
+
+	executions, err := importHandler.Store.ListExecutions(1)
+	assert.NoError(t, err)
+	assert.Len(t, executions, 1)
+	assert.Equal(t, "171", executions[0].Price.String())
+
+	rr := httptest.NewRecorder()
+	importHandler.DeleteBatch(rr, mux.SetURLVars(newImportRequest(t, "/imports", nil, 1), map[string]string{"id": strconv.Itoa(first.BatchID)}))
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+}
+
+func TestImportOFXHandler(t *testing.T) {
+	importHandler := &ImportHandler{Store: store.NewMemoryStore()}
+	assert.NoError(t, importHandler.Store.CreateAccount(&models.Account{UserID: 1, Name: "Brokerage", Number: "X12345678"}))
+
+	rr := httptest.NewRecorder()
+	importHandler.ImportOFX(rr, newImportRequest(t, "/imports/ofx?account=1", readFixture(t, "statement.ofx"), 1))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var report Report
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+	assert.Equal(t, 9, report.Created)
+	assert.Equal(t, 1, report.Skipped)
+	assert.Equal(t, 1, report.Failed)
+	assert.NotZero(t, report.Rows[3].CashTransactionID)
+
+	executions, err := importHandler.Store.ListExecutions(1)
+	assert.NoError(t, err)
+	assert.Len(t, executions, 4)
+	transactions, err := importHandler.Store.ListCashTransactions(1)
+	assert.NoError(t, err)
+	assert.Len(t, transactions, 5)
+	assert.Equal(t, models.CashDeposit, transactions[0].Type)
+	assert.Equal(t, 1, *transactions[0].AccountID)
+
+	rr = httptest.NewRecorder()
+	importHandler.ImportOFX(rr, newImportRequest(t, "/imports/ofx?account=1", readFixture(t, "statement.ofx"), 1))
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+	assert.Equal(t, 0, report.Created)
+	assert.Equal(t, 9, report.Duplicates)
+
+	assert.NoError(t, importHandler.Store.DeleteImportBatch(1, 1))
+	transactions, err = importHandler.Store.ListCashTransactions(1)
+	assert.NoError(t, err)
+	assert.Empty(t, transactions)
+
+	rr = httptest.NewRecorder()
+	importHandler.ImportOFX(rr, newImportRequest(t, "/imports/ofx?account=1", readFixture(t, "ibkr_trades.csv"), 1))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, "file is not an OFX statement\n", rr.Body.String())
+}
+
+func TestImportPlatformHandlers(t *testing.T) {
+	importHandler := &ImportHandler{Store: store.NewMemoryStore()}
+	assert.NoError(t, importHandler.Store.CreateAccount(&models.Account{UserID: 1, Name: "Futures", Type: models.AccountFutures}))
+
+	tests := []struct {
+		handler func(http.ResponseWriter, *http.Request)
+		fixture string
+		created int
+	}{
+		{importHandler.ImportNinjaTrader, "ninjatrader.csv", 3},
+		{importHandler.ImportTradovate, "tradovate.csv", 2},
+		{importHandler.ImportTradeStation, "tradestation.csv", 3},
+		{importHandler.ImportFIX, "dropcopy.log", 4},
+	}
+	for _, test := range tests {
+		rr := httptest.NewRecorder()
+		test.handler(rr, newImportRequest(t, "/imports?account=1", readFixture(t, test.fixture), 1))
+		assert.Equal(t, http.StatusOK, rr.Code, test.fixture)
+
+		var report Report
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+		assert.Equal(t, test.created, report.Created, test.fixture)
+	}
+
+	executions, err := importHandler.Store.ListExecutions(1)
+	assert.NoError(t, err)
+	for _, execution := range executions {
+		if execution.Symbol == "MESM24" {
+			assert.Equal(t, "5", execution.Multiplier.String(), "futures take the point value from the specs")
+		}
+	}
+
+	batches, err := importHandler.Store.ListImportBatches(1)
+	assert.NoError(t, err)
+	assert.Equal(t, models.SourceFIX, batches[0].Source)
+}
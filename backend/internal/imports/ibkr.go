@@ -0,0 +1,372 @@
+package imports
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"github.com/drewbuiltit/trading-journal/backend/internal/instruments"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/shopspring/decimal"
+	"io"
+	"strings"
+	"time"
+)
+
+// ibkrAliases maps Flex CSV column names onto the XML attribute names, both
+// lowercased with punctuation removed.
+var ibkrAliases = map[string]string{
+	"clientaccountid": "accountid",
+	"assetclass":      "assetcategory",
+	"currencyprimary": "currency",
+}
+
+// ibkrMarkers are the begin and end records IBKR writes around files,
+// accounts and sections in Flex CSV.
+var ibkrMarkers = map[string]bool{"BOF": true, "BOA": true, "BOS": true, "EOS": true, "EOA": true, "EOF": true}
+
+var ibkrTimeLayouts = []string{
+	"20060102;150405",
+	"2006-01-02;15:04:05",
+	"20060102 150405",
+	"2006-01-02 15:04:05",
+	"2006-01-02, 15:04:05",
+	"20060102",
+	"2006-01-02",
+}
+
+type ibkrRow struct {
+	line   int
+	fields map[string]string
+}
+
+func (r ibkrRow) get(name string) string {
+	return strings.TrimSpace(r.fields[name])
+}
+
+// ParseIBKR reads the trades of an Interactive Brokers Flex Query, exported
+// as either XML or CSV. Times without a zone are read in loc, which should be
+// the zone the query was configured with. Only execution-level trade rows are
+// imported: order and lot summaries would count fills twice, forex
+// conversions are cash movements and cancelled trades never happened.
+func ParseIBKR(data []byte, loc *time.Location) ([]Record, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, errors.New("file is empty")
+	}
+
+	var rows []ibkrRow
+	var err error
+	if trimmed[0] == '<' {
+		rows, err = ibkrXMLRows(data)
+	} else {
+		rows, err = ibkrCSVRows(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("file has no trades")
+	}
+
+	// Queries set to order level carry no execution rows; only then are the
+	// order rows the fills.
+	hasExecutions := false
+	for _, row := range rows {
+		if strings.EqualFold(row.get("levelofdetail"), "EXECUTION") {
+			hasExecutions = true
+		}
+	}
+
+	records := make([]Record, 0, len(rows))
+	for _, row := range rows {
+		records = append(records, ibkrRecord(row, hasExecutions, loc))
+	}
+	return records, nil
+}
+
+func ibkrXMLRows(data []byte) ([]ibkrRow, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	rows := []ibkrRow{}
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid XML: %v", err)
+		}
+		element, ok := token.(xml.StartElement)
+		if !ok || (element.Name.Local != "Trade" && element.Name.Local != "TradeConfirm") {
+			continue
+		}
+		line, _ := decoder.InputPos()
+		row := ibkrRow{line: line, fields: make(map[string]string)}
+		for _, attr := range element.Attr {
+			row.fields[ibkrKey(attr.Name.Local)] = attr.Value
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func ibkrCSVRows(data []byte) ([]ibkrRow, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	rows := []ibkrRow{}
+	var header []string
+	skipSection := false
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %v", err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		if ibkrMarkers[strings.TrimSpace(record[0])] {
+			if strings.TrimSpace(record[0]) == "BOS" {
+				header, skipSection = nil, false
+			}
+			continue
+		}
+		if skipSection {
+			continue
+		}
+		if header == nil || ibkrHeader(record, header) {
+			header = make([]string, len(record))
+			for i, name := range record {
+				header[i] = ibkrKey(name)
+			}
+			// Sections other than trades, e.g. cash transactions, are left
+			// out rather than reported row by row.
+			skipSection = !containsAll(header, "symbol", "quantity", "tradeprice")
+			continue
+		}
+
+		row := ibkrRow{line: line, fields: make(map[string]string)}
+		for i, value := range record {
+			if i < len(header) {
+				row.fields[header[i]] = value
+			}
+		}
+		rows = append(rows, row)
+	}
+	if header == nil {
+		return nil, errors.New("file has no header row")
+	}
+	return rows, nil
+}
+
+// ibkrHeader reports whether record repeats the current header, as Flex CSV
+// does at the start of each account.
+func ibkrHeader(record, header []string) bool {
+	if len(record) != len(header) {
+		return false
+	}
+	for i, name := range record {
+		if ibkrKey(name) != header[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func ibkrKey(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	if alias, ok := ibkrAliases[b.String()]; ok {
+		return alias
+	}
+	return b.String()
+}
+
+func containsAll(values []string, wanted ...string) bool {
+	for _, want := range wanted {
+		found := false
+		for _, value := range values {
+			if value == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func ibkrRecord(row ibkrRow, hasExecutions bool, loc *time.Location) Record {
+	record := Record{Line: row.line, Account: row.get("accountid"), ExecID: row.get("ibexecid")}
+	record.Execution.Symbol = row.get("symbol")
+	// IB's commission takes in the exchange and regulatory fees.
+	_, record.HasCommission = row.fields["ibcommission"]
+	_, hasTaxes := row.fields["taxes"]
+	record.HasFees = record.HasCommission || hasTaxes
+
+	detail := strings.ToUpper(row.get("levelofdetail"))
+	if detail != "" && detail != "EXECUTION" && (detail != "ORDER" || hasExecutions) {
+		record.Skip = "not an execution row (" + strings.ToLower(detail) + ")"
+		return record
+	}
+	if strings.EqualFold(row.get("assetcategory"), "CASH") {
+		record.Skip = "forex conversions are not imported"
+		return record
+	}
+	buySell := strings.ToUpper(row.get("buysell"))
+	if strings.Contains(buySell, "(CA") {
+		record.Skip = "trade was cancelled"
+		return record
+	}
+
+	execution, err := ibkrExecution(row, buySell, loc)
+	if err != nil {
+		record.Err = err
+		return record
+	}
+	record.Execution = execution
+	return record
+}
+
+func ibkrExecution(row ibkrRow, buySell string, loc *time.Location) (models.Execution, error) {
+	var execution models.Execution
+
+	execution.Symbol = ibkrSymbol(row)
+	if execution.Symbol == "" {
+		return execution, errors.New("symbol is required")
+	}
+
+	quantity, err := ibkrDecimal(row.get("quantity"))
+	if err != nil || quantity.IsZero() {
+		return execution, errors.New("quantity must be a non-zero number")
+	}
+	execution.Quantity = quantity.Abs()
+
+	switch {
+	case strings.HasPrefix(buySell, "BUY"):
+		execution.Side = models.SideBuy
+	case strings.HasPrefix(buySell, "SELL"):
+		execution.Side = models.SideSell
+	case buySell == "" && quantity.IsNegative():
+		execution.Side = models.SideSell
+	case buySell == "":
+		execution.Side = models.SideBuy
+	default:
+		return execution, errors.New("buy/sell must be BUY or SELL")
+	}
+
+	// O and C say whether the fill opened or closed; a fill that flips the
+	// position (C;O) is left to the lot book.
+	switch strings.ToUpper(row.get("opencloseindicator")) {
+	case "O":
+		execution.Action = models.ActionBuy
+		if execution.Side == models.SideSell {
+			execution.Action = models.ActionSellShort
+		}
+	case "C":
+		execution.Action = models.ActionSell
+		if execution.Side == models.SideBuy {
+			execution.Action = models.ActionBuyToCover
+		}
+	}
+
+	execution.Price, err = ibkrDecimal(row.get("tradeprice"))
+	if err != nil || execution.Price.IsNegative() {
+		return execution, errors.New("trade price must not be negative")
+	}
+	if execution.Commission, err = ibkrDecimal(row.get("ibcommission")); err != nil {
+		return execution, errors.New("commission must be a number")
+	}
+	execution.Commission = execution.Commission.Abs()
+	if execution.Fees, err = ibkrDecimal(row.get("taxes")); err != nil {
+		return execution, errors.New("taxes must be a number")
+	}
+	execution.Fees = execution.Fees.Abs()
+	if execution.Multiplier, err = ibkrDecimal(row.get("multiplier")); err != nil {
+		return execution, errors.New("multiplier must be a number")
+	}
+
+	execution.Currency = strings.ToUpper(row.get("currency"))
+	if execution.Currency != "" && !instruments.ValidCurrency(execution.Currency) {
+		return execution, errors.New("currency must be a three-letter currency code")
+	}
+
+	execution.ExecutedAt, err = ibkrTime(row, loc)
+	if err != nil {
+		return execution, err
+	}
+	return execution, nil
+}
+
+// ibkrSymbol returns the symbol as the journal spells it. Option rows whose
+// symbol is not in OCC form are rebuilt from the contract columns.
+func ibkrSymbol(row ibkrRow) string {
+	symbol := row.get("symbol")
+	category := strings.ToUpper(row.get("assetcategory"))
+	if category != "OPT" {
+		return symbol
+	}
+	if _, err := instruments.ParseOCC(symbol); err == nil {
+		return symbol
+	}
+
+	expiry, err := ibkrParseTime(row.get("expiry"), time.UTC)
+	strike, strikeErr := decimal.NewFromString(row.get("strike"))
+	underlying := strings.ToUpper(row.get("underlyingsymbol"))
+	if err != nil || strikeErr != nil || underlying == "" {
+		return symbol
+	}
+	right := models.RightCall
+	if strings.HasPrefix(strings.ToUpper(row.get("putcall")), "P") {
+		right = models.RightPut
+	}
+	return instruments.FormatOCC(models.Instrument{Underlying: underlying, Expiry: &expiry, Strike: strike, Right: right})
+}
+
+func ibkrTime(row ibkrRow, loc *time.Location) (time.Time, error) {
+	value := row.get("datetime")
+	if value == "" && row.get("tradedate") != "" {
+		value = strings.TrimSpace(row.get("tradedate") + ";" + row.get("tradetime"))
+		value = strings.TrimSuffix(value, ";")
+	}
+	if value == "" {
+		return time.Time{}, errors.New("trade time is required")
+	}
+	executedAt, err := ibkrParseTime(value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unrecognised trade time %q", value)
+	}
+	return executedAt.UTC(), nil
+}
+
+func ibkrParseTime(value string, loc *time.Location) (time.Time, error) {
+	var err error
+	for _, layout := range ibkrTimeLayouts {
+		var parsed time.Time
+		if parsed, err = time.ParseInLocation(layout, value, loc); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// ibkrDecimal reads an IBKR number, which may carry thousands separators. A
+// blank value is zero.
+func ibkrDecimal(value string) (decimal.Decimal, error) {
+	value = strings.ReplaceAll(value, ",", "")
+	if value == "" {
+		return decimal.Zero, nil
+	}
+	return decimal.NewFromString(value)
+}
@@ -0,0 +1,85 @@
+package imports
+
+import (
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+	"time"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	data, err := os.ReadFile("testdata/" + name)
+	assert.NoError(t, err)
+	return data
+}
+
+func TestParseIBKR_XML(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	assert.NoError(t, err)
+
+	records, err := ParseIBKR(readFixture(t, "ibkr_trades.xml"), newYork)
+	assert.NoError(t, err)
+	assert.Len(t, records, 8)
+
+	buy := records[0]
+	assert.Equal(t, 5, buy.Line)
+	assert.Equal(t, "U1234567", buy.Account)
+	assert.Equal(t, models.SideBuy, buy.Execution.Side)
+	assert.Equal(t, models.ActionBuy, buy.Execution.Action)
+	assert.Equal(t, "100", buy.Execution.Quantity.String())
+	assert.Equal(t, "170.25", buy.Execution.Price.String())
+	assert.Equal(t, "1", buy.Execution.Commission.String())
+	assert.True(t, buy.HasCommission && buy.HasFees, "the fee schedule is not applied over IB's costs")
+	assert.Equal(t, time.Date(2024, 5, 1, 13, 35, 12, 0, time.UTC), buy.Execution.ExecutedAt)
+
+	assert.Equal(t, "not an execution row (order)", records[1].Skip)
+
+	sell := records[2]
+	assert.Equal(t, models.SideSell, sell.Execution.Side)
+	assert.Equal(t, models.ActionSell, sell.Execution.Action)
+	assert.Equal(t, "100", sell.Execution.Quantity.String())
+	assert.Equal(t, "1.02", sell.Execution.Commission.String())
+	assert.Equal(t, "0.03", sell.Execution.Fees.String())
+
+	option := records[3]
+	assert.Equal(t, models.ActionSellShort, option.Execution.Action)
+	assert.Equal(t, "100", option.Execution.Multiplier.String())
+
+	future := records[4]
+	assert.Equal(t, "ESM4", future.Execution.Symbol)
+	assert.Equal(t, "50", future.Execution.Multiplier.String())
+
+	assert.Equal(t, "forex conversions are not imported", records[5].Skip)
+	assert.Equal(t, "trade was cancelled", records[6].Skip)
+	assert.EqualError(t, records[7].Err, `unrecognised trade time "yesterday"`)
+}
+
+func TestParseIBKR_CSV(t *testing.T) {
+	records, err := ParseIBKR(readFixture(t, "ibkr_trades.csv"), time.UTC)
+	assert.NoError(t, err)
+	assert.Len(t, records, 3, "the cash transactions section is left out")
+
+	vodafone := records[0]
+	assert.Equal(t, 5, vodafone.Line)
+	assert.Equal(t, "VOD", vodafone.Execution.Symbol)
+	assert.Equal(t, "GBP", vodafone.Execution.Currency)
+	assert.Equal(t, "1500", vodafone.Execution.Quantity.String())
+	assert.Equal(t, "5.25", vodafone.Execution.Fees.String())
+	assert.Equal(t, time.Date(2024, 5, 1, 8, 15, 0, 0, time.UTC), vodafone.Execution.ExecutedAt)
+
+	assert.Equal(t, "QQQ  240517C00440000", records[1].Execution.Symbol)
+	assert.Equal(t, "QQQ   240517C00440000", records[2].Execution.Symbol, "non-OCC option symbols are rebuilt from the contract")
+	assert.Equal(t, models.SideSell, records[2].Execution.Side)
+}
+
+func TestParseIBKR_Rejects(t *testing.T) {
+	_, err := ParseIBKR([]byte("  \n"), time.UTC)
+	assert.EqualError(t, err, "file is empty")
+
+	_, err = ParseIBKR([]byte("<FlexQueryResponse><Trades></Trades></FlexQueryResponse>"), time.UTC)
+	assert.EqualError(t, err, "file has no trades")
+
+	_, err = ParseIBKR([]byte("<FlexQueryResponse><Trades>"), time.UTC)
+	assert.Error(t, err)
+}
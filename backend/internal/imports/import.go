@@ -0,0 +1,365 @@
+package imports
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/drewbuiltit/trading-journal/backend/internal/fees"
+	"github.com/drewbuiltit/trading-journal/backend/internal/instruments"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/drewbuiltit/trading-journal/backend/internal/positions"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"strings"
+	"time"
+)
+
+const (
+	StatusCreated   = "created"
+	StatusDuplicate = "duplicate"
+	StatusSkipped   = "skipped"
+	StatusFailed    = "failed"
+	StatusBusted    = "busted"
+	StatusCorrected = "corrected"
+)
+
+// Record is one row of a broker file after parsing. A row that is not a fill
+// carries a Skip reason and a row that could not be read carries Err; either
+// way it is reported rather than imported. A row that moved cash rather than
+// filling an order carries Cash instead of an Execution. ExecID is the
+// broker's transaction ID when the file has one. A Bust row cancels the fill
+// ExecID names and a Correction row replaces it with Execution, whether that
+// fill came in this file or an earlier one. HasCommission and HasFees say
+// whether the file carried those costs; the ones it did not are filled in
+// from the account's fee schedule.
+type Record struct {
+	Line          int
+	Account       string
+	ExecID        string
+	Execution     models.Execution
+	HasCommission bool
+	HasFees       bool
+	Cash          *models.CashTransaction
+	Bust          bool
+	Correction    bool
+	Skip          string
+	Err           error
+}
+
+type RowResult struct {
+	Line              int    `json:"line"`
+	Status            string `json:"status"`
+	Symbol            string `json:"symbol,omitempty"`
+	ExecutionID       int    `json:"execution_id,omitempty"`
+	CashTransactionID int    `json:"cash_transaction_id,omitempty"`
+	Message           string `json:"message,omitempty"`
+}
+
+type Report struct {
+	BatchID    int         `json:"batch_id"`
+	Created    int         `json:"created"`
+	Duplicates int         `json:"duplicates"`
+	Skipped    int         `json:"skipped"`
+	Failed     int         `json:"failed"`
+	Busted     int         `json:"busted"`
+	Corrected  int         `json:"corrected"`
+	Rows       []RowResult `json:"rows"`
+}
+
+func (r *Report) add(row RowResult) {
+	switch row.Status {
+	case StatusCreated:
+		r.Created++
+	case StatusDuplicate:
+		r.Duplicates++
+	case StatusSkipped:
+		r.Skipped++
+	case StatusFailed:
+		r.Failed++
+	case StatusBusted:
+		r.Busted++
+	case StatusCorrected:
+		r.Corrected++
+	}
+	r.Rows = append(r.Rows, row)
+}
+
+// Import saves the records as executions and cash transactions in the account
+// under a new import batch, reporting the outcome of every row. Rows naming a
+// different broker account number than the chosen account are refused, as
+// are sells and buys to cover with nothing to close, and fills already
+// imported are reported as duplicates. Costs the file lacked
+// are filled in from the account's fee schedule. Busts and corrections
+// change fills that may belong to earlier batches, so a batch with any
+// cannot be rolled back. If the store fails the batch is rolled back and the
+// error returned.
+func Import(s store.Store, userID int, account *models.Account, source string, records []Record) (*Report, error) {
+	batch := &models.ImportBatch{UserID: userID, Source: source}
+	if account != nil {
+		accountID := account.ID
+		batch.AccountID = &accountID
+	}
+	schedule, err := fees.ScheduleFor(s, userID, batch.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.CreateImportBatch(batch); err != nil {
+		return nil, err
+	}
+
+	report := &Report{BatchID: batch.ID, Rows: []RowResult{}}
+	occurrences := make(map[string]int)
+	for _, record := range records {
+		row := RowResult{Line: record.Line, Symbol: record.Execution.Symbol}
+		switch {
+		case record.Err != nil:
+			row.Status = StatusFailed
+			row.Message = record.Err.Error()
+		case record.Skip != "":
+			row.Status = StatusSkipped
+			row.Message = record.Skip
+		case account != nil && account.Number != "" && record.Account != "" && !strings.EqualFold(account.Number, record.Account):
+			row.Status = StatusFailed
+			row.Message = "row belongs to account " + record.Account
+		case record.Cash != nil:
+			transaction := *record.Cash
+			prepareCash(&transaction, userID, account)
+			transaction.ImportBatchID = &batch.ID
+			transaction.Fingerprint = Fingerprint(number(cashKey(transaction, record.ExecID), record.ExecID, occurrences))
+
+			row.Symbol = transaction.Symbol
+			err := s.CreateCashTransaction(&transaction)
+			if errors.Is(err, store.ErrDuplicate) {
+				row.Status = StatusDuplicate
+				row.Message = "already imported"
+				break
+			}
+			if err != nil {
+				s.DeleteImportBatch(userID, batch.ID)
+				return nil, err
+			}
+			row.Status = StatusCreated
+			row.CashTransactionID = transaction.ID
+		case record.Bust, record.Correction:
+			var err error
+			if row, err = amend(s, userID, account, schedule, batch.ID, record); err != nil {
+				s.DeleteImportBatch(userID, batch.ID)
+				return nil, err
+			}
+		default:
+			execution := record.Execution
+			prepare(&execution, userID, account)
+			fees.Apply(schedule, &execution, record.HasCommission, record.HasFees)
+			execution.ImportBatchID = &batch.ID
+			execution.Fingerprint = Fingerprint(number(fingerprintKey(execution, record.ExecID), record.ExecID, occurrences))
+
+			row.Symbol = execution.Symbol
+			err := positions.CheckClose(s, userID, execution)
+			if errors.Is(err, positions.ErrOverclose) {
+				row.Status = StatusFailed
+				row.Message = err.Error()
+				break
+			}
+			if err == nil {
+				err = s.CreateExecution(&execution)
+			}
+			if errors.Is(err, store.ErrDuplicate) {
+				row.Status = StatusDuplicate
+				row.Message = "already imported"
+				break
+			}
+			if err != nil {
+				s.DeleteImportBatch(userID, batch.ID)
+				return nil, err
+			}
+			row.Status = StatusCreated
+			row.ExecutionID = execution.ID
+		}
+		report.add(row)
+	}
+
+	batch.Created = report.Created
+	batch.Duplicates = report.Duplicates
+	batch.Skipped = report.Skipped
+	batch.Failed = report.Failed
+	batch.Busted = report.Busted
+	batch.Corrected = report.Corrected
+	if err := s.UpdateImportBatch(batch); err != nil {
+		s.DeleteImportBatch(userID, batch.ID)
+		return nil, err
+	}
+	return report, nil
+}
+
+// amend applies a bust or correction to the fill its ExecID names. A bust
+// of a fill that was never imported is skipped; a correction of one is
+// imported as the fill. A correction keeps the spread, plan, strategy and
+// tags recorded on the fill it replaces.
+func amend(s store.Store, userID int, account *models.Account, schedule *models.FeeSchedule, batchID int, record Record) (RowResult, error) {
+	execution := record.Execution
+	prepare(&execution, userID, account)
+	fees.Apply(schedule, &execution, record.HasCommission, record.HasFees)
+	execution.Fingerprint = Fingerprint(fingerprintKey(execution, record.ExecID))
+
+	row := RowResult{Line: record.Line, Symbol: execution.Symbol}
+	existing, err := s.GetExecutionByFingerprint(userID, execution.Fingerprint)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return row, err
+	}
+
+	if record.Bust {
+		if existing == nil {
+			row.Status = StatusSkipped
+			row.Message = "no imported fill " + record.ExecID + " to bust"
+			return row, nil
+		}
+		if err := s.DeleteExecution(userID, existing.ID); err != nil {
+			return row, err
+		}
+		row.Status = StatusBusted
+		row.Symbol = existing.Symbol
+		row.ExecutionID = existing.ID
+		return row, nil
+	}
+
+	if existing == nil {
+		execution.ImportBatchID = &batchID
+		if err := s.CreateExecution(&execution); err != nil {
+			return row, err
+		}
+		row.Status = StatusCreated
+		row.ExecutionID = execution.ID
+		return row, nil
+	}
+
+	row.ExecutionID = existing.ID
+	execution.ID = existing.ID
+	execution.SpreadID = existing.SpreadID
+	execution.Plan = existing.Plan
+	execution.Strategy = existing.Strategy
+	execution.Tags = existing.Tags
+	if sameFill(*existing, execution) {
+		row.Status = StatusDuplicate
+		row.Message = "already imported"
+		return row, nil
+	}
+	if err := s.UpdateExecution(&execution); err != nil {
+		return row, err
+	}
+	row.Status = StatusCorrected
+	return row, nil
+}
+
+func sameFill(a, b models.Execution) bool {
+	return a.Symbol == b.Symbol &&
+		a.Side == b.Side &&
+		a.Action == b.Action &&
+		a.Quantity.Equal(b.Quantity) &&
+		a.Price.Equal(b.Price) &&
+		a.Commission.Equal(b.Commission) &&
+		a.Fees.Equal(b.Fees) &&
+		a.Multiplier.Equal(b.Multiplier) &&
+		a.Currency == b.Currency &&
+		a.ExecutedAt.Equal(b.ExecutedAt)
+}
+
+// fingerprintKey identifies a fill by the broker's execution ID when there is
+// one, and otherwise by what was traded, where and when.
+func fingerprintKey(execution models.Execution, execID string) string {
+	account := accountKey(execution.AccountID)
+	if execID != "" {
+		return strings.Join([]string{"exec", account, execID}, "|")
+	}
+	return strings.Join([]string{
+		"fill",
+		account,
+		execution.Symbol,
+		execution.Side,
+		execution.ExecutedAt.UTC().Format(time.RFC3339Nano),
+		execution.Quantity.String(),
+		execution.Price.String(),
+	}, "|")
+}
+
+// cashKey identifies a cash transaction the way fingerprintKey does a fill.
+func cashKey(transaction models.CashTransaction, transactionID string) string {
+	account := accountKey(transaction.AccountID)
+	if transactionID != "" {
+		return strings.Join([]string{"cash", account, transactionID}, "|")
+	}
+	return strings.Join([]string{
+		"cash",
+		account,
+		transaction.Type,
+		transaction.Symbol,
+		transaction.PostedAt.UTC().Format(time.RFC3339Nano),
+		transaction.Amount.String(),
+	}, "|")
+}
+
+// number tells apart identical rows without a broker ID, such as partial
+// fills in the same second, by their order in the file.
+func number(key, id string, occurrences map[string]int) string {
+	if id != "" {
+		return key
+	}
+	occurrences[key]++
+	if occurrences[key] > 1 {
+		key += fmt.Sprintf("#%d", occurrences[key])
+	}
+	return key
+}
+
+func accountKey(accountID *int) string {
+	if accountID == nil {
+		return "-"
+	}
+	return fmt.Sprint(*accountID)
+}
+
+// Fingerprint hashes a fill's identifying key into the value stored on the
+// execution.
+func Fingerprint(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// prepare fills in what the file left out the same way the executions
+// endpoint does: the canonical symbol, the contract multiplier and the
+// instrument's or account's currency.
+func prepare(execution *models.Execution, userID int, account *models.Account) {
+	execution.ID = 0
+	execution.UserID = userID
+	execution.Symbol = instruments.Normalize(execution.Symbol)
+	if !execution.Multiplier.IsPositive() {
+		execution.Multiplier = instruments.Lookup(execution.Symbol).Multiplier
+	}
+	fallback := ""
+	if account != nil {
+		accountID := account.ID
+		execution.AccountID = &accountID
+		fallback = account.Currency
+	}
+	execution.Currency = strings.ToUpper(execution.Currency)
+	if execution.Currency == "" {
+		execution.Currency = instruments.CurrencyFor(execution.Symbol, fallback)
+	}
+}
+
+// prepareCash fills in the owner, account and, failing the file, the
+// security's or account's currency.
+func prepareCash(transaction *models.CashTransaction, userID int, account *models.Account) {
+	transaction.ID = 0
+	transaction.UserID = userID
+	transaction.Symbol = instruments.Normalize(transaction.Symbol)
+	fallback := ""
+	if account != nil {
+		accountID := account.ID
+		transaction.AccountID = &accountID
+		fallback = account.Currency
+	}
+	transaction.Currency = strings.ToUpper(transaction.Currency)
+	if transaction.Currency == "" {
+		transaction.Currency = instruments.CurrencyFor(transaction.Symbol, fallback)
+	}
+}
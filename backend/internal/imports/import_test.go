@@ -0,0 +1,193 @@
+package imports
+
+import (
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func fillRecord(line int, execID string) Record {
+	return Record{Line: line, ExecID: execID, Execution: models.Execution{
+		Symbol:     "AAPL",
+		Side:       models.SideBuy,
+		Quantity:   decimal.NewFromInt(10),
+		Price:      decimal.NewFromInt(170),
+		ExecutedAt: time.Date(2024, 5, 1, 14, 30, 0, 0, time.UTC),
+	}}
+}
+
+func TestImport_Duplicates(t *testing.T) {
+	s := store.NewMemoryStore()
+	account := &models.Account{UserID: 1, Name: "Broker"}
+	assert.NoError(t, s.CreateAccount(account))
+
+	// Two identical partial fills without IDs are both kept.
+	records := []Record{fillRecord(2, ""), fillRecord(3, ""), fillRecord(4, "E1"), fillRecord(5, "E1")}
+	report, err := Import(s, 1, account, models.SourceCSV, records)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, report.Created)
+	assert.Equal(t, 1, report.Duplicates)
+	assert.Equal(t, StatusDuplicate, report.Rows[3].Status)
+
+	report, err = Import(s, 1, account, models.SourceCSV, records)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, report.Created)
+	assert.Equal(t, 4, report.Duplicates)
+
+	batch, err := s.GetImportBatch(1, report.BatchID)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, batch.Duplicates)
+	assert.Equal(t, account.ID, *batch.AccountID)
+
+	// The same fill in another account or for another user is new.
+	other := &models.Account{UserID: 1, Name: "Other"}
+	assert.NoError(t, s.CreateAccount(other))
+	report, err = Import(s, 1, other, models.SourceCSV, records[:1])
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.Created)
+	report, err = Import(s, 2, nil, models.SourceCSV, records[:1])
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.Created)
+}
+
+func TestImport_RollBack(t *testing.T) {
+	s := store.NewMemoryStore()
+	first, err := Import(s, 1, nil, models.SourceCSV, []Record{fillRecord(2, "E1"), fillRecord(3, "E2")})
+	assert.NoError(t, err)
+	_, err = Import(s, 1, nil, models.SourceCSV, []Record{fillRecord(2, "E3")})
+	assert.NoError(t, err)
+
+	assert.NoError(t, s.DeleteImportBatch(1, first.BatchID))
+	executions, err := s.ListExecutions(1)
+	assert.NoError(t, err)
+	assert.Len(t, executions, 1)
+
+	// Rolled-back fills can be imported again.
+	report, err := Import(s, 1, nil, models.SourceCSV, []Record{fillRecord(2, "E1")})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.Created)
+}
+
+func TestImport_CorrectionKeepsPlan(t *testing.T) {
+	s := store.NewMemoryStore()
+	report, err := Import(s, 1, nil, models.SourceFIX, []Record{fillRecord(1, "E1")})
+	assert.NoError(t, err)
+	fill, err := s.GetExecution(1, report.Rows[0].ExecutionID)
+	assert.NoError(t, err)
+	stop := decimal.NewFromInt(165)
+	fill.StopLoss = &stop
+	assert.NoError(t, s.UpdateExecution(fill))
+
+	correction := fillRecord(1, "E1")
+	correction.Correction = true
+	correction.Execution.Price = decimal.NewFromInt(171)
+	report, err = Import(s, 1, nil, models.SourceFIX, []Record{correction})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.Corrected)
+
+	corrected, err := s.GetExecution(1, fill.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "171", corrected.Price.String())
+	if assert.NotNil(t, corrected.StopLoss) {
+		assert.Equal(t, "165", corrected.StopLoss.String(), "the stop set on the fill survives the correction")
+	}
+}
+
+func TestImport_CorrectionKeepsStrategyAndTags(t *testing.T) {
+	s := store.NewMemoryStore()
+	report, err := Import(s, 1, nil, models.SourceFIX, []Record{fillRecord(1, "E1")})
+	assert.NoError(t, err)
+	fill, err := s.GetExecution(1, report.Rows[0].ExecutionID)
+	assert.NoError(t, err)
+	fill.Strategy = "breakout"
+	fill.Tags = []string{"earnings"}
+	assert.NoError(t, s.UpdateExecution(fill))
+
+	correction := fillRecord(1, "E1")
+	correction.Correction = true
+	correction.Execution.Quantity = decimal.NewFromInt(12)
+	report, err = Import(s, 1, nil, models.SourceFIX, []Record{correction})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.Corrected)
+
+	corrected, err := s.GetExecution(1, fill.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "12", corrected.Quantity.String())
+	assert.Equal(t, "breakout", corrected.Strategy)
+	assert.Equal(t, []string{"earnings"}, corrected.Tags)
+}
+
+func TestImport_ClosingActionNeedsAPosition(t *testing.T) {
+	s := store.NewMemoryStore()
+	sell := fillRecord(3, "E2")
+	sell.Execution.Side = models.SideSell
+	sell.Execution.Action = models.ActionSell
+	sell.Execution.ExecutedAt = sell.Execution.ExecutedAt.Add(time.Hour)
+
+	report, err := Import(s, 1, nil, models.SourceIBKR, []Record{sell})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, "closing fill is more than the position held: 0 AAPL held long", report.Rows[0].Message)
+
+	// With the buy ahead of it in the file the sell closes it, and importing
+	// the file again reports both as duplicates.
+	records := []Record{fillRecord(2, "E1"), sell}
+	for _, created := range []int{2, 0} {
+		report, err = Import(s, 1, nil, models.SourceIBKR, records)
+		assert.NoError(t, err)
+		assert.Equal(t, created, report.Created)
+		assert.Equal(t, 0, report.Failed)
+	}
+}
+
+func TestFingerprintKey(t *testing.T) {
+	accountID := 3
+	execution := fillRecord(2, "").Execution
+	execution.AccountID = &accountID
+
+	assert.Equal(t, "exec|3|E1", fingerprintKey(execution, "E1"))
+	assert.Equal(t, "fill|3|AAPL|buy|2024-05-01T14:30:00Z|10|170", fingerprintKey(execution, ""))
+	assert.Len(t, Fingerprint("exec|3|E1"), 64)
+}
+
+func TestImport_FeeSchedules(t *testing.T) {
+	s := store.NewMemoryStore()
+	cash := &models.Account{UserID: 1, Name: "Cash"}
+	assert.NoError(t, s.CreateAccount(cash))
+	other := &models.Account{UserID: 1, Name: "Other"}
+	assert.NoError(t, s.CreateAccount(other))
+	assert.NoError(t, s.SaveFeeSchedule(&models.FeeSchedule{UserID: 1, CommissionPerShare: decimal.RequireFromString("0.01"), CommissionMinimum: decimal.NewFromInt(1)}))
+	assert.NoError(t, s.SaveFeeSchedule(&models.FeeSchedule{UserID: 1, AccountID: &cash.ID, CommissionPerOrder: decimal.RequireFromString("0.65"), RegulatoryFeeRate: decimal.RequireFromString("0.001")}))
+
+	template := &models.CSVTemplate{SymbolColumn: "Symbol", SideColumn: "Side", QuantityColumn: "Qty", PriceColumn: "Price", DateColumn: "Date", DateFormat: "YYYY-MM-DD"}
+	records, err := ParseCSV([]byte("Symbol,Side,Qty,Price,Date\nAAPL,Buy,200,170,2024-05-01\nAAPL,Sell,200,175,2024-05-02\n"), template, time.UTC)
+	assert.NoError(t, err)
+
+	report, err := Import(s, 1, cash, models.SourceCSV, records)
+	assert.NoError(t, err)
+	sell, err := s.GetExecution(1, report.Rows[1].ExecutionID)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.65", sell.Commission.String(), "the account's schedule is used")
+	assert.Equal(t, "35", sell.Fees.String())
+
+	report, err = Import(s, 1, other, models.SourceCSV, records)
+	assert.NoError(t, err)
+	sell, err = s.GetExecution(1, report.Rows[1].ExecutionID)
+	assert.NoError(t, err)
+	assert.Equal(t, "2", sell.Commission.String(), "an account without a schedule falls back to the default")
+	assert.True(t, sell.Fees.IsZero())
+
+	// Costs the file carries are kept, even when they are zero.
+	template.CommissionColumn = "Comm"
+	records, err = ParseCSV([]byte("Symbol,Side,Qty,Price,Date,Comm\nMSFT,Sell,100,400,2024-05-02,0\n"), template, time.UTC)
+	assert.NoError(t, err)
+	report, err = Import(s, 1, cash, models.SourceCSV, records)
+	assert.NoError(t, err)
+	sell, err = s.GetExecution(1, report.Rows[0].ExecutionID)
+	assert.NoError(t, err)
+	assert.True(t, sell.Commission.IsZero())
+	assert.Equal(t, "40", sell.Fees.String())
+}
@@ -0,0 +1,464 @@
+package imports
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/drewbuiltit/trading-journal/backend/internal/instruments"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/shopspring/decimal"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ofxEntities = strings.NewReplacer("&amp;", "&", "&lt;", "<", "&gt;", ">", "&quot;", `"`, "&apos;", "'", "&nbsp;", " ")
+
+// ofxNode is an OFX element: an aggregate with children or a leaf with a
+// value.
+type ofxNode struct {
+	name     string
+	value    string
+	line     int
+	children []*ofxNode
+}
+
+func (n *ofxNode) child(name string) *ofxNode {
+	if n == nil {
+		return nil
+	}
+	for _, child := range n.children {
+		if child.name == name {
+			return child
+		}
+	}
+	return nil
+}
+
+// text returns the value at the end of a path of child names, or "" when
+// any step is missing.
+func (n *ofxNode) text(path ...string) string {
+	for _, name := range path {
+		n = n.child(name)
+	}
+	if n == nil {
+		return ""
+	}
+	return n.value
+}
+
+// all returns the descendants named name, in document order.
+func (n *ofxNode) all(name string) []*ofxNode {
+	var found []*ofxNode
+	for _, child := range n.children {
+		if child.name == name {
+			found = append(found, child)
+		}
+		found = append(found, child.all(name)...)
+	}
+	return found
+}
+
+type ofxSecurity struct {
+	ticker            string
+	name              string
+	option            bool
+	right             string
+	strike            decimal.Decimal
+	expiry            string
+	sharesPerContract string
+	underlyingID      string
+}
+
+// parseOFX reads the body of an OFX file into a tree. It accepts both OFX 1.x
+// SGML, where leaf elements are not closed, and OFX 2.x XML; the headers
+// before the OFX element are ignored.
+func parseOFX(data []byte) (*ofxNode, error) {
+	start := bytes.Index(bytes.ToUpper(data), []byte("<OFX>"))
+	if start < 0 {
+		return nil, errors.New("file is not an OFX statement")
+	}
+	line := 1 + bytes.Count(data[:start], []byte("\n"))
+	data = data[start:]
+
+	root := &ofxNode{}
+	stack := []*ofxNode{root}
+	for len(data) > 0 {
+		open := bytes.IndexByte(data, '<')
+		if open < 0 {
+			break
+		}
+		text := strings.TrimSpace(ofxEntities.Replace(string(data[:open])))
+		line += bytes.Count(data[:open], []byte("\n"))
+		data = data[open:]
+
+		// Text after an opening tag makes that element a leaf.
+		if text != "" && len(stack) > 1 {
+			top := stack[len(stack)-1]
+			if len(top.children) == 0 && top.value == "" {
+				top.value = text
+				stack = stack[:len(stack)-1]
+			}
+		}
+
+		end := bytes.IndexByte(data, '>')
+		if end < 0 {
+			return nil, fmt.Errorf("line %d: unterminated tag", line)
+		}
+		tag := strings.ToUpper(strings.TrimSpace(string(data[1:end])))
+		line += bytes.Count(data[:end], []byte("\n"))
+		data = data[end+1:]
+
+		switch {
+		case tag == "" || strings.HasPrefix(tag, "?") || strings.HasPrefix(tag, "!"):
+		case strings.HasPrefix(tag, "/"):
+			// Close the named aggregate and any unclosed leaves inside it;
+			// the closing tag of a leaf already closed by its text is ignored.
+			name := tag[1:]
+			for i := len(stack) - 1; i > 0; i-- {
+				if stack[i].name == name {
+					stack = stack[:i]
+					break
+				}
+			}
+		default:
+			node := &ofxNode{name: strings.TrimSuffix(tag, "/"), line: line}
+			top := stack[len(stack)-1]
+			top.children = append(top.children, node)
+			if !strings.HasSuffix(tag, "/") {
+				stack = append(stack, node)
+			}
+		}
+	}
+
+	ofx := root.child("OFX")
+	if ofx == nil {
+		return nil, errors.New("file is not an OFX statement")
+	}
+	return ofx, nil
+}
+
+// ParseOFX reads the investment transactions of an OFX or QFX statement.
+// Buys and sells of stocks, options, funds and bonds become fills; income,
+// expenses, margin interest and bank transactions become cash transactions;
+// dividend reinvestments become both. Other transactions, such as transfers
+// and splits, are reported as skipped. Times without a zone are read in loc;
+// the OFX default is UTC.
+func ParseOFX(data []byte, loc *time.Location) ([]Record, error) {
+	ofx, err := parseOFX(data)
+	if err != nil {
+		return nil, err
+	}
+
+	securities := ofxSecurities(ofx)
+	statements := ofx.all("INVSTMTRS")
+	if len(statements) == 0 {
+		return nil, errors.New("file has no investment statement")
+	}
+
+	records := []Record{}
+	for _, statement := range statements {
+		account := statement.text("INVACCTFROM", "ACCTID")
+		currency := statement.text("CURDEF")
+		for _, transaction := range statement.child("INVTRANLIST").children {
+			// DTSTART and DTEND are the only leaves; every transaction is an
+			// aggregate.
+			if len(transaction.children) == 0 {
+				continue
+			}
+			for _, record := range ofxRecords(transaction, securities, currency, loc) {
+				record.Line = transaction.line
+				record.Account = account
+				records = append(records, record)
+			}
+		}
+	}
+	if len(records) == 0 {
+		return nil, errors.New("file has no transactions")
+	}
+	return records, nil
+}
+
+func ofxSecurities(ofx *ofxNode) map[string]ofxSecurity {
+	securities := make(map[string]ofxSecurity)
+	for _, list := range ofx.all("SECLIST") {
+		for _, info := range list.children {
+			secInfo := info.child("SECINFO")
+			security := ofxSecurity{
+				ticker: secInfo.text("TICKER"),
+				name:   secInfo.text("SECNAME"),
+			}
+			if info.name == "OPTINFO" {
+				security.option = true
+				security.right = models.RightCall
+				if strings.EqualFold(info.text("OPTTYPE"), "PUT") {
+					security.right = models.RightPut
+				}
+				security.strike, _ = decimal.NewFromString(info.text("STRIKEPRICE"))
+				security.expiry = info.text("DTEXPIRE")
+				security.sharesPerContract = info.text("SHPERCTRCT")
+				security.underlyingID = info.text("SECID", "UNIQUEID")
+			}
+			securities[secInfo.text("SECID", "UNIQUEID")] = security
+		}
+	}
+	return securities
+}
+
+// ofxSymbol names the security with the given ID as the journal does. Options
+// listed without an OCC ticker are rebuilt from their contract terms.
+func ofxSymbol(id string, securities map[string]ofxSecurity, loc *time.Location) (string, error) {
+	security, ok := securities[id]
+	if !ok {
+		return "", fmt.Errorf("security %s is not in the securities list", id)
+	}
+	if !security.option {
+		if security.ticker == "" {
+			return id, nil
+		}
+		return security.ticker, nil
+	}
+	if _, err := instruments.ParseOCC(security.ticker); err == nil {
+		return security.ticker, nil
+	}
+	underlying := securities[security.underlyingID].ticker
+	expiry, err := ofxTime(security.expiry, loc)
+	if underlying == "" || err != nil {
+		if security.ticker == "" {
+			return id, nil
+		}
+		return security.ticker, nil
+	}
+	return instruments.FormatOCC(models.Instrument{
+		Underlying: strings.ToUpper(underlying),
+		Expiry:     &expiry,
+		Strike:     security.strike,
+		Right:      security.right,
+	}), nil
+}
+
+func ofxRecords(transaction *ofxNode, securities map[string]ofxSecurity, currency string, loc *time.Location) []Record {
+	name := transaction.name
+	switch {
+	case name == "INVBANKTRAN":
+		return []Record{ofxBankRecord(transaction.child("STMTTRN"), currency, loc)}
+	case name == "INCOME":
+		return []Record{ofxIncomeRecord(transaction, securities, currency, loc)}
+	case name == "INVEXPENSE":
+		return []Record{ofxCashRecord(transaction, securities, models.CashFee, true, currency, loc)}
+	case name == "MARGININTEREST":
+		return []Record{ofxCashRecord(transaction, securities, models.CashInterest, true, currency, loc)}
+	case name == "REINVEST":
+		income := ofxIncomeRecord(transaction, securities, currency, loc)
+		if income.Cash != nil {
+			income.Cash.Amount = income.Cash.Amount.Abs()
+		}
+		return []Record{income, ofxTradeRecord(transaction, transaction, models.SideBuy, securities, currency, loc)}
+	case strings.HasPrefix(name, "BUY") && transaction.child("INVBUY") != nil:
+		return []Record{ofxTradeRecord(transaction, transaction.child("INVBUY"), models.SideBuy, securities, currency, loc)}
+	case strings.HasPrefix(name, "SELL") && transaction.child("INVSELL") != nil:
+		return []Record{ofxTradeRecord(transaction, transaction.child("INVSELL"), models.SideSell, securities, currency, loc)}
+	}
+	return []Record{{Skip: strings.ToLower(name) + " transactions are not imported"}}
+}
+
+// ofxTradeRecord reads a buy or sell; detail is the INVBUY or INVSELL
+// aggregate, or the transaction itself for a reinvestment.
+func ofxTradeRecord(transaction, detail *ofxNode, side string, securities map[string]ofxSecurity, currency string, loc *time.Location) Record {
+	record := Record{ExecID: detail.text("INVTRAN", "FITID")}
+	execution := &record.Execution
+	execution.Side = side
+
+	symbol, err := ofxSymbol(detail.text("SECID", "UNIQUEID"), securities, loc)
+	if err != nil {
+		record.Err = err
+		return record
+	}
+	execution.Symbol = symbol
+
+	switch strings.ToUpper(transaction.text("BUYTYPE") + transaction.text("SELLTYPE") + transaction.text("OPTBUYTYPE") + transaction.text("OPTSELLTYPE")) {
+	case "BUY", "BUYTOOPEN":
+		execution.Action = models.ActionBuy
+	case "SELL", "SELLTOCLOSE":
+		execution.Action = models.ActionSell
+	case "SELLSHORT", "SELLTOOPEN":
+		execution.Action = models.ActionSellShort
+	case "BUYTOCOVER", "BUYTOCLOSE":
+		execution.Action = models.ActionBuyToCover
+	}
+
+	units, err := ofxDecimal(detail.text("UNITS"))
+	if err != nil || units.IsZero() {
+		record.Err = errors.New("units must be a non-zero number")
+		return record
+	}
+	execution.Quantity = units.Abs()
+	if execution.Price, err = ofxDecimal(detail.text("UNITPRICE")); err != nil || execution.Price.IsNegative() {
+		record.Err = errors.New("unit price must not be negative")
+		return record
+	}
+
+	var fees, taxes, load decimal.Decimal
+	execution.Commission, err = ofxDecimal(detail.text("COMMISSION"))
+	if err == nil {
+		fees, err = ofxDecimal(detail.text("FEES"))
+	}
+	if err == nil {
+		taxes, err = ofxDecimal(detail.text("TAXES"))
+	}
+	if err == nil {
+		load, err = ofxDecimal(detail.text("LOAD"))
+	}
+	if err != nil {
+		record.Err = errors.New("commission, fees, taxes and load must be numbers")
+		return record
+	}
+	execution.Commission = execution.Commission.Abs()
+	execution.Fees = fees.Abs().Add(taxes.Abs()).Add(load.Abs())
+	record.HasCommission = detail.child("COMMISSION") != nil
+	record.HasFees = detail.child("FEES") != nil || detail.child("TAXES") != nil || detail.child("LOAD") != nil
+
+	shares := transaction.text("SHPERCTRCT")
+	if shares == "" {
+		shares = securities[detail.text("SECID", "UNIQUEID")].sharesPerContract
+	}
+	if shares != "" {
+		execution.Multiplier, _ = decimal.NewFromString(shares)
+	}
+
+	execution.Currency = ofxCurrency(detail, currency)
+	if execution.ExecutedAt, err = ofxTime(detail.text("INVTRAN", "DTTRADE"), loc); err != nil {
+		record.Err = err
+	}
+	return record
+}
+
+func ofxIncomeRecord(transaction *ofxNode, securities map[string]ofxSecurity, currency string, loc *time.Location) Record {
+	cashType := models.CashDividend
+	switch strings.ToUpper(transaction.text("INCOMETYPE")) {
+	case "INTEREST":
+		cashType = models.CashInterest
+	case "MISC":
+		cashType = models.CashOther
+	}
+	return ofxCashRecord(transaction, securities, cashType, false, currency, loc)
+}
+
+// ofxCashRecord reads an investment transaction that only moved cash. OFX
+// signs expenses inconsistently, so debit forces their amount negative.
+func ofxCashRecord(transaction *ofxNode, securities map[string]ofxSecurity, cashType string, debit bool, currency string, loc *time.Location) Record {
+	record := Record{ExecID: transaction.text("INVTRAN", "FITID")}
+	cash := &models.CashTransaction{Type: cashType, Description: transaction.text("INVTRAN", "MEMO")}
+
+	if id := transaction.text("SECID", "UNIQUEID"); id != "" {
+		symbol, err := ofxSymbol(id, securities, loc)
+		if err != nil {
+			record.Err = err
+			return record
+		}
+		cash.Symbol = symbol
+	}
+
+	amount, err := ofxDecimal(transaction.text("TOTAL"))
+	if err != nil {
+		record.Err = errors.New("total must be a number")
+		return record
+	}
+	if debit {
+		amount = amount.Abs().Neg()
+	}
+	cash.Amount = amount
+
+	cash.Currency = ofxCurrency(transaction, currency)
+	if cash.PostedAt, err = ofxTime(transaction.text("INVTRAN", "DTTRADE"), loc); err != nil {
+		record.Err = err
+		return record
+	}
+	record.Cash = cash
+	return record
+}
+
+func ofxBankRecord(statement *ofxNode, currency string, loc *time.Location) Record {
+	record := Record{ExecID: statement.text("FITID")}
+
+	amount, err := ofxDecimal(statement.text("TRNAMT"))
+	if err != nil {
+		record.Err = errors.New("amount must be a number")
+		return record
+	}
+
+	cash := &models.CashTransaction{Amount: amount, Currency: ofxCurrency(statement, currency)}
+	switch strings.ToUpper(statement.text("TRNTYPE")) {
+	case "INT":
+		cash.Type = models.CashInterest
+	case "DIV":
+		cash.Type = models.CashDividend
+	case "FEE", "SRVCHG":
+		cash.Type = models.CashFee
+	default:
+		cash.Type = models.CashDeposit
+		if amount.IsNegative() {
+			cash.Type = models.CashWithdrawal
+		}
+	}
+	cash.Description = strings.TrimSpace(statement.text("NAME") + " " + statement.text("MEMO"))
+
+	if cash.PostedAt, err = ofxTime(statement.text("DTPOSTED"), loc); err != nil {
+		record.Err = err
+		return record
+	}
+	record.Cash = cash
+	return record
+}
+
+func ofxCurrency(n *ofxNode, fallback string) string {
+	if currency := n.text("CURRENCY", "CURSYM"); currency != "" {
+		return strings.ToUpper(currency)
+	}
+	if currency := n.text("ORIGCURRENCY", "CURSYM"); currency != "" {
+		return strings.ToUpper(currency)
+	}
+	return strings.ToUpper(fallback)
+}
+
+// ofxTime reads an OFX date time, YYYYMMDD optionally followed by HHMMSS,
+// milliseconds and a zone such as [-5:EST].
+func ofxTime(value string, loc *time.Location) (time.Time, error) {
+	raw := value
+	if i := strings.IndexByte(value, '['); i >= 0 {
+		zone := strings.TrimSuffix(value[i+1:], "]")
+		value = value[:i]
+		if j := strings.IndexByte(zone, ':'); j >= 0 {
+			zone = zone[:j]
+		}
+		hours, err := strconv.ParseFloat(zone, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("unrecognised date %q", raw)
+		}
+		loc = time.FixedZone("", int(hours*3600))
+	}
+	if i := strings.IndexByte(value, '.'); i >= 0 {
+		value = value[:i]
+	}
+
+	layouts := map[int]string{8: "20060102", 12: "200601021504", 14: "20060102150405"}
+	layout, ok := layouts[len(value)]
+	if !ok {
+		return time.Time{}, fmt.Errorf("unrecognised date %q", raw)
+	}
+	parsed, err := time.ParseInLocation(layout, value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unrecognised date %q", raw)
+	}
+	return parsed.UTC(), nil
+}
+
+// ofxDecimal reads an OFX amount, which some banks write with a decimal
+// comma. A blank value is zero.
+func ofxDecimal(value string) (decimal.Decimal, error) {
+	value = strings.ReplaceAll(strings.TrimSpace(value), ",", ".")
+	if value == "" {
+		return decimal.Zero, nil
+	}
+	return decimal.NewFromString(value)
+}
@@ -0,0 +1,113 @@
+package imports
+
+import (
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParseOFX_SGML(t *testing.T) {
+	records, err := ParseOFX(readFixture(t, "statement.ofx"), time.UTC)
+	assert.NoError(t, err)
+	assert.Len(t, records, 11)
+
+	buy := records[0]
+	assert.Equal(t, 33, buy.Line)
+	assert.Equal(t, "X12345678", buy.Account)
+	assert.Equal(t, "T-1001", buy.ExecID)
+	assert.Equal(t, "AAPL", buy.Execution.Symbol)
+	assert.Equal(t, models.SideBuy, buy.Execution.Side)
+	assert.Equal(t, models.ActionBuy, buy.Execution.Action)
+	assert.Equal(t, "100", buy.Execution.Quantity.String())
+	assert.Equal(t, "170.25", buy.Execution.Price.String())
+	assert.Equal(t, "0.02", buy.Execution.Fees.String())
+	assert.Equal(t, "USD", buy.Execution.Currency)
+	assert.Equal(t, time.Date(2024, 5, 1, 13, 30, 0, 0, time.UTC), buy.Execution.ExecutedAt)
+
+	sell := records[1]
+	assert.Equal(t, models.SideSell, sell.Execution.Side)
+	assert.Equal(t, "100", sell.Execution.Quantity.String())
+	assert.Equal(t, "0.04", sell.Execution.Fees.String())
+
+	option := records[2]
+	assert.Equal(t, "SPY   240621P00500000", option.Execution.Symbol, "options are rebuilt from the securities list")
+	assert.Equal(t, models.ActionSellShort, option.Execution.Action)
+	assert.Equal(t, "100", option.Execution.Multiplier.String())
+	assert.Equal(t, "1.3", option.Execution.Commission.String())
+	assert.True(t, option.HasCommission)
+
+	dividend := records[3].Cash
+	assert.Equal(t, models.CashDividend, dividend.Type)
+	assert.Equal(t, "AAPL", dividend.Symbol)
+	assert.Equal(t, "24", dividend.Amount.String())
+	assert.Equal(t, "DIVIDEND RECEIVED", dividend.Description)
+
+	assert.Equal(t, "50", records[4].Cash.Amount.String(), "a reinvested dividend is paid in")
+	assert.Equal(t, "VFIAX", records[5].Execution.Symbol)
+	assert.Equal(t, "0.1", records[5].Execution.Quantity.String())
+	assert.Equal(t, records[4].Line, records[5].Line)
+
+	fee := records[6].Cash
+	assert.Equal(t, models.CashFee, fee.Type)
+	assert.Equal(t, "-1.5", fee.Amount.String())
+
+	assert.Equal(t, "transfer transactions are not imported", records[7].Skip)
+	assert.EqualError(t, records[8].Err, "security 999999999 is not in the securities list")
+
+	deposit := records[9].Cash
+	assert.Equal(t, models.CashDeposit, deposit.Type)
+	assert.Equal(t, "5000", deposit.Amount.String())
+	assert.Equal(t, "ELECTRONIC FUNDS TRANSFER", deposit.Description)
+	assert.Equal(t, "B-6001", records[9].ExecID)
+
+	assert.Equal(t, models.CashInterest, records[10].Cash.Type)
+}
+
+func TestParseOFX_XML(t *testing.T) {
+	records, err := ParseOFX(readFixture(t, "statement.qfx"), time.UTC)
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+
+	short := records[0].Execution
+	assert.Equal(t, "C-555", records[0].Account)
+	assert.Equal(t, "SHOP.TO", short.Symbol)
+	assert.Equal(t, models.ActionSellShort, short.Action)
+	assert.Equal(t, "95.5", short.Price.String())
+	assert.Equal(t, "9.99", short.Commission.String())
+	assert.Equal(t, "CAD", short.Currency)
+	assert.Equal(t, time.Date(2024, 5, 1, 14, 30, 0, 0, time.UTC), short.ExecutedAt)
+
+	interest := records[1].Cash
+	assert.Equal(t, models.CashInterest, interest.Type)
+	assert.Equal(t, "-3.21", interest.Amount.String())
+	assert.Equal(t, "CAD", interest.Currency)
+}
+
+func TestParseOFX_Rejects(t *testing.T) {
+	_, err := ParseOFX([]byte("date,symbol\n"), time.UTC)
+	assert.EqualError(t, err, "file is not an OFX statement")
+
+	_, err = ParseOFX([]byte("<OFX><SIGNONMSGSRSV1></SIGNONMSGSRSV1></OFX>"), time.UTC)
+	assert.EqualError(t, err, "file has no investment statement")
+
+	_, err = ParseOFX([]byte("<OFX><INVSTMTRS><INVTRANLIST></INVTRANLIST></INVSTMTRS></OFX>"), time.UTC)
+	assert.EqualError(t, err, "file has no transactions")
+}
+
+func TestOFXTime(t *testing.T) {
+	parsed, err := ofxTime("20240501093000.123[-5:EST]", time.UTC)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 5, 1, 14, 30, 0, 0, time.UTC), parsed)
+
+	parsed, err = ofxTime("20240501", time.UTC)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), parsed)
+
+	parsed, err = ofxTime("202405010930[+5.5:IST]", time.UTC)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 5, 1, 4, 0, 0, 0, time.UTC), parsed)
+
+	_, err = ofxTime("May 1", time.UTC)
+	assert.EqualError(t, err, `unrecognised date "May 1"`)
+}
@@ -0,0 +1,194 @@
+package imports
+
+import (
+	"errors"
+	"fmt"
+	"github.com/drewbuiltit/trading-journal/backend/internal/instruments"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// platform describes the execution export of a trading platform: the header
+// names each column goes by across versions, the time formats it writes and
+// how its contract symbols map onto the journal's.
+type platform struct {
+	name     string
+	columns  map[string][]string
+	required []string
+	formats  []string
+	symbol   func(string) (string, error)
+}
+
+var ninjaTrader = platform{
+	name: "NinjaTrader executions",
+	columns: map[string][]string{
+		"symbol":     {"Instrument"},
+		"side":       {"Action"},
+		"quantity":   {"Quantity", "Qty"},
+		"price":      {"Price"},
+		"date":       {"Time", "Date/Time"},
+		"commission": {"Commission"},
+		"id":         {"ID", "Execution ID"},
+		"account":    {"Account"},
+	},
+	required: []string{"symbol", "side", "quantity", "price", "date"},
+	formats: []string{
+		"M/D/YYYY h:mm:ss A",
+		"M/D/YYYY HH:mm:ss",
+		"YYYY-MM-DD HH:mm:ss",
+		"DD.MM.YYYY HH:mm:ss",
+	},
+	symbol: ninjaTraderSymbol,
+}
+
+var tradovate = platform{
+	name: "Tradovate fills",
+	columns: map[string][]string{
+		"symbol":     {"Contract", "_contract", "Symbol"},
+		"side":       {"B/S", "_action", "Side", "Buy/Sell"},
+		"quantity":   {"Quantity", "_qty", "Qty", "filledQty"},
+		"price":      {"Price", "_price", "Fill Price", "avgPrice"},
+		"date":       {"Timestamp", "_timestamp", "Fill Time", "Time"},
+		"commission": {"Commission", "Commissions"},
+		"fees":       {"Fees", "Exchange Fees"},
+		"id":         {"Fill ID", "_id", "fillId"},
+		"account":    {"Account", "_accountName"},
+	},
+	required: []string{"symbol", "side", "quantity", "price", "date"},
+	formats: []string{
+		time.RFC3339Nano,
+		"MM/DD/YYYY HH:mm:ss",
+		"M/D/YYYY h:mm:ss A",
+		"YYYY-MM-DD HH:mm:ss",
+	},
+	symbol: tradovateSymbol,
+}
+
+var tradeStation = platform{
+	name: "TradeStation trade history",
+	columns: map[string][]string{
+		"symbol":     {"Symbol"},
+		"side":       {"Type", "Action", "Side", "Buy/Sell"},
+		"quantity":   {"Qty", "Quantity", "Shares/Contracts"},
+		"price":      {"Filled Price", "Price", "Fill Price"},
+		"date":       {"Trade Date", "Date", "Date/Time", "Entered"},
+		"time":       {"Time", "Trade Time"},
+		"commission": {"Commission", "Comm"},
+		"fees":       {"Fees", "Other Fees"},
+		"account":    {"Account", "Account #"},
+	},
+	required: []string{"symbol", "side", "quantity", "price", "date"},
+	formats: []string{
+		"M/D/YYYY",
+		"M/D/YYYY h:mm:ss A",
+		"M/D/YYYY HH:mm:ss",
+		"M/D/YYYY h:mm A",
+		"YYYY-MM-DD",
+		"YYYY-MM-DD HH:mm:ss",
+	},
+	symbol: tradeStationSymbol,
+}
+
+// ParseNinjaTrader reads a NinjaTrader executions grid export. Futures are
+// written as the root and expiry, e.g. "ES 06-24".
+func ParseNinjaTrader(data []byte, loc *time.Location) ([]Record, error) {
+	return parsePlatform(data, ninjaTrader, loc)
+}
+
+// ParseTradovate reads a Tradovate fills export. Every symbol must be a
+// futures contract in the specs registry.
+func ParseTradovate(data []byte, loc *time.Location) ([]Record, error) {
+	return parsePlatform(data, tradovate, loc)
+}
+
+// ParseTradeStation reads a TradeStation trade history export. Futures may
+// carry the continuous-contract @ prefix.
+func ParseTradeStation(data []byte, loc *time.Location) ([]Record, error) {
+	return parsePlatform(data, tradeStation, loc)
+}
+
+func parsePlatform(data []byte, p platform, loc *time.Location) ([]Record, error) {
+	header, err := newCSVReader(data).Read()
+	if err == io.EOF {
+		return nil, errors.New("CSV is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %v", err)
+	}
+
+	format := csvFormat{columns: make(map[string]string), symbol: p.symbol}
+	for field, names := range p.columns {
+		for _, name := range names {
+			if column, ok := findColumn(header, name); ok {
+				format.columns[field] = column
+				break
+			}
+		}
+	}
+	for _, field := range p.required {
+		if format.columns[field] == "" {
+			return nil, fmt.Errorf("file is not a %s export: no %s column", p.name, p.columns[field][0])
+		}
+	}
+
+	// A separate time column is appended to the date, so every date format
+	// is also tried with a time after it.
+	for _, f := range p.formats {
+		layout := Layout(f)
+		if f == time.RFC3339Nano {
+			layout = f
+		}
+		if format.columns["time"] != "" {
+			format.layouts = append(format.layouts, layout+" "+Layout("HH:mm:ss"), layout+" "+Layout("h:mm:ss A"))
+			continue
+		}
+		format.layouts = append(format.layouts, layout)
+	}
+
+	return parseCSV(data, format, loc)
+}
+
+// ninjaTraderExpiry matches NinjaTrader's "ROOT MM-YY" futures names.
+var ninjaTraderExpiry = regexp.MustCompile(`^([A-Z0-9]+) (\d{2})-(\d{2})$`)
+
+func ninjaTraderSymbol(symbol string) (string, error) {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	match := ninjaTraderExpiry.FindStringSubmatch(symbol)
+	if match == nil {
+		return symbol, nil
+	}
+	month, _ := strconv.Atoi(match[2])
+	if month < 1 || month > 12 {
+		return "", fmt.Errorf("unrecognised contract %q", symbol)
+	}
+	return futureSymbol(match[1]+string(monthCodes[month-1])+match[3], symbol)
+}
+
+func tradovateSymbol(symbol string) (string, error) {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	return futureSymbol(symbol, symbol)
+}
+
+func tradeStationSymbol(symbol string) (string, error) {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	if !strings.HasPrefix(symbol, "@") {
+		return symbol, nil
+	}
+	return futureSymbol(strings.TrimPrefix(symbol, "@"), symbol)
+}
+
+// monthCodes are the futures delivery month letters, January first.
+const monthCodes = "FGHJKMNQUVXZ"
+
+// futureSymbol checks that a contract is one the specs registry knows, so
+// that its point value is applied rather than a multiplier of one.
+func futureSymbol(symbol, original string) (string, error) {
+	instrument, err := instruments.ParseFuture(symbol)
+	if err != nil {
+		return "", fmt.Errorf("contract %q is not a known futures contract", original)
+	}
+	return instrument.Symbol, nil
+}
@@ -0,0 +1,86 @@
+package imports
+
+import (
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParseNinjaTrader(t *testing.T) {
+	records, err := ParseNinjaTrader(readFixture(t, "ninjatrader.csv"), time.UTC)
+	assert.NoError(t, err)
+	assert.Len(t, records, 4)
+
+	entry := records[0]
+	assert.Equal(t, "Sim101", entry.Account)
+	assert.Equal(t, "a1b2c3d4e5f6", entry.ExecID)
+	assert.Equal(t, "ESM24", entry.Execution.Symbol)
+	assert.Equal(t, models.SideBuy, entry.Execution.Side)
+	assert.Equal(t, "5100.25", entry.Execution.Price.String())
+	assert.Equal(t, "4.3", entry.Execution.Commission.String())
+	assert.True(t, entry.HasCommission)
+	assert.False(t, entry.HasFees, "NinjaTrader exports have no fees column")
+	assert.Equal(t, time.Date(2024, 5, 1, 9, 30, 15, 0, time.UTC), entry.Execution.ExecutedAt)
+
+	assert.Equal(t, models.SideSell, records[1].Execution.Side)
+	assert.Equal(t, "MNQU24", records[2].Execution.Symbol)
+	assert.Equal(t, models.ActionSellShort, records[2].Execution.Action)
+	assert.Equal(t, time.Date(2024, 5, 1, 14, 15, 0, 0, time.UTC), records[2].Execution.ExecutedAt)
+	assert.EqualError(t, records[3].Err, `contract "ZZ 06-24" is not a known futures contract`)
+}
+
+func TestParseNinjaTrader_MalformedRow(t *testing.T) {
+	data := "Instrument,Action,Quantity,Price,Time,ID,E/X,Position,Order ID,Name,Commission,Rate,Account,Connection,\n" +
+		"ES 06-24,Buy,2,5100.25,5/1/2024 9:30:15 AM,a1\"b2,Entry,2 L,9f8e7d6c,Entry,$4.30,1,Sim101,My Connection,\n"
+
+	records, err := ParseNinjaTrader([]byte(data), time.UTC)
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+	assert.Equal(t, 2, records[0].Line)
+	assert.Error(t, records[0].Err)
+}
+
+func TestParseTradovate(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	assert.NoError(t, err)
+
+	records, err := ParseTradovate(readFixture(t, "tradovate.csv"), chicago)
+	assert.NoError(t, err)
+	assert.Len(t, records, 3)
+
+	buy := records[0]
+	assert.Equal(t, "7001", buy.ExecID)
+	assert.Equal(t, "MESM24", buy.Execution.Symbol)
+	assert.Equal(t, "0.75", buy.Execution.Commission.String())
+	assert.Equal(t, "0.33", buy.Execution.Fees.String())
+	assert.Equal(t, time.Date(2024, 5, 1, 13, 30, 15, 250000000, time.UTC), buy.Execution.ExecutedAt, "a zone in the file wins")
+
+	assert.Equal(t, time.Date(2024, 5, 1, 15, 5, 0, 0, time.UTC), records[1].Execution.ExecutedAt)
+	assert.EqualError(t, records[2].Err, `contract "AAPL" is not a known futures contract`)
+}
+
+func TestParseTradeStation(t *testing.T) {
+	records, err := ParseTradeStation(readFixture(t, "tradestation.csv"), time.UTC)
+	assert.NoError(t, err)
+	assert.Len(t, records, 3)
+
+	assert.Equal(t, "NQU24", records[0].Execution.Symbol)
+	assert.Equal(t, "18400", records[0].Execution.Price.String())
+	assert.Equal(t, time.Date(2024, 5, 1, 9, 45, 0, 0, time.UTC), records[0].Execution.ExecutedAt)
+	assert.Empty(t, records[0].ExecID, "order numbers do not identify fills")
+
+	assert.Equal(t, "NQU24", records[1].Execution.Symbol)
+	assert.Equal(t, time.Date(2024, 5, 1, 15, 55, 10, 0, time.UTC), records[1].Execution.ExecutedAt)
+
+	assert.Equal(t, "MSFT", records[2].Execution.Symbol)
+	assert.Equal(t, models.ActionSellShort, records[2].Execution.Action)
+}
+
+func TestParsePlatform_WrongFile(t *testing.T) {
+	_, err := ParseNinjaTrader(readFixture(t, "tradovate.csv"), time.UTC)
+	assert.EqualError(t, err, "file is not a NinjaTrader executions export: no Instrument column")
+
+	_, err = ParseTradovate(nil, time.UTC)
+	assert.EqualError(t, err, "CSV is empty")
+}
@@ -0,0 +1,200 @@
+package imports
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/drewbuiltit/trading-journal/backend/internal/auth"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"github.com/gorilla/mux"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type TemplateRequest struct {
+	Name             string `json:"name"`
+	SymbolColumn     string `json:"symbol_column"`
+	SideColumn       string `json:"side_column,omitempty"`
+	QuantityColumn   string `json:"quantity_column"`
+	PriceColumn      string `json:"price_column"`
+	DateColumn       string `json:"date_column"`
+	TimeColumn       string `json:"time_column,omitempty"`
+	CommissionColumn string `json:"commission_column,omitempty"`
+	FeesColumn       string `json:"fees_column,omitempty"`
+	CurrencyColumn   string `json:"currency_column,omitempty"`
+	IDColumn         string `json:"id_column,omitempty"`
+	DateFormat       string `json:"date_format"`
+	TimeFormat       string `json:"time_format,omitempty"`
+}
+
+func (req *TemplateRequest) validate() error {
+	if strings.TrimSpace(req.Name) == "" {
+		return errors.New("name is required")
+	}
+	for _, column := range []string{req.SymbolColumn, req.QuantityColumn, req.PriceColumn, req.DateColumn} {
+		if strings.TrimSpace(column) == "" {
+			return errors.New("symbol, quantity, price and date columns are required")
+		}
+	}
+	if strings.TrimSpace(req.DateFormat) == "" {
+		return errors.New("date_format is required")
+	}
+	return nil
+}
+
+func (req *TemplateRequest) apply(template *models.CSVTemplate) {
+	template.Name = strings.TrimSpace(req.Name)
+	template.SymbolColumn = strings.TrimSpace(req.SymbolColumn)
+	template.SideColumn = strings.TrimSpace(req.SideColumn)
+	template.QuantityColumn = strings.TrimSpace(req.QuantityColumn)
+	template.PriceColumn = strings.TrimSpace(req.PriceColumn)
+	template.DateColumn = strings.TrimSpace(req.DateColumn)
+	template.TimeColumn = strings.TrimSpace(req.TimeColumn)
+	template.CommissionColumn = strings.TrimSpace(req.CommissionColumn)
+	template.FeesColumn = strings.TrimSpace(req.FeesColumn)
+	template.CurrencyColumn = strings.TrimSpace(req.CurrencyColumn)
+	template.IDColumn = strings.TrimSpace(req.IDColumn)
+	template.DateFormat = strings.TrimSpace(req.DateFormat)
+	template.TimeFormat = strings.TrimSpace(req.TimeFormat)
+}
+
+func (h *ImportHandler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	var req TemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	if err := req.validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	template := &models.CSVTemplate{UserID: userID}
+	req.apply(template)
+
+	if err := h.Store.CreateCSVTemplate(template); err != nil {
+		http.Error(w, "Error creating template", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(template)
+}
+
+func (h *ImportHandler) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	templates, err := h.Store.ListCSVTemplates(userID)
+	if err != nil {
+		http.Error(w, "Error listing templates", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(templates)
+}
+
+func (h *ImportHandler) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	templateID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid template ID", http.StatusBadRequest)
+		return
+	}
+
+	template, err := h.Store.GetCSVTemplate(userID, templateID)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "Template not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error fetching template", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(template)
+}
+
+func (h *ImportHandler) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	templateID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid template ID", http.StatusBadRequest)
+		return
+	}
+
+	var req TemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	if err := req.validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	template := &models.CSVTemplate{ID: templateID, UserID: userID}
+	req.apply(template)
+
+	err = h.Store.UpdateCSVTemplate(template)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "Template not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error updating template", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(template)
+}
+
+func (h *ImportHandler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	templateID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid template ID", http.StatusBadRequest)
+		return
+	}
+
+	err = h.Store.DeleteCSVTemplate(userID, templateID)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "Template not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error deleting template", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,71 @@
+package instruments
+
+import (
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"strings"
+)
+
+// exchangeCurrencies maps exchange suffixes on equity symbols, as in VOD.L or
+// 7203.T, to the currency the exchange quotes in.
+var exchangeCurrencies = map[string]string{
+	"L":  "GBP",
+	"T":  "JPY",
+	"TO": "CAD",
+	"V":  "CAD",
+	"DE": "EUR",
+	"F":  "EUR",
+	"PA": "EUR",
+	"AS": "EUR",
+	"MI": "EUR",
+	"MC": "EUR",
+	"SW": "CHF",
+	"HK": "HKD",
+	"AX": "AUD",
+	"ST": "SEK",
+	"CO": "DKK",
+	"OL": "NOK",
+}
+
+// exchangeCurrency returns the currency implied by an equity symbol's
+// exchange suffix, or "" when it has none.
+func exchangeCurrency(symbol string) string {
+	dot := strings.LastIndexByte(symbol, '.')
+	if dot < 0 {
+		return ""
+	}
+	return exchangeCurrencies[symbol[dot+1:]]
+}
+
+// ValidCurrency reports whether code looks like an ISO 4217 currency code.
+func ValidCurrency(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// CurrencyFor is the currency a fill in symbol is priced in when none was
+// given: the instrument's own currency, otherwise fallback, otherwise the
+// default.
+func CurrencyFor(symbol, fallback string) string {
+	if currency := Lookup(symbol).Currency; currency != "" {
+		return currency
+	}
+	if fallback != "" {
+		return fallback
+	}
+	return models.DefaultCurrency
+}
+
+// Currency is the currency a fill is priced in.
+func Currency(execution models.Execution) string {
+	if execution.Currency != "" {
+		return execution.Currency
+	}
+	return CurrencyFor(execution.Symbol, "")
+}
@@ -0,0 +1,170 @@
+package instruments
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/shopspring/decimal"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrNotFuture = errors.New("not a futures contract symbol")
+
+// monthCodes are the exchange month codes, January to December.
+const monthCodes = "FGHJKMNQUVXZ"
+
+//go:embed futures.json
+var seedFutures []byte
+
+// FutureSpec is the contract specification for a futures root.
+type FutureSpec struct {
+	Root        string          `json:"root"`
+	Description string          `json:"description"`
+	Exchange    string          `json:"exchange"`
+	TickSize    decimal.Decimal `json:"tick_size"`
+	TickValue   decimal.Decimal `json:"tick_value"`
+	Currency    string          `json:"currency"`
+}
+
+// PointValue is what a one point move in price is worth for one contract.
+func (s FutureSpec) PointValue() decimal.Decimal {
+	return s.TickValue.Div(s.TickSize)
+}
+
+var (
+	futuresMu sync.RWMutex
+	futures   map[string]FutureSpec
+
+	// now is replaced in tests to pin the decade of single-digit years.
+	now = time.Now
+)
+
+func init() {
+	if err := LoadFutures(bytes.NewReader(seedFutures)); err != nil {
+		panic(err)
+	}
+}
+
+// LoadFutures replaces the registry with the JSON array of specs read from r.
+func LoadFutures(r io.Reader) error {
+	var specs []FutureSpec
+	if err := json.NewDecoder(r).Decode(&specs); err != nil {
+		return err
+	}
+
+	registry := make(map[string]FutureSpec, len(specs))
+	for _, spec := range specs {
+		spec.Root = strings.ToUpper(strings.TrimSpace(spec.Root))
+		spec.Currency = strings.ToUpper(spec.Currency)
+		if spec.Root == "" {
+			return errors.New("future spec without a root")
+		}
+		if !spec.TickSize.IsPositive() || !spec.TickValue.IsPositive() {
+			return fmt.Errorf("future %s: tick size and tick value must be positive", spec.Root)
+		}
+		registry[spec.Root] = spec
+	}
+
+	futuresMu.Lock()
+	futures = registry
+	futuresMu.Unlock()
+	return nil
+}
+
+// LoadFuturesFile replaces the registry with the specs in a local JSON file.
+func LoadFuturesFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return LoadFutures(file)
+}
+
+// Futures returns the registered specs ordered by root.
+func Futures() []FutureSpec {
+	futuresMu.RLock()
+	defer futuresMu.RUnlock()
+
+	specs := make([]FutureSpec, 0, len(futures))
+	for _, spec := range futures {
+		specs = append(specs, spec)
+	}
+	sort.Slice(specs, func(i, j int) bool { return specs[i].Root < specs[j].Root })
+	return specs
+}
+
+func FutureSpecFor(root string) (FutureSpec, bool) {
+	futuresMu.RLock()
+	defer futuresMu.RUnlock()
+
+	spec, ok := futures[strings.ToUpper(root)]
+	return spec, ok
+}
+
+// ParseFuture reads a futures contract symbol: a registered root, a month
+// code and a one- or two-digit year, optionally prefixed with a slash as in
+// "/ESZ24" or "ESZ4". A single-digit year is taken as the nearest such year
+// to today. The canonical symbol always carries two digits.
+func ParseFuture(symbol string) (models.Instrument, error) {
+	symbol = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(symbol)), "/")
+
+	i := len(symbol)
+	for i > 0 && symbol[i-1] >= '0' && symbol[i-1] <= '9' {
+		i--
+	}
+	digits := symbol[i:]
+	if len(digits) == 0 || len(digits) > 2 || i < 2 {
+		return models.Instrument{}, ErrNotFuture
+	}
+
+	month := strings.IndexByte(monthCodes, symbol[i-1])
+	if month < 0 {
+		return models.Instrument{}, ErrNotFuture
+	}
+
+	root := symbol[:i-1]
+	spec, ok := FutureSpecFor(root)
+	if !ok {
+		return models.Instrument{}, ErrNotFuture
+	}
+
+	year, _ := strconv.Atoi(digits)
+	if len(digits) == 1 {
+		year = nearestYear(year, now().Year())
+	} else {
+		year += 2000
+	}
+
+	return models.Instrument{
+		Symbol:        fmt.Sprintf("%s%c%02d", root, monthCodes[month], year%100),
+		AssetClass:    models.AssetFuture,
+		Underlying:    root,
+		Strike:        decimal.Zero,
+		ContractMonth: fmt.Sprintf("%04d-%02d", year, month+1),
+		TickSize:      spec.TickSize,
+		TickValue:     spec.TickValue,
+		Currency:      spec.Currency,
+		Multiplier:    spec.PointValue(),
+	}, nil
+}
+
+func nearestYear(digit, current int) int {
+	year := current - current%10 + digit
+	switch {
+	case year > current+5:
+		year -= 10
+	case year < current-4:
+		year += 10
+	}
+	return year
+}
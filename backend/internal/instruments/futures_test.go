@@ -0,0 +1,69 @@
+package instruments
+
+import (
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseFuture(t *testing.T) {
+	now = func() time.Time { return time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	for _, symbol := range []string{"ESZ24", "/ESZ24", "esz4", "ESZ4"} {
+		instrument, err := ParseFuture(symbol)
+		assert.NoError(t, err, symbol)
+		assert.Equal(t, "ESZ24", instrument.Symbol)
+		assert.Equal(t, models.AssetFuture, instrument.AssetClass)
+		assert.Equal(t, "ES", instrument.Underlying)
+		assert.Equal(t, "2024-12", instrument.ContractMonth)
+		assert.Equal(t, "0.25", instrument.TickSize.String())
+		assert.Equal(t, "12.5", instrument.TickValue.String())
+		assert.Equal(t, "USD", instrument.Currency)
+		assert.Equal(t, "50", instrument.Multiplier.String())
+	}
+
+	instrument, err := ParseFuture("M2KH5")
+	assert.NoError(t, err)
+	assert.Equal(t, "M2KH25", instrument.Symbol)
+	assert.Equal(t, "2025-03", instrument.ContractMonth)
+	assert.Equal(t, "5", instrument.Multiplier.String())
+
+	instrument, err = ParseFuture("FDAXU3")
+	assert.NoError(t, err)
+	assert.Equal(t, "2023-09", instrument.ContractMonth)
+	assert.Equal(t, "EUR", instrument.Currency)
+}
+
+func TestParseFuture_Rejects(t *testing.T) {
+	for _, symbol := range []string{"ES", "ESZ", "ESA24", "ESZ2024", "XXZ24", "AAPL", "Z4"} {
+		_, err := ParseFuture(symbol)
+		assert.ErrorIs(t, err, ErrNotFuture, symbol)
+	}
+}
+
+func TestNearestYear(t *testing.T) {
+	assert.Equal(t, 2029, nearestYear(9, 2026))
+	assert.Equal(t, 2030, nearestYear(0, 2026))
+	assert.Equal(t, 2022, nearestYear(2, 2026))
+	assert.Equal(t, 2019, nearestYear(9, 2021))
+}
+
+func TestLoadFutures(t *testing.T) {
+	defer LoadFutures(strings.NewReader(string(seedFutures)))
+
+	err := LoadFutures(strings.NewReader(`[{"root": "zz", "tick_size": "0.5", "tick_value": "5", "currency": "usd"}]`))
+	assert.NoError(t, err)
+	assert.Len(t, Futures(), 1)
+
+	instrument := Lookup("ZZM25")
+	assert.Equal(t, models.AssetFuture, instrument.AssetClass)
+	assert.Equal(t, "10", instrument.Multiplier.String())
+	assert.Equal(t, models.AssetEquity, Lookup("ESM25").AssetClass, "roots outside the registry are not futures")
+
+	err = LoadFutures(strings.NewReader(`[{"root": "ZZ", "tick_size": "0", "tick_value": "5"}]`))
+	assert.EqualError(t, err, "future ZZ: tick size and tick value must be positive")
+	assert.Len(t, Futures(), 1, "a failed load keeps the previous registry")
+}
@@ -0,0 +1,26 @@
+package instruments
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+type InstrumentHandler struct{}
+
+// GetInstrument describes the contract behind the symbol query parameter.
+func (h *InstrumentHandler) GetInstrument(w http.ResponseWriter, r *http.Request) {
+	symbol := r.URL.Query().Get("symbol")
+	if strings.TrimSpace(symbol) == "" {
+		http.Error(w, "symbol is required", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Lookup(symbol))
+}
+
+func (h *InstrumentHandler) ListFutures(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Futures())
+}
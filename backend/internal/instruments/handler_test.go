@@ -0,0 +1,51 @@
+package instruments
+
+import (
+	"encoding/json"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestInstrumentHandler(t *testing.T) {
+	instrumentHandler := &InstrumentHandler{}
+
+	t.Run("Option Symbol", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/instruments?symbol="+url.QueryEscape("SPY   240621P00500000"), nil)
+		assert.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		instrumentHandler.GetInstrument(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var instrument models.Instrument
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &instrument))
+		assert.Equal(t, models.AssetOption, instrument.AssetClass)
+		assert.Equal(t, "500", instrument.Strike.String())
+	})
+
+	t.Run("Missing Symbol", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/instruments", nil)
+		assert.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		instrumentHandler.GetInstrument(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Futures Specs", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/instruments/futures", nil)
+		assert.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		instrumentHandler.ListFutures(rr, req)
+
+		var specs []FutureSpec
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &specs))
+		assert.Equal(t, len(Futures()), len(specs))
+		assert.Equal(t, "6B", specs[0].Root)
+	})
+}
@@ -0,0 +1,45 @@
+package instruments
+
+import (
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/shopspring/decimal"
+	"strings"
+)
+
+// Lookup describes the contract behind a symbol. OCC option symbols resolve
+// to options and contracts on a registered futures root to futures; anything
+// else is treated as an equity with a multiplier of one, priced in the
+// currency of its exchange suffix when it has one.
+func Lookup(symbol string) models.Instrument {
+	if instrument, err := ParseOCC(symbol); err == nil {
+		return instrument
+	}
+	if instrument, err := ParseFuture(symbol); err == nil {
+		return instrument
+	}
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	return models.Instrument{
+		Symbol:     symbol,
+		AssetClass: models.AssetEquity,
+		Underlying: symbol,
+		Strike:     decimal.Zero,
+		Currency:   exchangeCurrency(symbol),
+		Multiplier: decimal.NewFromInt(1),
+	}
+}
+
+// Normalize returns the canonical form of a symbol so that every spelling of
+// a contract groups into the same position.
+func Normalize(symbol string) string {
+	return Lookup(symbol).Symbol
+}
+
+// Multiplier is the number of units of the underlying one unit of the fill
+// controls: the fill's own multiplier when it has one, otherwise the
+// contract default.
+func Multiplier(execution models.Execution) decimal.Decimal {
+	if execution.Multiplier.IsPositive() {
+		return execution.Multiplier
+	}
+	return Lookup(execution.Symbol).Multiplier
+}
@@ -0,0 +1,95 @@
+package instruments
+
+import (
+	"errors"
+	"fmt"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/shopspring/decimal"
+	"strings"
+	"time"
+)
+
+var ErrNotOCC = errors.New("not an OCC option symbol")
+
+// optionMultiplier is the deliverable of a standard US equity option.
+var optionMultiplier = decimal.NewFromInt(100)
+
+// ParseOCC reads an OCC option symbol: a root of up to six characters, the
+// expiry as YYMMDD, C or P, and the strike times 1000 in eight digits. The
+// root may be padded to six characters with spaces or run straight into the
+// expiry, as many brokers print it.
+func ParseOCC(symbol string) (models.Instrument, error) {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	if len(symbol) < 16 {
+		return models.Instrument{}, ErrNotOCC
+	}
+
+	root := strings.TrimRight(symbol[:len(symbol)-15], " ")
+	tail := symbol[len(symbol)-15:]
+	if root == "" || len(root) > 6 || !alphanumeric(root) {
+		return models.Instrument{}, ErrNotOCC
+	}
+
+	expiry, err := time.Parse("060102", tail[:6])
+	if err != nil {
+		return models.Instrument{}, ErrNotOCC
+	}
+
+	var right string
+	switch tail[6] {
+	case 'C':
+		right = models.RightCall
+	case 'P':
+		right = models.RightPut
+	default:
+		return models.Instrument{}, ErrNotOCC
+	}
+
+	if !digits(tail[7:]) {
+		return models.Instrument{}, ErrNotOCC
+	}
+	strike := decimal.RequireFromString(tail[7:]).Shift(-3)
+
+	instrument := models.Instrument{
+		AssetClass: models.AssetOption,
+		Underlying: root,
+		Expiry:     &expiry,
+		Strike:     strike,
+		Right:      right,
+		Currency:   models.DefaultCurrency,
+		Multiplier: optionMultiplier,
+	}
+	instrument.Symbol = FormatOCC(instrument)
+	return instrument, nil
+}
+
+// FormatOCC writes the canonical 21-character OCC symbol for an option.
+func FormatOCC(instrument models.Instrument) string {
+	right := "C"
+	if instrument.Right == models.RightPut {
+		right = "P"
+	}
+	var expiry string
+	if instrument.Expiry != nil {
+		expiry = instrument.Expiry.Format("060102")
+	}
+	return fmt.Sprintf("%-6s%s%s%08d", instrument.Underlying, expiry, right, instrument.Strike.Shift(3).IntPart())
+}
+
+func alphanumeric(value string) bool {
+	for _, r := range value {
+		if (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+func digits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,74 @@
+package instruments
+
+import (
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParseOCC(t *testing.T) {
+	for _, symbol := range []string{"AAPL  240119C00190000", "AAPL240119C00190000", "aapl240119c00190000"} {
+		instrument, err := ParseOCC(symbol)
+		assert.NoError(t, err, symbol)
+		assert.Equal(t, "AAPL  240119C00190000", instrument.Symbol)
+		assert.Equal(t, models.AssetOption, instrument.AssetClass)
+		assert.Equal(t, "AAPL", instrument.Underlying)
+		assert.Equal(t, time.Date(2024, 1, 19, 0, 0, 0, 0, time.UTC), *instrument.Expiry)
+		assert.Equal(t, models.RightCall, instrument.Right)
+		assert.Equal(t, "190", instrument.Strike.String())
+		assert.Equal(t, "100", instrument.Multiplier.String())
+	}
+
+	instrument, err := ParseOCC("SPXW  240621P05432500")
+	assert.NoError(t, err)
+	assert.Equal(t, models.RightPut, instrument.Right)
+	assert.Equal(t, "5432.5", instrument.Strike.String())
+}
+
+func TestParseOCC_Rejects(t *testing.T) {
+	for _, symbol := range []string{"AAPL", "", "TOOLONG240119C00190000", "AAPL  241319C00190000", "AAPL  240119X00190000", "AAPL  240119C0019000A", "BRK.B 240119C00190000"} {
+		_, err := ParseOCC(symbol)
+		assert.ErrorIs(t, err, ErrNotOCC, symbol)
+	}
+}
+
+func TestFormatOCC(t *testing.T) {
+	expiry := time.Date(2025, 3, 21, 0, 0, 0, 0, time.UTC)
+	symbol := FormatOCC(models.Instrument{Underlying: "F", Expiry: &expiry, Strike: decimal.RequireFromString("12.5"), Right: models.RightPut})
+	assert.Equal(t, "F     250321P00012500", symbol)
+}
+
+func TestLookup(t *testing.T) {
+	equity := Lookup(" msft ")
+	assert.Equal(t, "MSFT", equity.Symbol)
+	assert.Equal(t, models.AssetEquity, equity.AssetClass)
+	assert.Equal(t, "MSFT", equity.Underlying)
+	assert.Equal(t, "1", equity.Multiplier.String())
+
+	assert.Equal(t, "AAPL  240119C00190000", Normalize("AAPL240119C00190000"))
+}
+
+func TestMultiplier(t *testing.T) {
+	option := models.Execution{Symbol: "AAPL  240119C00190000"}
+	assert.Equal(t, "100", Multiplier(option).String())
+
+	option.Multiplier = decimal.NewFromInt(150)
+	assert.Equal(t, "150", Multiplier(option).String())
+
+	assert.Equal(t, "1", Multiplier(models.Execution{Symbol: "AAPL"}).String())
+}
+
+func TestCurrencyFor(t *testing.T) {
+	assert.Equal(t, "GBP", CurrencyFor("VOD.L", "EUR"))
+	assert.Equal(t, "JPY", CurrencyFor("7203.T", ""))
+	assert.Equal(t, "EUR", CurrencyFor("FDAXZ24", ""))
+	assert.Equal(t, "USD", CurrencyFor("SPY   240621P00500000", "CAD"))
+	assert.Equal(t, "CAD", CurrencyFor("SHOP", "CAD"))
+	assert.Equal(t, "USD", CurrencyFor("SHOP", ""))
+
+	assert.Equal(t, "GBX", Currency(models.Execution{Symbol: "VOD.L", Currency: "GBX"}))
+	assert.True(t, ValidCurrency("EUR"))
+	assert.False(t, ValidCurrency("eu"))
+}
@@ -0,0 +1,74 @@
+package instruments
+
+import "github.com/drewbuiltit/trading-journal/backend/internal/models"
+
+// Classify names the spread formed by a set of option contracts from their
+// rights, strikes and expiries. Legs on different underlyings, equity legs
+// and shapes it does not recognise are custom.
+func Classify(legs []models.Instrument) string {
+	if len(legs) < 2 {
+		return models.SpreadCustom
+	}
+	for _, leg := range legs {
+		if leg.AssetClass != models.AssetOption || leg.Expiry == nil || leg.Underlying != legs[0].Underlying {
+			return models.SpreadCustom
+		}
+	}
+
+	switch len(legs) {
+	case 2:
+		a, b := legs[0], legs[1]
+		sameExpiry := a.Expiry.Equal(*b.Expiry)
+		sameStrike := a.Strike.Equal(b.Strike)
+		if a.Right == b.Right {
+			switch {
+			case sameExpiry && !sameStrike:
+				return models.SpreadVertical
+			case !sameExpiry && sameStrike:
+				return models.SpreadCalendar
+			case !sameExpiry:
+				return models.SpreadDiagonal
+			}
+			return models.SpreadCustom
+		}
+		if sameExpiry && sameStrike {
+			return models.SpreadStraddle
+		}
+		if sameExpiry {
+			return models.SpreadStrangle
+		}
+	case 4:
+		if ironCondor(legs) {
+			return models.SpreadIronCondor
+		}
+	}
+
+	return models.SpreadCustom
+}
+
+// ironCondor reports whether four legs are a put vertical below a call
+// vertical in the same expiry.
+func ironCondor(legs []models.Instrument) bool {
+	var puts, calls []models.Instrument
+	for _, leg := range legs {
+		if !leg.Expiry.Equal(*legs[0].Expiry) {
+			return false
+		}
+		if leg.Right == models.RightPut {
+			puts = append(puts, leg)
+		} else {
+			calls = append(calls, leg)
+		}
+	}
+	if len(puts) != 2 || len(calls) != 2 {
+		return false
+	}
+	for _, put := range puts {
+		for _, call := range calls {
+			if !put.Strike.LessThan(call.Strike) {
+				return false
+			}
+		}
+	}
+	return !puts[0].Strike.Equal(puts[1].Strike) && !calls[0].Strike.Equal(calls[1].Strike)
+}
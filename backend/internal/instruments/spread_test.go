@@ -0,0 +1,45 @@
+package instruments
+
+import (
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func option(symbol string) models.Instrument {
+	instrument, err := ParseOCC(symbol)
+	if err != nil {
+		panic(err)
+	}
+	return instrument
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		legs []string
+		want string
+	}{
+		{"vertical", []string{"SPY   240621C00500000", "SPY   240621C00510000"}, models.SpreadVertical},
+		{"calendar", []string{"SPY   240621P00500000", "SPY   240719P00500000"}, models.SpreadCalendar},
+		{"diagonal", []string{"SPY   240621P00500000", "SPY   240719P00490000"}, models.SpreadDiagonal},
+		{"straddle", []string{"SPY   240621P00500000", "SPY   240621C00500000"}, models.SpreadStraddle},
+		{"strangle", []string{"SPY   240621P00490000", "SPY   240621C00510000"}, models.SpreadStrangle},
+		{"iron condor", []string{"SPY   240621P00480000", "SPY   240621P00490000", "SPY   240621C00510000", "SPY   240621C00520000"}, models.SpreadIronCondor},
+		{"overlapping wings", []string{"SPY   240621P00480000", "SPY   240621P00515000", "SPY   240621C00510000", "SPY   240621C00520000"}, models.SpreadCustom},
+		{"mixed underlyings", []string{"SPY   240621C00500000", "QQQ   240621C00440000"}, models.SpreadCustom},
+		{"single leg", []string{"SPY   240621C00500000"}, models.SpreadCustom},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			legs := []models.Instrument{}
+			for _, symbol := range tt.legs {
+				legs = append(legs, option(symbol))
+			}
+			assert.Equal(t, tt.want, Classify(legs))
+		})
+	}
+
+	assert.Equal(t, models.SpreadCustom, Classify([]models.Instrument{Lookup("SPY"), option("SPY   240621C00500000")}))
+}
@@ -0,0 +1,282 @@
+package lots
+
+import (
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/shopspring/decimal"
+	"sort"
+	"time"
+)
+
+const (
+	MethodFIFO        = "fifo"
+	MethodLIFO        = "lifo"
+	MethodHighestCost = "highest_cost"
+	MethodSpecific    = "specific"
+	MethodAverage     = "average"
+)
+
+const (
+	DirectionLong  = models.DirectionLong
+	DirectionShort = models.DirectionShort
+)
+
+// daysPerYear is the money-market convention brokers use to accrue borrow
+// and margin interest from an annual rate.
+var daysPerYear = decimal.NewFromInt(360)
+
+func ValidMethod(method string) bool {
+	switch method {
+	case MethodFIFO, MethodLIFO, MethodHighestCost, MethodSpecific, MethodAverage:
+		return true
+	}
+	return false
+}
+
+// Lot is an open slice of a position. Costs are the opening costs still
+// attached to the Remaining quantity; Multiplier converts a price move into
+// money and is one when unset.
+type Lot struct {
+	ExecutionID int             `json:"execution_id,omitempty"`
+	TradeID     int             `json:"trade_id,omitempty"`
+	Symbol      string          `json:"symbol"`
+	Direction   string          `json:"direction"`
+	OpenedAt    time.Time       `json:"opened_at"`
+	Quantity    decimal.Decimal `json:"quantity"`
+	Remaining   decimal.Decimal `json:"remaining"`
+	Price       decimal.Decimal `json:"price"`
+	Multiplier  decimal.Decimal `json:"multiplier"`
+	Costs
+}
+
+// Costs are the transaction costs attached to a fill or a slice of one.
+type Costs struct {
+	Commission decimal.Decimal `json:"commission"`
+	Fees       decimal.Decimal `json:"fees"`
+}
+
+// Portion returns the share of c that belongs to part out of whole.
+func (c Costs) Portion(part, whole decimal.Decimal) Costs {
+	if part.Equal(whole) {
+		return c
+	}
+	return Costs{
+		Commission: c.Commission.Mul(part).Div(whole),
+		Fees:       c.Fees.Mul(part).Div(whole),
+	}
+}
+
+func (c Costs) Plus(other Costs) Costs {
+	return Costs{Commission: c.Commission.Add(other.Commission), Fees: c.Fees.Add(other.Fees)}
+}
+
+func (c Costs) Minus(other Costs) Costs {
+	return Costs{Commission: c.Commission.Sub(other.Commission), Fees: c.Fees.Sub(other.Fees)}
+}
+
+func (c Costs) Total() decimal.Decimal {
+	return c.Commission.Add(c.Fees)
+}
+
+type Match struct {
+	Symbol           string          `json:"symbol"`
+	Direction        string          `json:"direction"`
+	OpenExecutionID  int             `json:"open_execution_id,omitempty"`
+	CloseExecutionID int             `json:"close_execution_id,omitempty"`
+	OpenTradeID      int             `json:"open_trade_id,omitempty"`
+	CloseTradeID     int             `json:"close_trade_id,omitempty"`
+	OpenedAt         time.Time       `json:"opened_at"`
+	ClosedAt         time.Time       `json:"closed_at"`
+	Quantity         decimal.Decimal `json:"quantity"`
+	CostPrice        decimal.Decimal `json:"cost_price"`
+	ClosePrice       decimal.Decimal `json:"close_price"`
+	Multiplier       decimal.Decimal `json:"multiplier"`
+	HoldingSeconds   int64           `json:"holding_seconds"`
+	Costs
+	// BorrowCost is the borrow fee on a short lot or the margin interest on
+	// a long one.
+	BorrowCost decimal.Decimal `json:"borrow_cost"`
+	GrossPnL   decimal.Decimal `json:"gross_pnl"`
+	NetPnL     decimal.Decimal `json:"net_pnl"`
+	// FXRate converted the P&L into the base currency on the close date.
+	FXRate       decimal.Decimal `json:"fx_rate"`
+	BaseGrossPnL decimal.Decimal `json:"base_gross_pnl"`
+	BaseNetPnL   decimal.Decimal `json:"base_net_pnl"`
+}
+
+// Book holds the open lots of a single symbol and direction.
+type Book struct {
+	config     Config
+	selections map[int][]models.LotSelection
+	lots       []*Lot
+}
+
+func NewBook(config Config) *Book {
+	selections := make(map[int][]models.LotSelection)
+	for _, selection := range config.Selections {
+		selections[selection.CloseExecutionID] = append(selections[selection.CloseExecutionID], selection)
+	}
+	return &Book{config: config, selections: selections}
+}
+
+func (b *Book) Open(lot Lot) {
+	lot.Remaining = lot.Quantity
+	if !lot.Multiplier.IsPositive() {
+		lot.Multiplier = decimal.NewFromInt(1)
+	}
+	b.lots = append(b.lots, &lot)
+}
+
+func (b *Book) Remaining() decimal.Decimal {
+	total := decimal.Zero
+	for _, lot := range b.lots {
+		total = total.Add(lot.Remaining)
+	}
+	return total
+}
+
+func (b *Book) OpenLots() []Lot {
+	open := make([]Lot, 0, len(b.lots))
+	for _, lot := range b.lots {
+		open = append(open, *lot)
+	}
+	return open
+}
+
+// Close matches quantity units of a closing execution against the open lots
+// using the configured method. costs is the share of the execution's costs
+// that belongs to this quantity; it is allocated across the matches so that
+// they add back up to it exactly.
+func (b *Book) Close(execution models.Execution, quantity decimal.Decimal, costs Costs) []Match {
+	if b.config.Method == MethodAverage {
+		b.averageCost()
+	}
+
+	matches := []Match{}
+	remaining := quantity
+	consume := func(lot *Lot, amount decimal.Decimal) {
+		amount = decimal.Min(amount, lot.Remaining, remaining)
+		if !amount.IsPositive() {
+			return
+		}
+
+		openCosts := lot.Costs.Portion(amount, lot.Remaining)
+		closeCosts := costs.Portion(amount, remaining)
+		matchCosts := openCosts.Plus(closeCosts)
+
+		gross := execution.Price.Sub(lot.Price).Mul(amount).Mul(lot.Multiplier)
+		rate := b.config.MarginRate
+		if lot.Direction == DirectionShort {
+			gross = gross.Neg()
+			rate = b.config.BorrowRate
+		}
+		borrow := amount.Mul(lot.Price).Mul(lot.Multiplier).Mul(rate).
+			Mul(nightsHeld(lot.OpenedAt, execution.ExecutedAt)).Div(daysPerYear)
+
+		matches = append(matches, Match{
+			Symbol:           lot.Symbol,
+			Direction:        lot.Direction,
+			OpenExecutionID:  lot.ExecutionID,
+			CloseExecutionID: execution.ID,
+			OpenTradeID:      lot.TradeID,
+			CloseTradeID:     execution.TradeID,
+			OpenedAt:         lot.OpenedAt,
+			ClosedAt:         execution.ExecutedAt,
+			Quantity:         amount,
+			CostPrice:        lot.Price,
+			ClosePrice:       execution.Price,
+			Multiplier:       lot.Multiplier,
+			HoldingSeconds:   int64(execution.ExecutedAt.Sub(lot.OpenedAt).Seconds()),
+			Costs:            matchCosts,
+			BorrowCost:       borrow,
+			GrossPnL:         gross,
+			NetPnL:           gross.Sub(matchCosts.Total()).Sub(borrow),
+		})
+
+		lot.Costs = lot.Costs.Minus(openCosts)
+		lot.Remaining = lot.Remaining.Sub(amount)
+		costs = costs.Minus(closeCosts)
+		remaining = remaining.Sub(amount)
+	}
+
+	if b.config.Method == MethodSpecific && execution.ID != 0 {
+		for _, selection := range b.selections[execution.ID] {
+			for _, lot := range b.lots {
+				if lot.ExecutionID != 0 && lot.ExecutionID == selection.OpenExecutionID {
+					consume(lot, selection.Quantity)
+				}
+			}
+		}
+	}
+
+	for _, lot := range b.ordered() {
+		if !remaining.IsPositive() {
+			break
+		}
+		consume(lot, remaining)
+	}
+
+	b.prune()
+	return matches
+}
+
+// ordered returns the open lots in the order the method consumes them.
+// Specific identification falls back to FIFO for any unselected quantity.
+func (b *Book) ordered() []*Lot {
+	ordered := make([]*Lot, len(b.lots))
+	copy(ordered, b.lots)
+
+	switch b.config.Method {
+	case MethodLIFO:
+		for i, j := 0, len(ordered)-1; i < j; i, j = i+1, j-1 {
+			ordered[i], ordered[j] = ordered[j], ordered[i]
+		}
+	case MethodHighestCost:
+		// Highest cost minimises the realized gain: the most expensive long
+		// lots and the cheapest short lots go first.
+		sort.SliceStable(ordered, func(i, j int) bool {
+			if ordered[i].Direction == DirectionShort {
+				return ordered[i].Price.LessThan(ordered[j].Price)
+			}
+			return ordered[i].Price.GreaterThan(ordered[j].Price)
+		})
+	}
+
+	return ordered
+}
+
+func (b *Book) averageCost() {
+	quantity, cost := decimal.Zero, decimal.Zero
+	for _, lot := range b.lots {
+		quantity = quantity.Add(lot.Remaining)
+		cost = cost.Add(lot.Remaining.Mul(lot.Price))
+	}
+	if !quantity.IsPositive() {
+		return
+	}
+	for _, lot := range b.lots {
+		lot.Price = cost.Div(quantity)
+	}
+}
+
+func (b *Book) prune() {
+	open := b.lots[:0]
+	for _, lot := range b.lots {
+		if lot.Remaining.IsPositive() {
+			open = append(open, lot)
+		}
+	}
+	b.lots = open
+}
+
+// nightsHeld counts the calendar dates a lot was carried past, which is how
+// brokers accrue daily borrow and margin charges; intraday lots accrue
+// nothing.
+func nightsHeld(openedAt, closedAt time.Time) decimal.Decimal {
+	open := time.Date(openedAt.Year(), openedAt.Month(), openedAt.Day(), 0, 0, 0, 0, time.UTC)
+	closed := time.Date(closedAt.Year(), closedAt.Month(), closedAt.Day(), 0, 0, 0, 0, time.UTC)
+	nights := int64(closed.Sub(open).Hours() / 24)
+	if nights < 0 {
+		return decimal.Zero
+	}
+	return decimal.NewFromInt(nights)
+}
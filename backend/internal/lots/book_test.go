@@ -0,0 +1,172 @@
+package lots
+
+import (
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var start = time.Date(2024, 5, 1, 14, 30, 0, 0, time.UTC)
+
+func openTestBook(config Config) *Book {
+	book := NewBook(config)
+	for i, price := range []string{"10", "14", "12"} {
+		book.Open(Lot{
+			ExecutionID: i + 1,
+			Symbol:      "AAPL",
+			Direction:   DirectionLong,
+			OpenedAt:    start.Add(time.Duration(i) * time.Hour),
+			Quantity:    dec("100"),
+			Price:       dec(price),
+		})
+	}
+	return book
+}
+
+func dec(value string) decimal.Decimal {
+	return decimal.RequireFromString(value)
+}
+
+func closing(id int, quantity, price string) models.Execution {
+	return models.Execution{
+		ID:         id,
+		Symbol:     "AAPL",
+		Side:       models.SideSell,
+		Quantity:   dec(quantity),
+		Price:      dec(price),
+		ExecutedAt: start.Add(24 * time.Hour),
+	}
+}
+
+func TestBook_Methods(t *testing.T) {
+	tests := []struct {
+		method   string
+		openIDs  []int
+		realized string
+	}{
+		{MethodFIFO, []int{1, 2}, "550"},
+		{MethodLIFO, []int{3, 2}, "350"},
+		{MethodHighestCost, []int{2, 3}, "250"},
+		{MethodAverage, []int{1, 2}, "450"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			book := openTestBook(Config{Method: tt.method})
+			matches := book.Close(closing(4, "150", "15"), dec("150"), Costs{})
+
+			ids := []int{}
+			realized := decimal.Zero
+			for _, match := range matches {
+				ids = append(ids, match.OpenExecutionID)
+				realized = realized.Add(match.NetPnL)
+			}
+
+			assert.Equal(t, tt.openIDs, ids)
+			assert.Equal(t, tt.realized, realized.String())
+			assert.Equal(t, "150", book.Remaining().String())
+		})
+	}
+}
+
+func TestBook_AverageCostCarriesToRemainingLots(t *testing.T) {
+	book := openTestBook(Config{Method: MethodAverage})
+	book.Close(closing(4, "150", "15"), dec("150"), Costs{})
+
+	for _, lot := range book.OpenLots() {
+		assert.Equal(t, "12", lot.Price.String())
+	}
+}
+
+func TestBook_SpecificIdentification(t *testing.T) {
+	book := openTestBook(Config{
+		Method: MethodSpecific,
+		Selections: []models.LotSelection{
+			{CloseExecutionID: 4, OpenExecutionID: 3, Quantity: dec("60")},
+		},
+	})
+
+	matches := book.Close(closing(4, "100", "15"), dec("100"), Costs{})
+	assert.Len(t, matches, 2)
+	assert.Equal(t, 3, matches[0].OpenExecutionID)
+	assert.Equal(t, "60", matches[0].Quantity.String())
+	assert.Equal(t, 1, matches[1].OpenExecutionID, "Unselected quantity should fall back to FIFO")
+	assert.Equal(t, "40", matches[1].Quantity.String())
+}
+
+func TestBook_FeesAreAllocatedPerMatch(t *testing.T) {
+	book := NewBook(Config{})
+	book.Open(Lot{ExecutionID: 1, Symbol: "AAPL", Direction: DirectionShort, OpenedAt: start, Quantity: dec("100"), Price: dec("20"), Costs: Costs{Fees: dec("2")}})
+
+	matches := book.Close(models.Execution{ID: 2, Symbol: "AAPL", Side: models.SideBuy, Quantity: dec("50"), Price: dec("18"), ExecutedAt: start.Add(time.Hour)}, dec("50"), Costs{Fees: dec("1")})
+	assert.Len(t, matches, 1)
+	assert.Equal(t, "100", matches[0].GrossPnL.String())
+	assert.Equal(t, "2", matches[0].Fees.String())
+	assert.Equal(t, "98", matches[0].NetPnL.String())
+	assert.Equal(t, int64(3600), matches[0].HoldingSeconds)
+}
+
+func TestBook_BorrowCostAccruesOvernight(t *testing.T) {
+	book := NewBook(Config{Rates: Rates{BorrowRate: dec("0.036")}})
+	book.Open(Lot{ExecutionID: 1, Symbol: "GME", Direction: DirectionShort, OpenedAt: start, Quantity: dec("100"), Price: dec("20")})
+
+	cover := models.Execution{ID: 2, Symbol: "GME", Side: models.SideBuy, Quantity: dec("100"), Price: dec("15"), ExecutedAt: start.Add(72 * time.Hour)}
+	matches := book.Close(cover, dec("100"), Costs{Commission: dec("1")})
+
+	assert.Len(t, matches, 1)
+	assert.Equal(t, "500", matches[0].GrossPnL.String())
+	assert.Equal(t, "0.6", matches[0].BorrowCost.String())
+	assert.Equal(t, "498.4", matches[0].NetPnL.String())
+}
+
+func TestBook_IntradayShortAccruesNoBorrow(t *testing.T) {
+	book := NewBook(Config{Rates: Rates{BorrowRate: dec("0.5")}})
+	book.Open(Lot{ExecutionID: 1, Symbol: "GME", Direction: DirectionShort, OpenedAt: start, Quantity: dec("100"), Price: dec("20")})
+
+	cover := models.Execution{ID: 2, Symbol: "GME", Side: models.SideBuy, Quantity: dec("100"), Price: dec("21"), ExecutedAt: start.Add(time.Hour)}
+	matches := book.Close(cover, dec("100"), Costs{})
+
+	assert.True(t, matches[0].BorrowCost.IsZero())
+	assert.Equal(t, "-100", matches[0].NetPnL.String())
+}
+
+func TestBook_MarginInterestAccruesOnLongs(t *testing.T) {
+	book := NewBook(Config{Rates: Rates{BorrowRate: dec("0.5"), MarginRate: dec("0.072")}})
+	book.Open(Lot{ExecutionID: 1, Symbol: "AAPL", Direction: DirectionLong, OpenedAt: start, Quantity: dec("100"), Price: dec("150")})
+
+	matches := book.Close(closing(2, "100", "160"), dec("100"), Costs{})
+	assert.Equal(t, "1000", matches[0].GrossPnL.String())
+	assert.Equal(t, "3", matches[0].BorrowCost.String(), "one night of margin interest, not the borrow rate")
+	assert.Equal(t, "997", matches[0].NetPnL.String())
+}
+
+func TestConfigForUser_AccountRates(t *testing.T) {
+	s := store.NewMemoryStore()
+	assert.NoError(t, s.CreateUser(&models.User{Username: "jane_doe", Email: "jane@example.com"}))
+	margin, cash := 1, 2
+	assert.NoError(t, s.SaveFeeSchedule(&models.FeeSchedule{UserID: 1, BorrowRate: dec("0.03")}))
+	assert.NoError(t, s.SaveFeeSchedule(&models.FeeSchedule{UserID: 1, AccountID: &margin, BorrowRate: dec("0.05"), MarginRate: dec("0.08")}))
+
+	config, err := ConfigForUser(s, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.08", config.ForAccount(&margin).MarginRate.String())
+	assert.Equal(t, "0.05", config.ForAccount(&margin).BorrowRate.String())
+	assert.Equal(t, "0.03", config.ForAccount(&cash).BorrowRate.String(), "accounts without a schedule use the default")
+	assert.True(t, config.ForAccount(nil).MarginRate.IsZero())
+}
+
+func TestBook_MultiplierScalesPnL(t *testing.T) {
+	book := NewBook(Config{})
+	book.Open(Lot{ExecutionID: 1, Symbol: "AAPL  240119C00190000", Direction: DirectionLong, OpenedAt: start, Quantity: dec("2"), Price: dec("3.10"), Multiplier: dec("100")})
+
+	sell := models.Execution{ID: 2, Symbol: "AAPL  240119C00190000", Side: models.SideSell, Quantity: dec("2"), Price: dec("4.25"), ExecutedAt: start.Add(time.Hour)}
+	matches := book.Close(sell, dec("2"), Costs{Commission: dec("1.30")})
+
+	assert.Len(t, matches, 1)
+	assert.Equal(t, "100", matches[0].Multiplier.String())
+	assert.Equal(t, "230", matches[0].GrossPnL.String())
+	assert.Equal(t, "228.7", matches[0].NetPnL.String())
+}
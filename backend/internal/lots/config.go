@@ -0,0 +1,74 @@
+package lots
+
+import (
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"github.com/shopspring/decimal"
+)
+
+// Config selects how closing fills are matched against open lots. An empty
+// Method behaves like FIFO. Accounts overrides the financing rates for the
+// accounts that have their own fee schedule.
+type Config struct {
+	Method     string
+	Selections []models.LotSelection
+	Rates
+	Accounts map[int]Rates
+}
+
+// Rates are the annual financing rates: BorrowRate is charged on short lots
+// and MarginRate on long ones.
+type Rates struct {
+	BorrowRate decimal.Decimal
+	MarginRate decimal.Decimal
+}
+
+// ForAccount returns the config with the account's own rates, if it has
+// any.
+func (c Config) ForAccount(accountID *int) Config {
+	if accountID != nil {
+		if rates, ok := c.Accounts[*accountID]; ok {
+			c.Rates = rates
+		}
+	}
+	return c
+}
+
+func MethodOf(user *models.User) string {
+	if user == nil || user.LotMethod == "" {
+		return MethodFIFO
+	}
+	return user.LotMethod
+}
+
+// ConfigForUser loads the user's lot method, the financing rates of their
+// fee schedules and, for specific identification, their lot selections.
+func ConfigForUser(s store.Store, userID int) (Config, error) {
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return Config{}, err
+	}
+
+	config := Config{Method: MethodOf(user), Accounts: make(map[int]Rates)}
+
+	schedules, err := s.ListFeeSchedules(userID)
+	if err != nil {
+		return Config{}, err
+	}
+	for _, schedule := range schedules {
+		rates := Rates{BorrowRate: schedule.BorrowRate, MarginRate: schedule.MarginRate}
+		if schedule.AccountID == nil {
+			config.Rates = rates
+		} else {
+			config.Accounts[*schedule.AccountID] = rates
+		}
+	}
+	if config.Method == MethodSpecific {
+		config.Selections, err = s.ListLotSelections(userID)
+		if err != nil {
+			return Config{}, err
+		}
+	}
+
+	return config, nil
+}
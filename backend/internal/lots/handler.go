@@ -0,0 +1,166 @@
+package lots
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/drewbuiltit/trading-journal/backend/internal/accounts"
+	"github.com/drewbuiltit/trading-journal/backend/internal/auth"
+	"github.com/drewbuiltit/trading-journal/backend/internal/corporate"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"github.com/gorilla/mux"
+	"github.com/shopspring/decimal"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type LotHandler struct {
+	Store store.Store
+}
+
+type MethodRequest struct {
+	Method string `json:"method"`
+}
+
+type MethodResponse struct {
+	Method string `json:"method"`
+}
+
+type SelectionRequest struct {
+	OpenExecutionID int             `json:"open_execution_id"`
+	Quantity        decimal.Decimal `json:"quantity"`
+}
+
+func (h *LotHandler) GetMethod(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	user, err := h.Store.GetUserByID(userID)
+	if err != nil {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(MethodResponse{Method: MethodOf(user)})
+}
+
+func (h *LotHandler) SetMethod(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	var req MethodRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	method := strings.ToLower(req.Method)
+	if !ValidMethod(method) {
+		http.Error(w, "Unsupported lot method", http.StatusBadRequest)
+		return
+	}
+
+	err := h.Store.UpdateLotMethod(userID, method)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error updating lot method", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(MethodResponse{Method: method})
+}
+
+// SetSelections replaces the lots a closing execution is matched against when
+// the user's method is specific identification.
+func (h *LotHandler) SetSelections(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	executionID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid execution ID", http.StatusBadRequest)
+		return
+	}
+
+	var req []SelectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	closing, err := h.Store.GetExecution(userID, executionID)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "Execution not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error fetching execution", http.StatusInternalServerError)
+		return
+	}
+
+	// Lots are matched in the terms of the shares after any split or rename,
+	// so the fills are compared, and quantities given, in those terms too.
+	actions, err := h.Store.ListCorporateActions(userID)
+	if err != nil {
+		http.Error(w, "Error listing corporate actions", http.StatusInternalServerError)
+		return
+	}
+	adjustedClosing := corporate.Apply([]models.Execution{*closing}, actions)[0]
+
+	selections := make([]models.LotSelection, 0, len(req))
+	total := decimal.Zero
+	for _, item := range req {
+		if !item.Quantity.IsPositive() {
+			http.Error(w, "quantity must be positive", http.StatusBadRequest)
+			return
+		}
+
+		opening, err := h.Store.GetExecution(userID, item.OpenExecutionID)
+		if err != nil {
+			http.Error(w, "Open execution not found", http.StatusBadRequest)
+			return
+		}
+		adjustedOpening := corporate.Apply([]models.Execution{*opening}, actions)[0]
+		if adjustedOpening.Symbol != adjustedClosing.Symbol || opening.Side == closing.Side || !opening.ExecutedAt.Before(closing.ExecutedAt) ||
+			!accounts.Same(opening.AccountID, closing.AccountID) {
+			http.Error(w, "Open execution cannot be closed by this execution", http.StatusBadRequest)
+			return
+		}
+
+		total = total.Add(item.Quantity)
+		selections = append(selections, models.LotSelection{
+			UserID:           userID,
+			CloseExecutionID: closing.ID,
+			OpenExecutionID:  opening.ID,
+			Quantity:         item.Quantity,
+		})
+	}
+
+	if total.GreaterThan(adjustedClosing.Quantity) {
+		http.Error(w, "Selected quantity exceeds execution quantity", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Store.ReplaceLotSelections(userID, closing.ID, selections); err != nil {
+		http.Error(w, "Error saving lot selections", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(selections)
+}
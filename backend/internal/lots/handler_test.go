@@ -0,0 +1,109 @@
+package lots
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/drewbuiltit/trading-journal/backend/internal/auth"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func setupTestLotHandler(t *testing.T) *LotHandler {
+	lotHandler := &LotHandler{Store: store.NewMemoryStore()}
+	assert.NoError(t, lotHandler.Store.CreateUser(&models.User{Username: "jane_doe", Email: "jane@example.com"}))
+	return lotHandler
+}
+
+func newLotRequest(t *testing.T, method, url string, body []byte, vars map[string]string) *http.Request {
+	req, err := http.NewRequest(method, url, bytes.NewBuffer(body))
+	assert.NoError(t, err)
+	req = req.WithContext(context.WithValue(req.Context(), auth.UserContextKey, 1))
+	if vars != nil {
+		req = mux.SetURLVars(req, vars)
+	}
+	return req
+}
+
+func TestLotMethodHandlers(t *testing.T) {
+	lotHandler := setupTestLotHandler(t)
+
+	t.Run("Defaults to FIFO", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		lotHandler.GetMethod(rr, newLotRequest(t, "GET", "/lots/method", nil, nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var response MethodResponse
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, MethodFIFO, response.Method)
+	})
+
+	t.Run("Successful Update", func(t *testing.T) {
+		body, _ := json.Marshal(MethodRequest{Method: "LIFO"})
+
+		rr := httptest.NewRecorder()
+		lotHandler.SetMethod(rr, newLotRequest(t, "PUT", "/lots/method", body, nil))
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		user, err := lotHandler.Store.GetUserByID(1)
+		assert.NoError(t, err)
+		assert.Equal(t, MethodLIFO, user.LotMethod)
+	})
+
+	t.Run("Unsupported Method", func(t *testing.T) {
+		body, _ := json.Marshal(MethodRequest{Method: "random"})
+
+		rr := httptest.NewRecorder()
+		lotHandler.SetMethod(rr, newLotRequest(t, "PUT", "/lots/method", body, nil))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, "Unsupported lot method\n", rr.Body.String())
+	})
+}
+
+func TestSetSelectionsHandler(t *testing.T) {
+	lotHandler := setupTestLotHandler(t)
+
+	for _, execution := range []*models.Execution{
+		{UserID: 1, Symbol: "AAPL", Side: models.SideBuy, Quantity: dec("100"), Price: dec("10"), ExecutedAt: start},
+		{UserID: 1, Symbol: "MSFT", Side: models.SideBuy, Quantity: dec("100"), Price: dec("10"), ExecutedAt: start},
+		{UserID: 1, Symbol: "AAPL", Side: models.SideSell, Quantity: dec("50"), Price: dec("12"), ExecutedAt: start.Add(time.Hour)},
+	} {
+		assert.NoError(t, lotHandler.Store.CreateExecution(execution))
+	}
+	vars := map[string]string{"id": "3"}
+
+	t.Run("Successful Selection", func(t *testing.T) {
+		body, _ := json.Marshal([]SelectionRequest{{OpenExecutionID: 1, Quantity: dec("50")}})
+
+		rr := httptest.NewRecorder()
+		lotHandler.SetSelections(rr, newLotRequest(t, "PUT", "/executions/3/lots", body, vars))
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		selections, err := lotHandler.Store.ListLotSelections(1)
+		assert.NoError(t, err)
+		assert.Len(t, selections, 1)
+	})
+
+	t.Run("Mismatched Symbol", func(t *testing.T) {
+		body, _ := json.Marshal([]SelectionRequest{{OpenExecutionID: 2, Quantity: dec("50")}})
+
+		rr := httptest.NewRecorder()
+		lotHandler.SetSelections(rr, newLotRequest(t, "PUT", "/executions/3/lots", body, vars))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Quantity Exceeds Execution", func(t *testing.T) {
+		body, _ := json.Marshal([]SelectionRequest{{OpenExecutionID: 1, Quantity: dec("80")}})
+
+		rr := httptest.NewRecorder()
+		lotHandler.SetSelections(rr, newLotRequest(t, "PUT", "/executions/3/lots", body, vars))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, "Selected quantity exceeds execution quantity\n", rr.Body.String())
+	})
+}
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS executions;
\ No newline at end of file
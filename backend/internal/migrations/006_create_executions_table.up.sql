@@ -0,0 +1,13 @@
+CREATE TABLE executions
+(
+    id          SERIAL PRIMARY KEY,
+    user_id     INT         NOT NULL REFERENCES users (id),
+    symbol      VARCHAR(50) NOT NULL,
+    side        VARCHAR(4)  NOT NULL CHECK (side IN ('buy', 'sell')),
+    quantity    DECIMAL     NOT NULL CHECK (quantity > 0),
+    price       DECIMAL     NOT NULL,
+    fees        DECIMAL     NOT NULL DEFAULT 0,
+    executed_at TIMESTAMP   NOT NULL,
+    created_at  TIMESTAMP DEFAULT NOW()
+);
+CREATE INDEX idx_executions_user_symbol ON executions (user_id, symbol, executed_at);
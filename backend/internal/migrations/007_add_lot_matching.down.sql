@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS lot_selections;
+ALTER TABLE users DROP COLUMN IF EXISTS lot_method;
\ No newline at end of file
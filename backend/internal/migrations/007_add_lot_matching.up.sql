@@ -0,0 +1,12 @@
+ALTER TABLE users
+    ADD COLUMN lot_method VARCHAR(20) NOT NULL DEFAULT 'fifo';
+
+CREATE TABLE lot_selections
+(
+    id                 SERIAL PRIMARY KEY,
+    user_id            INT     NOT NULL REFERENCES users (id),
+    close_execution_id INT     NOT NULL REFERENCES executions (id) ON DELETE CASCADE,
+    open_execution_id  INT     NOT NULL REFERENCES executions (id) ON DELETE CASCADE,
+    quantity           DECIMAL NOT NULL CHECK (quantity > 0)
+);
+CREATE INDEX idx_lot_selections_user ON lot_selections (user_id, close_execution_id);
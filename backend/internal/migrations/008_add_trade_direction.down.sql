@@ -0,0 +1,15 @@
+ALTER TABLE executions
+    DROP COLUMN IF EXISTS action;
+
+ALTER TABLE trades
+    DROP CONSTRAINT IF EXISTS chk_trades_quantity,
+    DROP CONSTRAINT IF EXISTS chk_trades_action,
+    DROP CONSTRAINT IF EXISTS chk_trades_direction;
+
+UPDATE trades
+SET quantity = -quantity
+WHERE action IN ('sell', 'sell_short');
+
+ALTER TABLE trades
+    DROP COLUMN IF EXISTS action,
+    DROP COLUMN IF EXISTS direction;
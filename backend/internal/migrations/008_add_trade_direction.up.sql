@@ -0,0 +1,16 @@
+ALTER TABLE trades
+    ADD COLUMN direction VARCHAR(5)  NOT NULL DEFAULT 'long',
+    ADD COLUMN action    VARCHAR(12) NOT NULL DEFAULT 'buy';
+
+UPDATE trades
+SET action   = 'sell',
+    quantity = -quantity
+WHERE quantity < 0;
+
+ALTER TABLE trades
+    ADD CONSTRAINT chk_trades_direction CHECK (direction IN ('long', 'short')),
+    ADD CONSTRAINT chk_trades_action CHECK (action IN ('buy', 'sell', 'sell_short', 'buy_to_cover')),
+    ADD CONSTRAINT chk_trades_quantity CHECK (quantity > 0);
+
+ALTER TABLE executions
+    ADD COLUMN action VARCHAR(12) CHECK (action IN ('buy', 'sell', 'sell_short', 'buy_to_cover'));
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS fee_schedules;
+
+ALTER TABLE trades
+    DROP COLUMN IF EXISTS fees,
+    DROP COLUMN IF EXISTS commission;
+
+ALTER TABLE executions
+    DROP COLUMN IF EXISTS commission;
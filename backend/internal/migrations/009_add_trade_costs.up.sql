@@ -0,0 +1,18 @@
+ALTER TABLE executions
+    ADD COLUMN commission DECIMAL NOT NULL DEFAULT 0;
+
+ALTER TABLE trades
+    ADD COLUMN commission DECIMAL NOT NULL DEFAULT 0,
+    ADD COLUMN fees       DECIMAL NOT NULL DEFAULT 0;
+
+CREATE TABLE fee_schedules
+(
+    id                   SERIAL PRIMARY KEY,
+    user_id              INT     NOT NULL UNIQUE REFERENCES users (id),
+    commission_per_share DECIMAL NOT NULL DEFAULT 0,
+    commission_per_order DECIMAL NOT NULL DEFAULT 0,
+    commission_minimum   DECIMAL NOT NULL DEFAULT 0,
+    regulatory_fee_rate  DECIMAL NOT NULL DEFAULT 0,
+    borrow_rate          DECIMAL NOT NULL DEFAULT 0,
+    margin_rate          DECIMAL NOT NULL DEFAULT 0
+);
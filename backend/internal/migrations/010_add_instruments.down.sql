@@ -0,0 +1,9 @@
+ALTER TABLE trades
+    DROP COLUMN IF EXISTS spread_id,
+    DROP COLUMN IF EXISTS multiplier;
+
+ALTER TABLE executions
+    DROP COLUMN IF EXISTS spread_id,
+    DROP COLUMN IF EXISTS multiplier;
+
+DROP TABLE IF EXISTS spreads;
@@ -0,0 +1,26 @@
+CREATE TABLE spreads
+(
+    id         SERIAL PRIMARY KEY,
+    user_id    INT          NOT NULL REFERENCES users (id),
+    name       VARCHAR(100) NOT NULL,
+    kind       VARCHAR(20),
+    created_at TIMESTAMP DEFAULT NOW()
+);
+CREATE INDEX idx_spreads_user ON spreads (user_id);
+
+ALTER TABLE executions
+    ADD COLUMN multiplier DECIMAL NOT NULL DEFAULT 1,
+    ADD COLUMN spread_id  INT REFERENCES spreads (id) ON DELETE SET NULL;
+
+ALTER TABLE trades
+    ADD COLUMN multiplier DECIMAL NOT NULL DEFAULT 1,
+    ADD COLUMN spread_id  INT REFERENCES spreads (id) ON DELETE SET NULL;
+
+-- Option contracts already recorded under OCC symbols control 100 shares.
+UPDATE executions
+SET multiplier = 100
+WHERE symbol ~ '^[A-Z0-9]{1,6} *[0-9]{6}[CP][0-9]{8}$';
+
+UPDATE trades
+SET multiplier = 100
+WHERE symbol ~ '^[A-Z0-9]{1,6} *[0-9]{6}[CP][0-9]{8}$';
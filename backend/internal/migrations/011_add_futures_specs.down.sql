@@ -0,0 +1,7 @@
+UPDATE executions
+SET multiplier = 1
+WHERE multiplier = 0;
+
+UPDATE trades
+SET multiplier = 1
+WHERE multiplier = 0;
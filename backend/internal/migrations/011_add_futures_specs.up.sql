@@ -0,0 +1,11 @@
+-- Futures contracts recorded before the spec registry were stored with the
+-- default multiplier of one. Clear it so the registry supplies the point value.
+UPDATE executions
+SET multiplier = 0
+WHERE multiplier = 1
+  AND symbol ~ '^/?[A-Z0-9]{1,4}[FGHJKMNQUVXZ][0-9]{1,2}$';
+
+UPDATE trades
+SET multiplier = 0
+WHERE multiplier = 1
+  AND symbol ~ '^/?[A-Z0-9]{1,4}[FGHJKMNQUVXZ][0-9]{1,2}$';
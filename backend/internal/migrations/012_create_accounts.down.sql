@@ -0,0 +1,15 @@
+DROP INDEX IF EXISTS idx_fee_schedules_user_account;
+
+DELETE FROM fee_schedules WHERE account_id IS NOT NULL;
+
+ALTER TABLE fee_schedules
+    DROP COLUMN IF EXISTS account_id,
+    ADD CONSTRAINT fee_schedules_user_id_key UNIQUE (user_id);
+
+ALTER TABLE executions
+    DROP COLUMN IF EXISTS account_id;
+
+ALTER TABLE trades
+    DROP COLUMN IF EXISTS account_id;
+
+DROP TABLE IF EXISTS accounts;
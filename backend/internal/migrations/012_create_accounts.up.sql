@@ -0,0 +1,28 @@
+CREATE TABLE accounts
+(
+    id         SERIAL PRIMARY KEY,
+    user_id    INT          NOT NULL REFERENCES users (id),
+    name       VARCHAR(100) NOT NULL,
+    broker     VARCHAR(100),
+    number     VARCHAR(50),
+    type       VARCHAR(20),
+    created_at TIMESTAMP DEFAULT NOW()
+);
+CREATE INDEX idx_accounts_user ON accounts (user_id);
+
+ALTER TABLE trades
+    ADD COLUMN account_id INT REFERENCES accounts (id) ON DELETE SET NULL;
+
+ALTER TABLE executions
+    ADD COLUMN account_id INT REFERENCES accounts (id) ON DELETE SET NULL;
+
+CREATE INDEX idx_trades_account ON trades (account_id);
+CREATE INDEX idx_executions_account ON executions (account_id);
+
+-- Each account may have its own fee schedule; the one without an account is
+-- the user's default.
+ALTER TABLE fee_schedules
+    DROP CONSTRAINT IF EXISTS fee_schedules_user_id_key,
+    ADD COLUMN account_id INT REFERENCES accounts (id) ON DELETE CASCADE;
+
+CREATE UNIQUE INDEX idx_fee_schedules_user_account ON fee_schedules (user_id, COALESCE(account_id, 0));
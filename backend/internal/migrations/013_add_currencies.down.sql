@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS fx_rates;
+
+ALTER TABLE executions
+    DROP COLUMN IF EXISTS currency;
+
+ALTER TABLE trades
+    DROP COLUMN IF EXISTS currency;
+
+ALTER TABLE accounts
+    DROP COLUMN IF EXISTS currency;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS base_currency;
@@ -0,0 +1,23 @@
+ALTER TABLE users
+    ADD COLUMN base_currency VARCHAR(3) NOT NULL DEFAULT '';
+
+ALTER TABLE accounts
+    ADD COLUMN currency VARCHAR(3) NOT NULL DEFAULT '';
+
+-- An empty currency is derived from the symbol when positions are built.
+ALTER TABLE trades
+    ADD COLUMN currency VARCHAR(3) NOT NULL DEFAULT '';
+
+ALTER TABLE executions
+    ADD COLUMN currency VARCHAR(3) NOT NULL DEFAULT '';
+
+CREATE TABLE fx_rates
+(
+    id       SERIAL PRIMARY KEY,
+    user_id  INT        NOT NULL REFERENCES users (id),
+    date     DATE       NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    quote    VARCHAR(3) NOT NULL,
+    rate     DECIMAL    NOT NULL CHECK (rate > 0)
+);
+CREATE UNIQUE INDEX idx_fx_rates_key ON fx_rates (user_id, date, currency, quote);
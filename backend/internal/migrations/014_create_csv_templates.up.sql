@@ -0,0 +1,19 @@
+CREATE TABLE csv_templates
+(
+    id                SERIAL PRIMARY KEY,
+    user_id           INT          NOT NULL REFERENCES users (id),
+    name              VARCHAR(100) NOT NULL,
+    symbol_column     VARCHAR(100) NOT NULL,
+    side_column       VARCHAR(100) NOT NULL DEFAULT '',
+    quantity_column   VARCHAR(100) NOT NULL,
+    price_column      VARCHAR(100) NOT NULL,
+    date_column       VARCHAR(100) NOT NULL,
+    time_column       VARCHAR(100) NOT NULL DEFAULT '',
+    commission_column VARCHAR(100) NOT NULL DEFAULT '',
+    fees_column       VARCHAR(100) NOT NULL DEFAULT '',
+    currency_column   VARCHAR(100) NOT NULL DEFAULT '',
+    date_format       VARCHAR(50)  NOT NULL,
+    time_format       VARCHAR(50)  NOT NULL DEFAULT '',
+    created_at        TIMESTAMP DEFAULT NOW()
+);
+CREATE INDEX idx_csv_templates_user ON csv_templates (user_id);
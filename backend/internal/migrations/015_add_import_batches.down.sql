@@ -0,0 +1,11 @@
+ALTER TABLE csv_templates
+    DROP COLUMN IF EXISTS id_column;
+
+DROP INDEX IF EXISTS idx_executions_import_batch;
+DROP INDEX IF EXISTS idx_executions_fingerprint;
+
+ALTER TABLE executions
+    DROP COLUMN IF EXISTS import_batch_id,
+    DROP COLUMN IF EXISTS fingerprint;
+
+DROP TABLE IF EXISTS import_batches;
@@ -0,0 +1,23 @@
+CREATE TABLE import_batches
+(
+    id         SERIAL PRIMARY KEY,
+    user_id    INT         NOT NULL REFERENCES users (id),
+    account_id INT REFERENCES accounts (id) ON DELETE SET NULL,
+    source     VARCHAR(20) NOT NULL,
+    created    INT         NOT NULL DEFAULT 0,
+    duplicates INT         NOT NULL DEFAULT 0,
+    skipped    INT         NOT NULL DEFAULT 0,
+    failed     INT         NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+CREATE INDEX idx_import_batches_user ON import_batches (user_id);
+
+ALTER TABLE executions
+    ADD COLUMN fingerprint     VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN import_batch_id INT REFERENCES import_batches (id) ON DELETE CASCADE;
+
+CREATE UNIQUE INDEX idx_executions_fingerprint ON executions (user_id, fingerprint) WHERE fingerprint <> '';
+CREATE INDEX idx_executions_import_batch ON executions (import_batch_id);
+
+ALTER TABLE csv_templates
+    ADD COLUMN id_column VARCHAR(100) NOT NULL DEFAULT '';
@@ -0,0 +1,16 @@
+CREATE TABLE cash_transactions
+(
+    id              SERIAL PRIMARY KEY,
+    user_id         INT         NOT NULL REFERENCES users (id),
+    account_id      INT REFERENCES accounts (id) ON DELETE SET NULL,
+    type            VARCHAR(20) NOT NULL,
+    symbol          VARCHAR(50) NOT NULL DEFAULT '',
+    amount          DECIMAL     NOT NULL,
+    currency        VARCHAR(3)  NOT NULL DEFAULT '',
+    posted_at       TIMESTAMP   NOT NULL,
+    description     TEXT        NOT NULL DEFAULT '',
+    fingerprint     VARCHAR(64) NOT NULL DEFAULT '',
+    import_batch_id INT REFERENCES import_batches (id) ON DELETE CASCADE
+);
+CREATE INDEX idx_cash_transactions_user ON cash_transactions (user_id, posted_at);
+CREATE UNIQUE INDEX idx_cash_transactions_fingerprint ON cash_transactions (user_id, fingerprint) WHERE fingerprint <> '';
@@ -0,0 +1,3 @@
+ALTER TABLE import_batches
+    DROP COLUMN IF EXISTS busted,
+    DROP COLUMN IF EXISTS corrected;
@@ -0,0 +1,5 @@
+-- Busts and corrections change fills from earlier imports, so they are
+-- counted on the batch rather than tagged with it.
+ALTER TABLE import_batches
+    ADD COLUMN busted INT NOT NULL DEFAULT 0,
+    ADD COLUMN corrected INT NOT NULL DEFAULT 0;
@@ -0,0 +1,14 @@
+CREATE TABLE corporate_actions
+(
+    id         SERIAL PRIMARY KEY,
+    user_id    INT         NOT NULL REFERENCES users (id),
+    date       DATE        NOT NULL,
+    type       VARCHAR(20) NOT NULL,
+    symbol     VARCHAR(50) NOT NULL,
+    new_symbol VARCHAR(50) NOT NULL DEFAULT '',
+    new_shares BIGINT      NOT NULL DEFAULT 0,
+    old_shares BIGINT      NOT NULL DEFAULT 0,
+    cash       DECIMAL     NOT NULL DEFAULT 0,
+    note       TEXT        NOT NULL DEFAULT ''
+);
+CREATE UNIQUE INDEX idx_corporate_actions_key ON corporate_actions (user_id, date, type, symbol);
@@ -0,0 +1,6 @@
+ALTER TABLE executions
+    DROP COLUMN IF EXISTS tags,
+    DROP COLUMN IF EXISTS strategy;
+
+ALTER TABLE trades
+    DROP COLUMN IF EXISTS tags;
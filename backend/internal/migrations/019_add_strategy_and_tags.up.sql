@@ -0,0 +1,6 @@
+ALTER TABLE trades
+    ADD COLUMN tags JSONB;
+
+ALTER TABLE executions
+    ADD COLUMN strategy VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN tags     JSONB;
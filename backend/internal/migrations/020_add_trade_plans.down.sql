@@ -0,0 +1,9 @@
+ALTER TABLE executions
+    DROP COLUMN IF EXISTS profit_target,
+    DROP COLUMN IF EXISTS stop_loss,
+    DROP COLUMN IF EXISTS planned_entry;
+
+ALTER TABLE trades
+    DROP COLUMN IF EXISTS profit_target,
+    DROP COLUMN IF EXISTS stop_loss,
+    DROP COLUMN IF EXISTS planned_entry;
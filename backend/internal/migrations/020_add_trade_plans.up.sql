@@ -0,0 +1,9 @@
+ALTER TABLE trades
+    ADD COLUMN planned_entry DECIMAL,
+    ADD COLUMN stop_loss     DECIMAL,
+    ADD COLUMN profit_target DECIMAL;
+
+ALTER TABLE executions
+    ADD COLUMN planned_entry DECIMAL,
+    ADD COLUMN stop_loss     DECIMAL,
+    ADD COLUMN profit_target DECIMAL;
@@ -0,0 +1,3 @@
+-- An empty time zone is taken to be UTC.
+ALTER TABLE users
+    ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT '';
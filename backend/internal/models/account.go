@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+const (
+	AccountCash    = "cash"
+	AccountMargin  = "margin"
+	AccountIRA     = "ira"
+	AccountFutures = "futures"
+	AccountOther   = "other"
+)
+
+// Account is a brokerage account owned by a user. Trades and executions
+// belong to at most one account; positions never net across accounts.
+// Currency is the account's cash currency, used for fills whose instrument
+// does not imply one.
+type Account struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"user_id"`
+	Name      string    `json:"name"`
+	Broker    string    `json:"broker,omitempty"`
+	Number    string    `json:"number,omitempty"`
+	Type      string    `json:"type,omitempty"`
+	Currency  string    `json:"currency,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func ValidAccountType(accountType string) bool {
+	switch accountType {
+	case AccountCash, AccountMargin, AccountIRA, AccountFutures, AccountOther:
+		return true
+	}
+	return false
+}
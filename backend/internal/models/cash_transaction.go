@@ -0,0 +1,41 @@
+package models
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+const (
+	CashDeposit    = "deposit"
+	CashWithdrawal = "withdrawal"
+	CashDividend   = "dividend"
+	CashInterest   = "interest"
+	CashFee        = "fee"
+	CashOther      = "other"
+)
+
+// CashTransaction is a movement of cash in an account other than paying for
+// a fill: deposits, withdrawals, dividends, interest and fees. Amount is
+// signed, positive into the account. Symbol names the security behind a
+// dividend or fee when there is one.
+type CashTransaction struct {
+	ID            int             `json:"id"`
+	UserID        int             `json:"user_id" gorm:"uniqueIndex:idx_cash_transactions_fingerprint"`
+	AccountID     *int            `json:"account_id,omitempty"`
+	Type          string          `json:"type"`
+	Symbol        string          `json:"symbol,omitempty"`
+	Amount        decimal.Decimal `json:"amount" gorm:"type:decimal"`
+	Currency      string          `json:"currency"`
+	PostedAt      time.Time       `json:"posted_at"`
+	Description   string          `json:"description,omitempty"`
+	Fingerprint   string          `json:"fingerprint,omitempty" gorm:"uniqueIndex:idx_cash_transactions_fingerprint,where:fingerprint <> ''"`
+	ImportBatchID *int            `json:"import_batch_id,omitempty"`
+}
+
+func ValidCashType(cashType string) bool {
+	switch cashType {
+	case CashDeposit, CashWithdrawal, CashDividend, CashInterest, CashFee, CashOther:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+const (
+	ActionSplit        = "split"
+	ActionSymbolChange = "symbol_change"
+	ActionMerger       = "merger"
+)
+
+// CorporateAction changes the shares of Symbol from the start of Date, its
+// ex-date. The ratio is kept as a fraction in lowest terms, NewShares for
+// every OldShares, so that a 1:3 reverse split restates holdings exactly: a
+// 4:1 split is 4 for 1 and a 1:10 reverse split 1 for 10. A symbol change
+// moves the shares to NewSymbol one for one; a merger either exchanges them
+// for shares of NewSymbol at the ratio or, with Cash, pays that much per
+// share.
+type CorporateAction struct {
+	ID        int             `json:"id"`
+	UserID    int             `json:"user_id" gorm:"uniqueIndex:idx_corporate_actions_key"`
+	Date      time.Time       `json:"date" gorm:"type:date;uniqueIndex:idx_corporate_actions_key"`
+	Type      string          `json:"type" gorm:"uniqueIndex:idx_corporate_actions_key"`
+	Symbol    string          `json:"symbol" gorm:"uniqueIndex:idx_corporate_actions_key"`
+	NewSymbol string          `json:"new_symbol,omitempty"`
+	NewShares int64           `json:"new_shares"`
+	OldShares int64           `json:"old_shares"`
+	Cash      decimal.Decimal `json:"cash" gorm:"type:decimal"`
+	Note      string          `json:"note,omitempty"`
+}
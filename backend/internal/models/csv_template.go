@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// CSVTemplate is a saved mapping from a broker's CSV columns onto fill fields.
+// Column fields hold header names; empty ones are not in the file. IDColumn
+// holds the broker's execution ID, which makes re-imports exact. Date and
+// time formats use YYYY, MM, DD, HH, mm and ss style tokens.
+type CSVTemplate struct {
+	ID               int       `json:"id"`
+	UserID           int       `json:"user_id"`
+	Name             string    `json:"name"`
+	SymbolColumn     string    `json:"symbol_column"`
+	SideColumn       string    `json:"side_column,omitempty"`
+	QuantityColumn   string    `json:"quantity_column"`
+	PriceColumn      string    `json:"price_column"`
+	DateColumn       string    `json:"date_column"`
+	TimeColumn       string    `json:"time_column,omitempty"`
+	CommissionColumn string    `json:"commission_column,omitempty"`
+	FeesColumn       string    `json:"fees_column,omitempty"`
+	CurrencyColumn   string    `json:"currency_column,omitempty"`
+	IDColumn         string    `json:"id_column,omitempty"`
+	DateFormat       string    `json:"date_format"`
+	TimeFormat       string    `json:"time_format,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+}
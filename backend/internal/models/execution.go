@@ -0,0 +1,37 @@
+package models
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+const (
+	SideBuy  = "buy"
+	SideSell = "sell"
+)
+
+// Execution is a single fill. Imported fills carry a Fingerprint, unique per
+// user, so that importing an overlapping statement again does not double
+// them, and the ID of the import batch that created them.
+type Execution struct {
+	ID            int             `json:"id"`
+	TradeID       int             `json:"trade_id,omitempty" gorm:"-"`
+	UserID        int             `json:"user_id" gorm:"uniqueIndex:idx_executions_fingerprint"`
+	AccountID     *int            `json:"account_id,omitempty"`
+	Symbol        string          `json:"symbol"`
+	Side          string          `json:"side"`
+	Action        string          `json:"action,omitempty"`
+	Quantity      decimal.Decimal `json:"quantity" gorm:"type:decimal"`
+	Price         decimal.Decimal `json:"price" gorm:"type:decimal"`
+	Commission    decimal.Decimal `json:"commission" gorm:"type:decimal"`
+	Fees          decimal.Decimal `json:"fees" gorm:"type:decimal"`
+	Multiplier    decimal.Decimal `json:"multiplier" gorm:"type:decimal"`
+	Currency      string          `json:"currency"`
+	SpreadID      *int            `json:"spread_id,omitempty"`
+	ExecutedAt    time.Time       `json:"executed_at"`
+	Strategy      string          `json:"strategy,omitempty"`
+	Tags          []string        `json:"tags,omitempty" gorm:"type:jsonb;serializer:json"`
+	Fingerprint   string          `json:"fingerprint,omitempty" gorm:"uniqueIndex:idx_executions_fingerprint,where:fingerprint <> ''"`
+	ImportBatchID *int            `json:"import_batch_id,omitempty"`
+	Plan
+}
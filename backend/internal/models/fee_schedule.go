@@ -0,0 +1,22 @@
+package models
+
+import "github.com/shopspring/decimal"
+
+// FeeSchedule describes what a broker charges so costs can be filled in when
+// an import file does not carry them. A schedule with an AccountID applies to
+// that account; the one without is the user's default. RegulatoryFeeRate is
+// charged on sell notional; BorrowRate is the annual rate on short positions.
+type FeeSchedule struct {
+	ID                 int             `json:"id"`
+	UserID             int             `json:"user_id"`
+	AccountID          *int            `json:"account_id,omitempty"`
+	CommissionPerShare decimal.Decimal `json:"commission_per_share" gorm:"type:decimal"`
+	CommissionPerOrder decimal.Decimal `json:"commission_per_order" gorm:"type:decimal"`
+	CommissionMinimum  decimal.Decimal `json:"commission_minimum" gorm:"type:decimal"`
+	RegulatoryFeeRate  decimal.Decimal `json:"regulatory_fee_rate" gorm:"type:decimal"`
+	BorrowRate         decimal.Decimal `json:"borrow_rate" gorm:"type:decimal"`
+	// MarginRate is the annual rate on long positions. The journal does not
+	// know the cash balance, so it is charged on the whole position rather
+	// than the margin loan; leave it zero for cash accounts.
+	MarginRate decimal.Decimal `json:"margin_rate" gorm:"type:decimal"`
+}
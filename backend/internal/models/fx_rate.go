@@ -0,0 +1,20 @@
+package models
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// DefaultCurrency is assumed wherever no currency has been recorded.
+const DefaultCurrency = "USD"
+
+// FXRate is the closing rate on Date: one unit of Currency buys Rate units of
+// Quote.
+type FXRate struct {
+	ID       int             `json:"id"`
+	UserID   int             `json:"user_id" gorm:"uniqueIndex:idx_fx_rates_key"`
+	Date     time.Time       `json:"date" gorm:"type:date;uniqueIndex:idx_fx_rates_key"`
+	Currency string          `json:"currency" gorm:"uniqueIndex:idx_fx_rates_key"`
+	Quote    string          `json:"quote" gorm:"uniqueIndex:idx_fx_rates_key"`
+	Rate     decimal.Decimal `json:"rate" gorm:"type:decimal"`
+}
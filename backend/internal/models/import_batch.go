@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+const (
+	SourceIBKR = "ibkr"
+	SourceCSV  = "csv"
+	SourceOFX  = "ofx"
+	SourceFIX  = "fix"
+
+	SourceNinjaTrader  = "ninjatrader"
+	SourceTradovate    = "tradovate"
+	SourceTradeStation = "tradestation"
+)
+
+// ImportBatch records one file import so that it can be reviewed and rolled
+// back as a unit. The executions and cash transactions it created carry its
+// ID; the fills it busted or corrected are only counted, and keep it from
+// being rolled back.
+type ImportBatch struct {
+	ID         int       `json:"id"`
+	UserID     int       `json:"user_id"`
+	AccountID  *int      `json:"account_id,omitempty"`
+	Source     string    `json:"source"`
+	Created    int       `json:"created"`
+	Duplicates int       `json:"duplicates"`
+	Skipped    int       `json:"skipped"`
+	Failed     int       `json:"failed"`
+	Busted     int       `json:"busted"`
+	Corrected  int       `json:"corrected"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+const (
+	AssetEquity = "equity"
+	AssetOption = "option"
+	AssetFuture = "future"
+)
+
+const (
+	RightCall = "call"
+	RightPut  = "put"
+)
+
+// Instrument describes the contract behind a symbol. It is derived from the
+// symbol rather than stored; Multiplier is the default for the contract and a
+// fill may carry its own, e.g. for options adjusted after a corporate action.
+// For futures Underlying is the root, ContractMonth is YYYY-MM and Multiplier
+// is the point value, TickValue divided by TickSize.
+type Instrument struct {
+	Symbol        string          `json:"symbol"`
+	AssetClass    string          `json:"asset_class"`
+	Underlying    string          `json:"underlying"`
+	Expiry        *time.Time      `json:"expiry,omitempty"`
+	Strike        decimal.Decimal `json:"strike"`
+	Right         string          `json:"right,omitempty"`
+	ContractMonth string          `json:"contract_month,omitempty"`
+	TickSize      decimal.Decimal `json:"tick_size"`
+	TickValue     decimal.Decimal `json:"tick_value"`
+	Currency      string          `json:"currency,omitempty"`
+	Multiplier    decimal.Decimal `json:"multiplier"`
+}
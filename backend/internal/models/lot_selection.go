@@ -0,0 +1,11 @@
+package models
+
+import "github.com/shopspring/decimal"
+
+type LotSelection struct {
+	ID               int             `json:"id"`
+	UserID           int             `json:"user_id"`
+	CloseExecutionID int             `json:"close_execution_id"`
+	OpenExecutionID  int             `json:"open_execution_id"`
+	Quantity         decimal.Decimal `json:"quantity" gorm:"type:decimal"`
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"errors"
+	"github.com/shopspring/decimal"
+)
+
+// Plan is the trader's plan for a fill that opens a position: the price they
+// meant to enter at, where they would cut the loss and where they would take
+// the profit. Any of them may be left out.
+type Plan struct {
+	PlannedEntry *decimal.Decimal `json:"planned_entry,omitempty" gorm:"type:decimal"`
+	StopLoss     *decimal.Decimal `json:"stop_loss,omitempty" gorm:"type:decimal"`
+	ProfitTarget *decimal.Decimal `json:"profit_target,omitempty" gorm:"type:decimal"`
+}
+
+// Validate checks that the plan's prices are positive and, for a position in
+// the given direction, that the stop is on the losing side of the entry and
+// the target on the winning side.
+func (p Plan) Validate(direction string) error {
+	for _, price := range []*decimal.Decimal{p.PlannedEntry, p.StopLoss, p.ProfitTarget} {
+		if price != nil && !price.IsPositive() {
+			return errors.New("planned prices must be positive")
+		}
+	}
+	below := func(low, high *decimal.Decimal) bool {
+		if low == nil || high == nil {
+			return true
+		}
+		if direction == DirectionShort {
+			low, high = high, low
+		}
+		return low.LessThan(*high)
+	}
+	if !below(p.StopLoss, p.PlannedEntry) {
+		return errors.New("stop_loss must be on the losing side of planned_entry")
+	}
+	if !below(p.PlannedEntry, p.ProfitTarget) {
+		return errors.New("profit_target must be on the winning side of planned_entry")
+	}
+	if !below(p.StopLoss, p.ProfitTarget) {
+		return errors.New("stop_loss and profit_target are on the wrong sides")
+	}
+	return nil
+}
+
+// Adjust returns the plan with its prices multiplied by oldShares and divided
+// by newShares, as a split of newShares for oldShares restates the price.
+func (p Plan) Adjust(oldShares, newShares decimal.Decimal) Plan {
+	adjust := func(price *decimal.Decimal) *decimal.Decimal {
+		if price == nil {
+			return nil
+		}
+		adjusted := price.Mul(oldShares).Div(newShares)
+		return &adjusted
+	}
+	return Plan{PlannedEntry: adjust(p.PlannedEntry), StopLoss: adjust(p.StopLoss), ProfitTarget: adjust(p.ProfitTarget)}
+}
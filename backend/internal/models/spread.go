@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+const (
+	SpreadVertical   = "vertical"
+	SpreadCalendar   = "calendar"
+	SpreadDiagonal   = "diagonal"
+	SpreadStraddle   = "straddle"
+	SpreadStrangle   = "strangle"
+	SpreadIronCondor = "iron_condor"
+	SpreadCustom     = "custom"
+)
+
+// Spread groups the legs of a multi-leg strategy. Fills and trades join a
+// spread through their SpreadID, and the legs are reported as one position.
+type Spread struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"user_id"`
+	Name      string    `json:"name"`
+	Kind      string    `json:"kind,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func ValidSpreadKind(kind string) bool {
+	switch kind {
+	case SpreadVertical, SpreadCalendar, SpreadDiagonal, SpreadStraddle, SpreadStrangle, SpreadIronCondor, SpreadCustom:
+		return true
+	}
+	return false
+}
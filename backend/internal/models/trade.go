@@ -1,14 +1,88 @@
 package models
 
-import "time"
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+const (
+	DirectionLong  = "long"
+	DirectionShort = "short"
+)
+
+const (
+	ActionBuy        = "buy"
+	ActionSell       = "sell"
+	ActionSellShort  = "sell_short"
+	ActionBuyToCover = "buy_to_cover"
+)
 
 type Trade struct {
-	ID        int       `json:"id"`
-	UserID    int       `json:"user_id"`
-	Symbol    string    `json:"symbol"`
-	Quantity  float64   `json:"quantity"`
-	Price     float64   `json:"price"`
-	TradeDate time.Time `json:"trade_date"`
-	Strategy  string    `json:"strategy,omitempty"`
-	Note      string    `json:"note,omitempty"`
+	ID         int             `json:"id"`
+	UserID     int             `json:"user_id"`
+	AccountID  *int            `json:"account_id,omitempty"`
+	Symbol     string          `json:"symbol"`
+	Direction  string          `json:"direction"`
+	Action     string          `json:"action"`
+	Quantity   decimal.Decimal `json:"quantity" gorm:"type:decimal"`
+	Price      decimal.Decimal `json:"price" gorm:"type:decimal"`
+	Commission decimal.Decimal `json:"commission" gorm:"type:decimal"`
+	Fees       decimal.Decimal `json:"fees" gorm:"type:decimal"`
+	Multiplier decimal.Decimal `json:"multiplier" gorm:"type:decimal"`
+	Currency   string          `json:"currency"`
+	SpreadID   *int            `json:"spread_id,omitempty"`
+	TradeDate  time.Time       `json:"trade_date"`
+	Strategy   string          `json:"strategy,omitempty"`
+	Tags       []string        `json:"tags,omitempty" gorm:"type:jsonb;serializer:json"`
+	Note       string          `json:"note,omitempty"`
+	Plan
+}
+
+func ValidAction(action string) bool {
+	switch action {
+	case ActionBuy, ActionSell, ActionSellShort, ActionBuyToCover:
+		return true
+	}
+	return false
+}
+
+// ActionSide reports whether an action buys or sells.
+func ActionSide(action string) string {
+	if action == ActionSell || action == ActionSellShort {
+		return SideSell
+	}
+	return SideBuy
+}
+
+// ActionDirection reports which side of the book an action trades: buy and
+// sell trade long positions, sell short and buy to cover trade short ones.
+func ActionDirection(action string) string {
+	if action == ActionSellShort || action == ActionBuyToCover {
+		return DirectionShort
+	}
+	return DirectionLong
+}
+
+// Execution returns the trade as a single fill so it can take part in
+// position building alongside recorded executions.
+func (t Trade) Execution() Execution {
+	return Execution{
+		TradeID:    t.ID,
+		UserID:     t.UserID,
+		AccountID:  t.AccountID,
+		Symbol:     t.Symbol,
+		Side:       ActionSide(t.Action),
+		Action:     t.Action,
+		Quantity:   t.Quantity,
+		Price:      t.Price,
+		Commission: t.Commission,
+		Fees:       t.Fees,
+		Multiplier: t.Multiplier,
+		Currency:   t.Currency,
+		SpreadID:   t.SpreadID,
+		ExecutedAt: t.TradeDate,
+		Strategy:   t.Strategy,
+		Tags:       t.Tags,
+		Plan:       t.Plan,
+	}
 }
@@ -1,8 +1,11 @@
 package models
 
 type User struct {
-	ID       int    `json:"id"`
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	Password string `json:"-"`
+	ID           int    `json:"id"`
+	Username     string `json:"username"`
+	Email        string `json:"email"`
+	Password     string `json:"-"`
+	LotMethod    string `json:"lot_method,omitempty"`
+	BaseCurrency string `json:"base_currency,omitempty"`
+	Timezone     string `json:"timezone,omitempty"`
 }
@@ -0,0 +1,302 @@
+package positions
+
+import (
+	"fmt"
+	"github.com/drewbuiltit/trading-journal/backend/internal/instruments"
+	"github.com/drewbuiltit/trading-journal/backend/internal/lots"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/shopspring/decimal"
+	"sort"
+	"time"
+)
+
+const (
+	DirectionLong  = lots.DirectionLong
+	DirectionShort = lots.DirectionShort
+
+	StatusOpen   = "open"
+	StatusClosed = "closed"
+)
+
+// Position is a round trip built from a user's executions in one symbol: it
+// opens when the net quantity leaves zero and closes when it returns to zero.
+// A spread position instead sums its Legs; see GroupSpreads.
+type Position struct {
+	AccountID      *int               `json:"account_id,omitempty"`
+	Symbol         string             `json:"symbol"`
+	Underlying     string             `json:"underlying"`
+	Instrument     *models.Instrument `json:"instrument,omitempty"`
+	SpreadID       *int               `json:"spread_id,omitempty"`
+	RolledFrom     string             `json:"rolled_from,omitempty"`
+	Kind           string             `json:"kind,omitempty"`
+	Strategy       string             `json:"strategy,omitempty"`
+	Tags           []string           `json:"tags,omitempty"`
+	Direction      string             `json:"direction"`
+	Status         string             `json:"status"`
+	Quantity       decimal.Decimal    `json:"quantity"`
+	Multiplier     decimal.Decimal    `json:"multiplier"`
+	Currency       string             `json:"currency"`
+	OpenQuantity   decimal.Decimal    `json:"open_quantity"`
+	EntryAverage   decimal.Decimal    `json:"entry_average"`
+	ExitAverage    decimal.Decimal    `json:"exit_average"`
+	OpenedAt       time.Time          `json:"opened_at"`
+	ClosedAt       *time.Time         `json:"closed_at,omitempty"`
+	HoldingSeconds int64              `json:"holding_seconds"`
+	models.Plan
+	lots.Costs
+	BorrowCost   decimal.Decimal `json:"borrow_cost"`
+	GrossPnL     decimal.Decimal `json:"gross_pnl"`
+	NetPnL       decimal.Decimal `json:"net_pnl"`
+	BaseCurrency string          `json:"base_currency,omitempty"`
+	BaseGrossPnL decimal.Decimal `json:"base_gross_pnl"`
+	BaseNetPnL   decimal.Decimal `json:"base_net_pnl"`
+	// InitialRisk is what the position stood to lose had it been stopped
+	// out: the distance from the planned entry, or the average entry without
+	// one, to the stop loss over the whole quantity.
+	InitialRisk *decimal.Decimal `json:"initial_risk,omitempty"`
+	// RMultiple is the net P&L of a closed position in units of its initial
+	// risk.
+	RMultiple *decimal.Decimal `json:"r_multiple,omitempty"`
+	// FXRateMissing is set when a close had no rate into the base currency,
+	// so the base P&L leaves it out.
+	FXRateMissing bool         `json:"fx_rate_missing,omitempty"`
+	ExecutionIDs  []int        `json:"execution_ids"`
+	TradeIDs      []int        `json:"trade_ids,omitempty"`
+	Matches       []lots.Match `json:"matches"`
+	OpenLots      []lots.Lot   `json:"open_lots"`
+	Legs          []Position   `json:"legs,omitempty"`
+}
+
+type builder struct {
+	config       lots.Config
+	position     *Position
+	book         *lots.Book
+	entryCost    decimal.Decimal
+	exitQuantity decimal.Decimal
+	exitProceeds decimal.Decimal
+}
+
+// Build groups executions by account and symbol, keeping spread legs apart
+// from outright fills, into positions ordered by the time they were opened.
+// Partial closes are matched against open lots using config.
+func Build(executions []models.Execution, config lots.Config) []Position {
+	sorted := make([]models.Execution, len(executions))
+	copy(sorted, executions)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].ExecutedAt.Equal(sorted[j].ExecutedAt) {
+			return sorted[i].ExecutedAt.Before(sorted[j].ExecutedAt)
+		}
+		if sorted[i].ID != sorted[j].ID {
+			return sorted[i].ID < sorted[j].ID
+		}
+		return sorted[i].TradeID < sorted[j].TradeID
+	})
+
+	positions := []Position{}
+	builders := make(map[string]*builder)
+
+	for _, execution := range sorted {
+		key := execution.Symbol
+		if execution.SpreadID != nil {
+			key = fmt.Sprintf("%s/%d", key, *execution.SpreadID)
+		}
+		if execution.AccountID != nil {
+			key = fmt.Sprintf("%d:%s", *execution.AccountID, key)
+		}
+		b, exists := builders[key]
+		if !exists {
+			b = &builder{config: config}
+			builders[key] = b
+		}
+
+		costs := lots.Costs{Commission: execution.Commission, Fees: execution.Fees}
+		remaining := execution.Quantity
+		for remaining.IsPositive() {
+			if b.position == nil {
+				b.open(execution)
+			}
+
+			if b.adds(execution) {
+				b.add(execution, remaining, costs)
+				break
+			}
+
+			closed := decimal.Min(remaining, b.position.OpenQuantity)
+			closedCosts := costs.Portion(closed, remaining)
+			b.reduce(execution, closed, closedCosts)
+			costs = costs.Minus(closedCosts)
+			remaining = remaining.Sub(closed)
+
+			if !b.position.OpenQuantity.IsPositive() {
+				positions = append(positions, b.close(execution.ExecutedAt))
+			}
+		}
+	}
+
+	for _, b := range builders {
+		if b.position != nil {
+			positions = append(positions, b.snapshot())
+		}
+	}
+
+	sort.SliceStable(positions, func(i, j int) bool {
+		if !positions[i].OpenedAt.Equal(positions[j].OpenedAt) {
+			return positions[i].OpenedAt.Before(positions[j].OpenedAt)
+		}
+		return positions[i].Symbol < positions[j].Symbol
+	})
+
+	return positions
+}
+
+func (b *builder) open(execution models.Execution) {
+	direction := DirectionLong
+	if execution.Side == models.SideSell {
+		direction = DirectionShort
+	}
+
+	instrument := instruments.Lookup(execution.Symbol)
+
+	*b = builder{
+		config: b.config,
+		book:   lots.NewBook(b.config.ForAccount(execution.AccountID)),
+		position: &Position{
+			AccountID:    execution.AccountID,
+			Symbol:       execution.Symbol,
+			Underlying:   instrument.Underlying,
+			SpreadID:     execution.SpreadID,
+			Direction:    direction,
+			Multiplier:   instruments.Multiplier(execution),
+			Currency:     instruments.Currency(execution),
+			Status:       StatusOpen,
+			OpenedAt:     execution.ExecutedAt,
+			ExecutionIDs: []int{},
+			Matches:      []lots.Match{},
+		},
+	}
+	if instrument.AssetClass != models.AssetEquity {
+		b.position.Instrument = &instrument
+	}
+}
+
+func (b *builder) adds(execution models.Execution) bool {
+	if b.position.Direction == DirectionLong {
+		return execution.Side == models.SideBuy
+	}
+	return execution.Side == models.SideSell
+}
+
+func (b *builder) add(execution models.Execution, quantity decimal.Decimal, costs lots.Costs) {
+	b.track(execution)
+	b.position.Quantity = b.position.Quantity.Add(quantity)
+	b.position.OpenQuantity = b.position.OpenQuantity.Add(quantity)
+	b.position.Costs = b.position.Costs.Plus(costs)
+	b.entryCost = b.entryCost.Add(quantity.Mul(execution.Price))
+	b.book.Open(lots.Lot{
+		ExecutionID: execution.ID,
+		TradeID:     execution.TradeID,
+		Symbol:      execution.Symbol,
+		Direction:   b.position.Direction,
+		OpenedAt:    execution.ExecutedAt,
+		Quantity:    quantity,
+		Price:       execution.Price,
+		Multiplier:  instruments.Multiplier(execution),
+		Costs:       costs,
+	})
+}
+
+func (b *builder) reduce(execution models.Execution, quantity decimal.Decimal, costs lots.Costs) {
+	b.track(execution)
+
+	for _, match := range b.book.Close(execution, quantity, costs) {
+		b.position.BorrowCost = b.position.BorrowCost.Add(match.BorrowCost)
+		b.position.GrossPnL = b.position.GrossPnL.Add(match.GrossPnL)
+		b.position.NetPnL = b.position.NetPnL.Add(match.NetPnL)
+		b.position.Matches = append(b.position.Matches, match)
+	}
+
+	b.position.Costs = b.position.Costs.Plus(costs)
+	b.position.OpenQuantity = b.position.OpenQuantity.Sub(quantity)
+	b.exitQuantity = b.exitQuantity.Add(quantity)
+	b.exitProceeds = b.exitProceeds.Add(quantity.Mul(execution.Price))
+}
+
+// track records the fill as part of the position. The position takes the
+// first strategy and planned prices given on its fills and every tag.
+func (b *builder) track(execution models.Execution) {
+	if b.position.Strategy == "" {
+		b.position.Strategy = execution.Strategy
+	}
+	if b.position.PlannedEntry == nil {
+		b.position.PlannedEntry = execution.PlannedEntry
+	}
+	if b.position.StopLoss == nil {
+		b.position.StopLoss = execution.StopLoss
+	}
+	if b.position.ProfitTarget == nil {
+		b.position.ProfitTarget = execution.ProfitTarget
+	}
+	if len(execution.Tags) > 0 {
+		b.position.Tags = models.NormalizeTags(append(append([]string{}, b.position.Tags...), execution.Tags...))
+	}
+	if execution.TradeID != 0 {
+		b.position.TradeIDs = appendOnce(b.position.TradeIDs, execution.TradeID)
+		return
+	}
+	// The fill that closes out a cash merger has no ID.
+	if execution.ID != 0 {
+		b.position.ExecutionIDs = appendOnce(b.position.ExecutionIDs, execution.ID)
+	}
+}
+
+func appendOnce(ids []int, id int) []int {
+	if len(ids) == 0 || ids[len(ids)-1] != id {
+		return append(ids, id)
+	}
+	return ids
+}
+
+func (b *builder) snapshot() Position {
+	position := *b.position
+	if position.Quantity.IsPositive() {
+		position.EntryAverage = b.entryCost.Div(position.Quantity)
+	}
+	if b.exitQuantity.IsPositive() {
+		position.ExitAverage = b.exitProceeds.Div(b.exitQuantity)
+	}
+	position.OpenLots = b.book.OpenLots()
+	position.InitialRisk, position.RMultiple = risk(position)
+	return position
+}
+
+// risk works out the initial risk of a position with a stop loss and, once
+// it has closed, its R-multiple.
+func risk(position Position) (*decimal.Decimal, *decimal.Decimal) {
+	if position.StopLoss == nil {
+		return nil, nil
+	}
+	entry := position.EntryAverage
+	if position.PlannedEntry != nil {
+		entry = *position.PlannedEntry
+	}
+	initial := entry.Sub(*position.StopLoss).Abs().Mul(position.Quantity).Mul(position.Multiplier)
+	if !initial.IsPositive() {
+		return nil, nil
+	}
+	if position.Status != StatusClosed {
+		return &initial, nil
+	}
+	multiple := position.NetPnL.DivRound(initial, 4)
+	return &initial, &multiple
+}
+
+func (b *builder) close(closedAt time.Time) Position {
+	b.position.OpenQuantity = decimal.Zero
+	b.position.Status = StatusClosed
+	b.position.ClosedAt = &closedAt
+	b.position.HoldingSeconds = int64(closedAt.Sub(b.position.OpenedAt).Seconds())
+
+	position := b.snapshot()
+	b.position = nil
+	return position
+}
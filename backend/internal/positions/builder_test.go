@@ -0,0 +1,271 @@
+package positions
+
+import (
+	"github.com/drewbuiltit/trading-journal/backend/internal/lots"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var start = time.Date(2024, 5, 1, 14, 30, 0, 0, time.UTC)
+
+func dec(value string) decimal.Decimal {
+	return decimal.RequireFromString(value)
+}
+
+func fill(id int, symbol, side, quantity, price, fees string, offset time.Duration) models.Execution {
+	return models.Execution{
+		ID:         id,
+		UserID:     1,
+		Symbol:     symbol,
+		Side:       side,
+		Quantity:   dec(quantity),
+		Price:      dec(price),
+		Fees:       dec(fees),
+		ExecutedAt: start.Add(offset),
+	}
+}
+
+func TestBuild_ScaleInAndOut(t *testing.T) {
+	executions := []models.Execution{
+		fill(1, "AAPL", models.SideBuy, "100", "10", "1", 0),
+		fill(2, "AAPL", models.SideBuy, "100", "12", "1", time.Minute),
+		fill(3, "AAPL", models.SideSell, "50", "13", "0.5", 2*time.Minute),
+		fill(4, "AAPL", models.SideSell, "150", "14", "1.5", time.Hour),
+	}
+
+	positions := Build(executions, lots.Config{})
+	assert.Len(t, positions, 1)
+
+	position := positions[0]
+	assert.Equal(t, DirectionLong, position.Direction)
+	assert.Equal(t, StatusClosed, position.Status)
+	assert.Equal(t, "200", position.Quantity.String())
+	assert.Equal(t, "11", position.EntryAverage.String())
+	assert.Equal(t, "13.75", position.ExitAverage.String())
+	assert.Equal(t, int64(3600), position.HoldingSeconds)
+	assert.Equal(t, "4", position.Fees.String())
+	assert.Equal(t, "546", position.NetPnL.String())
+	assert.Equal(t, []int{1, 2, 3, 4}, position.ExecutionIDs)
+}
+
+func TestBuild_ShortRoundTrip(t *testing.T) {
+	positions := Build([]models.Execution{
+		fill(1, "TSLA", models.SideSell, "10", "200", "0", 0),
+		fill(2, "TSLA", models.SideBuy, "10", "190", "0", time.Minute),
+	}, lots.Config{})
+
+	assert.Len(t, positions, 1)
+	assert.Equal(t, DirectionShort, positions[0].Direction)
+	assert.Equal(t, "100", positions[0].NetPnL.String())
+}
+
+func TestBuild_FlipThroughZero(t *testing.T) {
+	positions := Build([]models.Execution{
+		fill(1, "MSFT", models.SideBuy, "100", "10", "0", 0),
+		fill(2, "MSFT", models.SideSell, "150", "11", "3", time.Minute),
+	}, lots.Config{})
+
+	assert.Len(t, positions, 2)
+
+	assert.Equal(t, DirectionLong, positions[0].Direction)
+	assert.Equal(t, StatusClosed, positions[0].Status)
+	assert.Equal(t, "2", positions[0].Fees.String())
+	assert.Equal(t, "98", positions[0].NetPnL.String())
+
+	assert.Equal(t, DirectionShort, positions[1].Direction)
+	assert.Equal(t, StatusOpen, positions[1].Status)
+	assert.Equal(t, "50", positions[1].OpenQuantity.String())
+	assert.Equal(t, "11", positions[1].EntryAverage.String())
+	assert.Equal(t, "1", positions[1].Fees.String())
+	assert.Nil(t, positions[1].ClosedAt)
+}
+
+func TestBuild_SeparatesSymbolsAndOrdersByOpen(t *testing.T) {
+	positions := Build([]models.Execution{
+		fill(3, "NVDA", models.SideBuy, "1", "900", "0", 2*time.Minute),
+		fill(1, "AMD", models.SideBuy, "10", "150", "0", 0),
+		fill(2, "AMD", models.SideSell, "10", "155", "0", time.Minute),
+	}, lots.Config{})
+
+	assert.Len(t, positions, 2)
+	assert.Equal(t, "AMD", positions[0].Symbol)
+	assert.Equal(t, StatusClosed, positions[0].Status)
+	assert.Equal(t, "NVDA", positions[1].Symbol)
+	assert.Equal(t, StatusOpen, positions[1].Status)
+}
+
+func TestBuild_TradesAsFills(t *testing.T) {
+	trades := []models.Trade{
+		{ID: 7, UserID: 1, Symbol: "TSLA", Action: models.ActionSellShort, Quantity: dec("10"), Price: dec("250"), TradeDate: start},
+		{ID: 8, UserID: 1, Symbol: "TSLA", Action: models.ActionBuyToCover, Quantity: dec("10"), Price: dec("240"), TradeDate: start.Add(time.Hour)},
+	}
+
+	executions := []models.Execution{}
+	for _, trade := range trades {
+		executions = append(executions, trade.Execution())
+	}
+
+	positions := Build(executions, lots.Config{})
+	assert.Len(t, positions, 1)
+	assert.Equal(t, DirectionShort, positions[0].Direction)
+	assert.Equal(t, StatusClosed, positions[0].Status)
+	assert.Equal(t, "100", positions[0].NetPnL.String())
+	assert.Equal(t, []int{7, 8}, positions[0].TradeIDs)
+	assert.Empty(t, positions[0].ExecutionIDs)
+	assert.Equal(t, 7, positions[0].Matches[0].OpenTradeID)
+	assert.Equal(t, 8, positions[0].Matches[0].CloseTradeID)
+}
+
+func TestBuild_GrossAndNetPnL(t *testing.T) {
+	buy := fill(1, "AAPL", models.SideBuy, "100", "10", "0.5", 0)
+	buy.Commission = dec("1")
+	sell := fill(2, "AAPL", models.SideSell, "100", "12", "0.5", time.Hour)
+	sell.Commission = dec("1")
+
+	positions := Build([]models.Execution{buy, sell}, lots.Config{})
+	assert.Len(t, positions, 1)
+	assert.Equal(t, "200", positions[0].GrossPnL.String())
+	assert.Equal(t, "2", positions[0].Commission.String())
+	assert.Equal(t, "1", positions[0].Fees.String())
+	assert.Equal(t, "197", positions[0].NetPnL.String())
+}
+
+func TestBuild_OptionUsesMultiplier(t *testing.T) {
+	positions := Build([]models.Execution{
+		fill(1, "AAPL  240119C00190000", models.SideBuy, "3", "2.50", "0", 0),
+		fill(2, "AAPL  240119C00190000", models.SideSell, "3", "3.75", "0", time.Hour),
+	}, lots.Config{})
+
+	assert.Len(t, positions, 1)
+	position := positions[0]
+	assert.Equal(t, "AAPL", position.Underlying)
+	assert.Equal(t, "100", position.Multiplier.String())
+	assert.Equal(t, models.RightCall, position.Instrument.Right)
+	assert.Equal(t, "375", position.GrossPnL.String())
+}
+
+func TestGroupSpreads_CreditVertical(t *testing.T) {
+	spreadID := 7
+	short := fill(1, "SPY   240621P00500000", models.SideSell, "2", "4.10", "0.02", 0)
+	long := fill(2, "SPY   240621P00495000", models.SideBuy, "2", "2.60", "0", 0)
+	closeShort := fill(3, "SPY   240621P00500000", models.SideBuy, "2", "1.00", "0", 24*time.Hour)
+	closeLong := fill(4, "SPY   240621P00495000", models.SideSell, "2", "0.40", "0.01", 24*time.Hour)
+	outright := fill(5, "SPY", models.SideBuy, "10", "500", "0", time.Hour)
+	for _, leg := range []*models.Execution{&short, &long, &closeShort, &closeLong} {
+		leg.SpreadID = &spreadID
+	}
+
+	positions := GroupSpreads(Build([]models.Execution{short, long, closeShort, closeLong, outright}, lots.Config{}),
+		[]models.Spread{{ID: spreadID, Name: "SPY put credit"}})
+	assert.Len(t, positions, 2)
+
+	spread := positions[0]
+	assert.Equal(t, "SPY", spread.Symbol)
+	assert.Equal(t, spreadID, *spread.SpreadID)
+	assert.Equal(t, models.SpreadVertical, spread.Kind)
+	assert.Equal(t, DirectionShort, spread.Direction)
+	assert.Equal(t, StatusClosed, spread.Status)
+	assert.Equal(t, "2", spread.Quantity.String())
+	assert.Equal(t, "1.5", spread.EntryAverage.String())
+	assert.Equal(t, "0.6", spread.ExitAverage.String())
+	assert.Equal(t, "180", spread.GrossPnL.String())
+	assert.Equal(t, "179.97", spread.NetPnL.String())
+	assert.Equal(t, int64(86400), spread.HoldingSeconds)
+	assert.Len(t, spread.Legs, 2)
+
+	assert.Nil(t, positions[1].SpreadID)
+	assert.Equal(t, "SPY", positions[1].Symbol)
+	assert.Equal(t, StatusOpen, positions[1].Status)
+}
+
+func TestGroupSpreads_OpenWhileAnyLegIsOpen(t *testing.T) {
+	spreadID := 3
+	near := fill(1, "AAPL  240621C00200000", models.SideSell, "1", "2", "0", 0)
+	far := fill(2, "AAPL  240719C00200000", models.SideBuy, "1", "5", "0", 0)
+	closeNear := fill(3, "AAPL  240621C00200000", models.SideBuy, "1", "0.5", "0", time.Hour)
+	for _, leg := range []*models.Execution{&near, &far, &closeNear} {
+		leg.SpreadID = &spreadID
+	}
+
+	positions := GroupSpreads(Build([]models.Execution{near, far, closeNear}, lots.Config{}), nil)
+	assert.Len(t, positions, 1)
+	assert.Equal(t, models.SpreadCalendar, positions[0].Kind)
+	assert.Equal(t, DirectionLong, positions[0].Direction)
+	assert.Equal(t, StatusOpen, positions[0].Status)
+	assert.Nil(t, positions[0].ClosedAt)
+	assert.Equal(t, "150", positions[0].GrossPnL.String())
+}
+
+func TestBuild_KeepsAccountsApart(t *testing.T) {
+	cash, ira := 1, 2
+	buy := fill(1, "AAPL", models.SideBuy, "10", "100", "0", 0)
+	buy.AccountID = &cash
+	sell := fill(2, "AAPL", models.SideSell, "10", "110", "0", time.Minute)
+	sell.AccountID = &ira
+
+	positions := Build([]models.Execution{buy, sell}, lots.Config{})
+	assert.Len(t, positions, 2, "a sell in one account must not close a buy in another")
+	assert.Equal(t, DirectionLong, positions[0].Direction)
+	assert.Equal(t, cash, *positions[0].AccountID)
+	assert.Equal(t, DirectionShort, positions[1].Direction)
+	assert.Equal(t, ira, *positions[1].AccountID)
+}
+
+func TestBuild_AccountFinancingRates(t *testing.T) {
+	margin, cash := 1, 2
+	var executions []models.Execution
+	for i, accountID := range []*int{&margin, &cash} {
+		buy := fill(2*i+1, "AAPL", models.SideBuy, "100", "180", "0", 0)
+		buy.AccountID = accountID
+		sell := fill(2*i+2, "AAPL", models.SideSell, "100", "180", "0", 48*time.Hour)
+		sell.AccountID = accountID
+		executions = append(executions, buy, sell)
+	}
+
+	config := lots.Config{Accounts: map[int]lots.Rates{margin: {MarginRate: dec("0.06")}}}
+	positions := Build(executions, config)
+	assert.Len(t, positions, 2)
+	for _, position := range positions {
+		if *position.AccountID == margin {
+			assert.Equal(t, "6", position.BorrowCost.String(), "two nights of margin interest")
+		} else {
+			assert.True(t, position.BorrowCost.IsZero())
+		}
+	}
+}
+
+func TestBuild_RMultiple(t *testing.T) {
+	stop := func(execution models.Execution, entry, stop string) models.Execution {
+		if entry != "" {
+			planned := dec(entry)
+			execution.PlannedEntry = &planned
+		}
+		stopLoss := dec(stop)
+		execution.StopLoss = &stopLoss
+		return execution
+	}
+
+	positions := Build([]models.Execution{
+		stop(fill(1, "AAPL", models.SideBuy, "100", "10", "1", 0), "", "9"),
+		fill(2, "AAPL", models.SideSell, "100", "13", "1", time.Hour),
+		stop(fill(3, "TSLA", models.SideSell, "10", "201", "0", 2*time.Hour), "200", "205"),
+		fill(4, "TSLA", models.SideBuy, "10", "206.5", "0", 3*time.Hour),
+		stop(fill(5, "MSFT", models.SideBuy, "10", "400", "0", 4*time.Hour), "", "390"),
+		fill(6, "NVDA", models.SideBuy, "1", "900", "0", 5*time.Hour),
+	}, lots.Config{})
+	assert.Len(t, positions, 4)
+
+	assert.Equal(t, "100", positions[0].InitialRisk.String(), "the average entry stands in for a planned one")
+	assert.Equal(t, "2.98", positions[0].RMultiple.String())
+
+	assert.Equal(t, "50", positions[1].InitialRisk.String(), "risk is measured from the planned entry")
+	assert.Equal(t, "-1.1", positions[1].RMultiple.String(), "slippage past the stop loses more than 1R")
+
+	assert.Equal(t, "100", positions[2].InitialRisk.String())
+	assert.Nil(t, positions[2].RMultiple, "open positions have no outcome yet")
+
+	assert.Nil(t, positions[3].InitialRisk, "no stop, no risk")
+}
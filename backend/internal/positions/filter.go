@@ -0,0 +1,109 @@
+package positions
+
+import (
+	"errors"
+	"github.com/drewbuiltit/trading-journal/backend/internal/accounts"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Filter selects positions the way the positions listing does. Empty fields
+// match everything.
+type Filter struct {
+	Status    string
+	Direction string
+	Symbol    string
+	Strategy  string
+	Tag       string
+	From      *time.Time
+	To        *time.Time
+	Accounts  accounts.Filter
+}
+
+// ParseFilter reads ?status=, ?direction=, ?symbol=, ?strategy=, ?tag=,
+// ?from=, ?to= and ?account=. A symbol matches a position's own symbol or
+// its underlying. Strategies match without regard to case. The dates are
+// inclusive YYYY-MM-DD days, UTC, and select positions by the day they
+// closed, or opened if they are still open.
+func ParseFilter(query url.Values) (Filter, error) {
+	filter := Filter{
+		Status:    strings.ToLower(query.Get("status")),
+		Direction: strings.ToLower(query.Get("direction")),
+		Symbol:    strings.ToUpper(query.Get("symbol")),
+		Strategy:  strings.TrimSpace(query.Get("strategy")),
+		Tag:       strings.ToLower(strings.TrimSpace(query.Get("tag"))),
+	}
+	if filter.Status != "" && filter.Status != StatusOpen && filter.Status != StatusClosed {
+		return Filter{}, errors.New("status must be open or closed")
+	}
+	if filter.Direction != "" && filter.Direction != DirectionLong && filter.Direction != DirectionShort {
+		return Filter{}, errors.New("direction must be long or short")
+	}
+
+	var err error
+	if filter.From, err = parseDay(query, "from"); err != nil {
+		return Filter{}, err
+	}
+	if filter.To, err = parseDay(query, "to"); err != nil {
+		return Filter{}, err
+	}
+	if filter.From != nil && filter.To != nil && filter.To.Before(*filter.From) {
+		return Filter{}, errors.New("to must not be before from")
+	}
+	if filter.Accounts, err = accounts.ParseFilter(query); err != nil {
+		return Filter{}, err
+	}
+	return filter, nil
+}
+
+func (f Filter) Match(position Position) bool {
+	if f.Status != "" && position.Status != f.Status {
+		return false
+	}
+	if f.Direction != "" && position.Direction != f.Direction {
+		return false
+	}
+	if f.Symbol != "" && position.Symbol != f.Symbol && position.Underlying != f.Symbol {
+		return false
+	}
+	if f.Strategy != "" && !strings.EqualFold(position.Strategy, f.Strategy) {
+		return false
+	}
+	if f.Tag != "" && !hasTag(position.Tags, f.Tag) {
+		return false
+	}
+	if f.From != nil || f.To != nil {
+		at := position.OpenedAt
+		if position.ClosedAt != nil {
+			at = *position.ClosedAt
+		}
+		at = at.UTC()
+		day := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC)
+		if (f.From != nil && day.Before(*f.From)) || (f.To != nil && day.After(*f.To)) {
+			return false
+		}
+	}
+	return f.Accounts.Match(position.AccountID)
+}
+
+func parseDay(query url.Values, name string) (*time.Time, error) {
+	value := query.Get(name)
+	if value == "" {
+		return nil, nil
+	}
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, errors.New(name + " must be YYYY-MM-DD")
+	}
+	return &date, nil
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+package positions
+
+import (
+	"github.com/drewbuiltit/trading-journal/backend/internal/fx"
+	"github.com/shopspring/decimal"
+)
+
+// ConvertPnL reports realized P&L in the base currency as well as the
+// position's own. Each lot match is converted at the rate on the day it
+// closed and records that rate. Run it before GroupSpreads, which sums the
+// converted legs.
+func ConvertPnL(positions []Position, table *fx.Table, base string) []Position {
+	for i := range positions {
+		position := &positions[i]
+		position.BaseCurrency = base
+		position.BaseGrossPnL = decimal.Zero
+		position.BaseNetPnL = decimal.Zero
+
+		for j := range position.Matches {
+			match := &position.Matches[j]
+			rate, ok := table.Rate(position.Currency, base, match.ClosedAt)
+			if !ok {
+				position.FXRateMissing = true
+				continue
+			}
+			match.FXRate = rate
+			match.BaseGrossPnL = match.GrossPnL.Mul(rate)
+			match.BaseNetPnL = match.NetPnL.Mul(rate)
+			position.BaseGrossPnL = position.BaseGrossPnL.Add(match.BaseGrossPnL)
+			position.BaseNetPnL = position.BaseNetPnL.Add(match.BaseNetPnL)
+		}
+	}
+	return positions
+}
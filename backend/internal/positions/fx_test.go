@@ -0,0 +1,45 @@
+package positions
+
+import (
+	"github.com/drewbuiltit/trading-journal/backend/internal/fx"
+	"github.com/drewbuiltit/trading-journal/backend/internal/lots"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestConvertPnL(t *testing.T) {
+	table := fx.NewTable([]models.FXRate{
+		{Date: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), Currency: "GBP", Quote: "USD", Rate: dec("1.25")},
+		{Date: time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC), Currency: "GBP", Quote: "USD", Rate: dec("1.30")},
+	})
+
+	positions := ConvertPnL(Build([]models.Execution{
+		fill(1, "VOD.L", models.SideBuy, "1000", "0.70", "1", 0),
+		fill(2, "VOD.L", models.SideSell, "500", "0.74", "1", time.Hour),
+		fill(3, "VOD.L", models.SideSell, "500", "0.76", "1", 24*time.Hour),
+		fill(4, "7203.T", models.SideBuy, "100", "3500", "0", 0),
+		fill(5, "7203.T", models.SideSell, "100", "3600", "0", time.Hour),
+	}, lots.Config{}), table, "USD")
+
+	assert.Len(t, positions, 2)
+
+	toyota := positions[0]
+	assert.Equal(t, "JPY", toyota.Currency)
+	assert.Equal(t, "10000", toyota.NetPnL.String())
+	assert.True(t, toyota.FXRateMissing)
+	assert.True(t, toyota.BaseNetPnL.IsZero())
+
+	vodafone := positions[1]
+	assert.Equal(t, "GBP", vodafone.Currency)
+	assert.Equal(t, "USD", vodafone.BaseCurrency)
+	assert.Equal(t, "50", vodafone.GrossPnL.String())
+	assert.Equal(t, "47", vodafone.NetPnL.String())
+	assert.Equal(t, "1.25", vodafone.Matches[0].FXRate.String())
+	assert.Equal(t, "1.3", vodafone.Matches[1].FXRate.String())
+	assert.Equal(t, "23.125", vodafone.Matches[0].BaseNetPnL.String())
+	assert.Equal(t, "37.05", vodafone.Matches[1].BaseNetPnL.String())
+	assert.Equal(t, "60.175", vodafone.BaseNetPnL.String())
+	assert.False(t, vodafone.FXRateMissing)
+}
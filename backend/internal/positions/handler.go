@@ -0,0 +1,131 @@
+package positions
+
+import (
+	"encoding/json"
+	"github.com/drewbuiltit/trading-journal/backend/internal/accounts"
+	"github.com/drewbuiltit/trading-journal/backend/internal/auth"
+	"github.com/drewbuiltit/trading-journal/backend/internal/lots"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"net/http"
+	"strings"
+)
+
+type PositionHandler struct {
+	Store store.Store
+}
+
+type LotsResponse struct {
+	Method string       `json:"method"`
+	Open   []lots.Lot   `json:"open"`
+	Closed []lots.Match `json:"closed"`
+}
+
+func (h *PositionHandler) ListPositions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	filter, err := ParseFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	built, _, err := ForUser(h.Store, userID)
+	if err != nil {
+		http.Error(w, "Error building positions", http.StatusInternalServerError)
+		return
+	}
+
+	positions := []Position{}
+	for _, position := range built {
+		if filter.Match(position) {
+			positions = append(positions, position)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(positions)
+}
+
+// ListLots returns the user's open lots and realized lot matches under their
+// current lot method. Nothing is cached, so editing a historical execution or
+// switching methods is reflected on the next request.
+func (h *PositionHandler) ListLots(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	symbol := strings.ToUpper(r.URL.Query().Get("symbol"))
+	accountFilter, err := accounts.ParseFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	built, config, err := ForUser(h.Store, userID)
+	if err != nil {
+		http.Error(w, "Error building positions", http.StatusInternalServerError)
+		return
+	}
+
+	response := LotsResponse{
+		Method: config.Method,
+		Open:   []lots.Lot{},
+		Closed: []lots.Match{},
+	}
+	for _, position := range built {
+		if symbol != "" && position.Symbol != symbol && position.Underlying != symbol {
+			continue
+		}
+		if !accountFilter.Match(position.AccountID) {
+			continue
+		}
+		response.Open = append(response.Open, position.OpenLots...)
+		response.Closed = append(response.Closed, position.Matches...)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// ListChains returns the user's futures positions linked across contract
+// rolls, optionally for a single root.
+func (h *PositionHandler) ListChains(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	root := strings.ToUpper(strings.TrimPrefix(r.URL.Query().Get("root"), "/"))
+	accountFilter, err := accounts.ParseFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	built, _, err := ForUser(h.Store, userID)
+	if err != nil {
+		http.Error(w, "Error building positions", http.StatusInternalServerError)
+		return
+	}
+
+	chains := []Chain{}
+	for _, chain := range Chains(built) {
+		if root != "" && chain.Root != root {
+			continue
+		}
+		if !accountFilter.Match(chain.AccountID) {
+			continue
+		}
+		chains = append(chains, chain)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(chains)
+}
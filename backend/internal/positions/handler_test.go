@@ -0,0 +1,200 @@
+package positions
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/drewbuiltit/trading-journal/backend/internal/auth"
+	"github.com/drewbuiltit/trading-journal/backend/internal/lots"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestListPositionsHandler(t *testing.T) {
+	positionHandler := &PositionHandler{Store: store.NewMemoryStore()}
+	assert.NoError(t, positionHandler.Store.CreateUser(&models.User{Username: "jane_doe", Email: "jane@example.com"}))
+
+	for _, execution := range []models.Execution{
+		fill(0, "AAPL", models.SideBuy, "10", "100", "0", 0),
+		fill(0, "AAPL", models.SideSell, "10", "110", "0", time.Minute),
+		fill(0, "MSFT", models.SideBuy, "5", "400", "0", 2*time.Minute),
+		{UserID: 2, Symbol: "NVDA", Side: models.SideBuy, Quantity: dec("1"), Price: dec("900"), ExecutedAt: start},
+	} {
+		execution := execution
+		assert.NoError(t, positionHandler.Store.CreateExecution(&execution))
+	}
+
+	list := func(query string) (int, []Position) {
+		req, err := http.NewRequest("GET", "/positions"+query, nil)
+		assert.NoError(t, err)
+		req = req.WithContext(context.WithValue(req.Context(), auth.UserContextKey, 1))
+
+		rr := httptest.NewRecorder()
+		positionHandler.ListPositions(rr, req)
+
+		var positions []Position
+		if rr.Code == http.StatusOK {
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &positions))
+		}
+		return rr.Code, positions
+	}
+
+	t.Run("All Positions", func(t *testing.T) {
+		code, positions := list("")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Len(t, positions, 2)
+	})
+
+	t.Run("Filter by Status", func(t *testing.T) {
+		code, positions := list("?status=closed")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Len(t, positions, 1)
+		assert.Equal(t, "AAPL", positions[0].Symbol)
+		assert.Equal(t, "100", positions[0].NetPnL.String())
+	})
+
+	t.Run("Filter by Symbol", func(t *testing.T) {
+		code, positions := list("?symbol=msft")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Len(t, positions, 1)
+		assert.Equal(t, StatusOpen, positions[0].Status)
+	})
+
+	t.Run("Filter by Direction", func(t *testing.T) {
+		code, positions := list("?direction=short")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Empty(t, positions)
+	})
+
+	t.Run("Includes Trades", func(t *testing.T) {
+		trade := &models.Trade{UserID: 1, Symbol: "AMD", Direction: models.DirectionShort, Action: models.ActionSellShort, Quantity: dec("10"), Price: dec("150"), TradeDate: start}
+		assert.NoError(t, positionHandler.Store.CreateTrade(trade))
+
+		code, positions := list("?direction=short")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Len(t, positions, 1)
+		assert.Equal(t, "AMD", positions[0].Symbol)
+		assert.Equal(t, []int{trade.ID}, positions[0].TradeIDs)
+	})
+
+	t.Run("Filter by Account", func(t *testing.T) {
+		account := &models.Account{UserID: 1, Name: "IRA"}
+		assert.NoError(t, positionHandler.Store.CreateAccount(account))
+		execution := fill(0, "VTI", models.SideBuy, "3", "250", "0", time.Hour)
+		execution.AccountID = &account.ID
+		assert.NoError(t, positionHandler.Store.CreateExecution(&execution))
+
+		code, positions := list("?account=1")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Len(t, positions, 1)
+		assert.Equal(t, "VTI", positions[0].Symbol)
+
+		code, positions = list("?account=none")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Len(t, positions, 3)
+
+		code, _ = list("?account=ira")
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("Invalid Status", func(t *testing.T) {
+		code, _ := list("?status=pending")
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+}
+
+func TestListLotsHandler(t *testing.T) {
+	positionHandler := &PositionHandler{Store: store.NewMemoryStore()}
+	assert.NoError(t, positionHandler.Store.CreateUser(&models.User{Username: "jane_doe", Email: "jane@example.com"}))
+
+	for _, execution := range []models.Execution{
+		fill(0, "AAPL", models.SideBuy, "10", "100", "0", 0),
+		fill(0, "AAPL", models.SideBuy, "10", "120", "0", time.Minute),
+		fill(0, "AAPL", models.SideSell, "10", "130", "0", 2*time.Minute),
+	} {
+		execution := execution
+		assert.NoError(t, positionHandler.Store.CreateExecution(&execution))
+	}
+
+	list := func() LotsResponse {
+		req, err := http.NewRequest("GET", "/lots", nil)
+		assert.NoError(t, err)
+		req = req.WithContext(context.WithValue(req.Context(), auth.UserContextKey, 1))
+
+		rr := httptest.NewRecorder()
+		positionHandler.ListLots(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var response LotsResponse
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		return response
+	}
+
+	response := list()
+	assert.Equal(t, lots.MethodFIFO, response.Method)
+	assert.Len(t, response.Closed, 1)
+	assert.Equal(t, 1, response.Closed[0].OpenExecutionID)
+	assert.Equal(t, "300", response.Closed[0].NetPnL.String())
+	assert.Len(t, response.Open, 1)
+	assert.Equal(t, 2, response.Open[0].ExecutionID)
+
+	assert.NoError(t, positionHandler.Store.UpdateLotMethod(1, lots.MethodLIFO))
+
+	response = list()
+	assert.Equal(t, lots.MethodLIFO, response.Method)
+	assert.Equal(t, 2, response.Closed[0].OpenExecutionID)
+	assert.Equal(t, "100", response.Closed[0].NetPnL.String())
+	assert.Equal(t, 1, response.Open[0].ExecutionID)
+}
+
+func TestListPositionsHandler_CorporateActions(t *testing.T) {
+	positionHandler := &PositionHandler{Store: store.NewMemoryStore()}
+	assert.NoError(t, positionHandler.Store.CreateUser(&models.User{Username: "jane_doe", Email: "jane@example.com"}))
+
+	exDate := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+	for _, execution := range []models.Execution{
+		fill(0, "NVDA", models.SideBuy, "10", "800", "0", 0),
+		fill(0, "NVDA", models.SideSell, "40", "210", "0", 48*time.Hour),
+		fill(0, "VMW", models.SideBuy, "10", "140", "0", 0),
+		fill(0, "SIRI", models.SideBuy, "300", "1", "0", 0),
+		fill(0, "SIRI", models.SideSell, "100", "3.2", "0", 48*time.Hour),
+	} {
+		execution := execution
+		assert.NoError(t, positionHandler.Store.CreateExecution(&execution))
+	}
+	assert.NoError(t, positionHandler.Store.SaveCorporateActions(1, []models.CorporateAction{
+		{Date: exDate, Type: models.ActionSplit, Symbol: "NVDA", NewShares: 4, OldShares: 1},
+		{Date: exDate, Type: models.ActionMerger, Symbol: "VMW", Cash: dec("142.5")},
+		{Date: exDate, Type: models.ActionSplit, Symbol: "SIRI", NewShares: 1, OldShares: 3},
+	}))
+
+	req, err := http.NewRequest("GET", "/positions", nil)
+	assert.NoError(t, err)
+	req = req.WithContext(context.WithValue(req.Context(), auth.UserContextKey, 1))
+	rr := httptest.NewRecorder()
+	positionHandler.ListPositions(rr, req)
+
+	var positions []Position
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &positions))
+	assert.Len(t, positions, 3)
+	for _, position := range positions {
+		assert.Equal(t, StatusClosed, position.Status, position.Symbol)
+		switch position.Symbol {
+		case "NVDA":
+			assert.Equal(t, "40", position.Quantity.String(), "the buy is restated in post-split shares")
+			assert.Equal(t, "200", position.EntryAverage.String())
+			assert.Equal(t, "400", position.NetPnL.String())
+		case "VMW":
+			assert.Equal(t, exDate, *position.ClosedAt)
+			assert.Equal(t, "25", position.NetPnL.String())
+			assert.Equal(t, []int{3}, position.ExecutionIDs)
+		case "SIRI":
+			assert.Equal(t, "100", position.Quantity.String(), "a 1:3 reverse split leaves no dust")
+			assert.Equal(t, "20", position.NetPnL.String())
+		}
+	}
+}
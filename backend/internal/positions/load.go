@@ -0,0 +1,125 @@
+package positions
+
+import (
+	"errors"
+	"fmt"
+	"github.com/drewbuiltit/trading-journal/backend/internal/accounts"
+	"github.com/drewbuiltit/trading-journal/backend/internal/corporate"
+	"github.com/drewbuiltit/trading-journal/backend/internal/fx"
+	"github.com/drewbuiltit/trading-journal/backend/internal/lots"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"github.com/shopspring/decimal"
+)
+
+// Fills returns a user's executions together with their trades converted to
+// single fills, restated for the user's corporate actions.
+func Fills(s store.Store, userID int) ([]models.Execution, error) {
+	executions, err := s.ListExecutions(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	trades, err := s.ListTrades(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	fills := make([]models.Execution, 0, len(executions)+len(trades))
+	fills = append(fills, executions...)
+	for _, trade := range trades {
+		fills = append(fills, trade.Execution())
+	}
+
+	actions, err := s.ListCorporateActions(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return corporate.Apply(fills, actions), nil
+}
+
+// ForUser builds a user's positions from their current fills and lot method,
+// with P&L converted to their base currency, the legs of each spread grouped
+// into one position and futures rolls linked.
+func ForUser(s store.Store, userID int) ([]Position, lots.Config, error) {
+	config, err := lots.ConfigForUser(s, userID)
+	if err != nil {
+		return nil, lots.Config{}, err
+	}
+
+	fills, err := Fills(s, userID)
+	if err != nil {
+		return nil, lots.Config{}, err
+	}
+
+	spreads, err := s.ListSpreads(userID)
+	if err != nil {
+		return nil, lots.Config{}, err
+	}
+
+	base, table, err := fx.ForUser(s, userID)
+	if err != nil {
+		return nil, lots.Config{}, err
+	}
+
+	positions := ConvertPnL(Build(fills, config), table, base)
+	return LinkRolls(GroupSpreads(positions, spreads)), config, nil
+}
+
+// ErrOverclose is returned by CheckClose for a closing fill that would open a
+// position the other way.
+var ErrOverclose = errors.New("closing fill is more than the position held")
+
+// CheckClose returns ErrOverclose when execution sells or buys to cover more
+// than the user held open in that direction just before it. Fills without a
+// closing action are not checked. When execution is already stored, its
+// stored version is left out.
+func CheckClose(s store.Store, userID int, execution models.Execution) error {
+	if execution.Action != models.ActionSell && execution.Action != models.ActionBuyToCover {
+		return nil
+	}
+
+	fills, err := Fills(s, userID)
+	if err != nil {
+		return err
+	}
+	actions, err := s.ListCorporateActions(userID)
+	if err != nil {
+		return err
+	}
+	execution = corporate.Apply([]models.Execution{execution}, actions)[0]
+
+	prior := []models.Execution{}
+	for _, fill := range fills {
+		if (execution.ID != 0 && fill.ID == execution.ID) || (execution.TradeID != 0 && fill.TradeID == execution.TradeID) ||
+			(execution.Fingerprint != "" && fill.Fingerprint == execution.Fingerprint) {
+			continue
+		}
+		if fill.Symbol != execution.Symbol || !accounts.Same(fill.AccountID, execution.AccountID) || !sameSpread(fill.SpreadID, execution.SpreadID) {
+			continue
+		}
+		if !fill.ExecutedAt.After(execution.ExecutedAt) {
+			prior = append(prior, fill)
+		}
+	}
+
+	direction := models.ActionDirection(execution.Action)
+	held := decimal.Zero
+	for _, position := range Build(prior, lots.Config{}) {
+		if position.Status == StatusOpen && position.Direction == direction {
+			held = position.OpenQuantity
+		}
+	}
+	if execution.Quantity.GreaterThan(held) {
+		return fmt.Errorf("%w: %s %s held %s", ErrOverclose, held, execution.Symbol, direction)
+	}
+	return nil
+}
+
+func sameSpread(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,110 @@
+package positions
+
+import (
+	"github.com/drewbuiltit/trading-journal/backend/internal/lots"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/shopspring/decimal"
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+	"time"
+)
+
+// roundTrips is a random sequence of fills in one symbol that ends flat, with
+// prices in cents and costs in tenths of a cent so float64 would drift.
+type roundTrips []models.Execution
+
+func (roundTrips) Generate(r *rand.Rand, size int) reflect.Value {
+	fills := roundTrips{}
+	net := int64(0)
+	at := start
+
+	add := func(side string, quantity int64) {
+		fills = append(fills, models.Execution{
+			ID:         len(fills) + 1,
+			Symbol:     "AAPL",
+			Side:       side,
+			Quantity:   decimal.NewFromInt(quantity),
+			Price:      decimal.New(r.Int63n(100000)+1, -2),
+			Commission: decimal.New(r.Int63n(1000), -3),
+			Fees:       decimal.New(r.Int63n(100), -3),
+			ExecutedAt: at,
+		})
+		at = at.Add(time.Duration(r.Intn(3600)+1) * time.Second)
+	}
+
+	for i := 0; i < size; i++ {
+		quantity := r.Int63n(500) + 1
+		if r.Intn(2) == 0 {
+			add(models.SideBuy, quantity)
+			net += quantity
+		} else {
+			add(models.SideSell, quantity)
+			net -= quantity
+		}
+	}
+	if net > 0 {
+		add(models.SideSell, net)
+	} else if net < 0 {
+		add(models.SideBuy, -net)
+	}
+
+	return reflect.ValueOf(fills)
+}
+
+func TestBuild_NetPnLMatchesCashFlows(t *testing.T) {
+	for _, method := range []string{lots.MethodFIFO, lots.MethodLIFO, lots.MethodHighestCost, lots.MethodAverage} {
+		t.Run(method, func(t *testing.T) {
+			property := func(fills roundTrips) bool {
+				cash := decimal.Zero
+				for _, fill := range fills {
+					notional := fill.Quantity.Mul(fill.Price)
+					if fill.Side == models.SideBuy {
+						notional = notional.Neg()
+					}
+					cash = cash.Add(notional).Sub(fill.Commission).Sub(fill.Fees)
+				}
+
+				net := decimal.Zero
+				for _, position := range Build(fills, lots.Config{Method: method}) {
+					if position.Status != StatusClosed {
+						return false
+					}
+					net = net.Add(position.NetPnL)
+				}
+
+				if method == lots.MethodAverage {
+					// Average cost divides, so allow for the rounding of
+					// the repeating average price.
+					return net.Sub(cash).Abs().LessThan(decimal.New(1, -6))
+				}
+				return net.Equal(cash)
+			}
+
+			if err := quick.Check(property, &quick.Config{MaxCount: 200}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestBuild_CostsAreAllocatedExactly(t *testing.T) {
+	property := func(fills roundTrips) bool {
+		total := lots.Costs{Commission: decimal.Zero, Fees: decimal.Zero}
+		for _, fill := range fills[:len(fills)-1] {
+			total = total.Plus(lots.Costs{Commission: fill.Commission, Fees: fill.Fees})
+		}
+
+		allocated := lots.Costs{Commission: decimal.Zero, Fees: decimal.Zero}
+		for _, position := range Build(fills[:len(fills)-1], lots.Config{}) {
+			allocated = allocated.Plus(position.Costs)
+		}
+
+		return allocated.Commission.Equal(total.Commission) && allocated.Fees.Equal(total.Fees)
+	}
+
+	if err := quick.Check(property, &quick.Config{MaxCount: 200}); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,121 @@
+package positions
+
+import (
+	"github.com/drewbuiltit/trading-journal/backend/internal/accounts"
+	"github.com/drewbuiltit/trading-journal/backend/internal/lots"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// rollWindow is how far apart the two sides of a roll may be filled. Rolls
+// are normally done as one calendar spread order, so a session is ample.
+const rollWindow = 24 * time.Hour
+
+// Chain is the continuous history of a futures trade across contract rolls:
+// each position after the first re-opened the same direction in a later
+// contract of the root as the previous one closed.
+type Chain struct {
+	AccountID *int       `json:"account_id,omitempty"`
+	Root      string     `json:"root"`
+	Direction string     `json:"direction"`
+	Status    string     `json:"status"`
+	OpenedAt  time.Time  `json:"opened_at"`
+	ClosedAt  *time.Time `json:"closed_at,omitempty"`
+	Contracts []string   `json:"contracts"`
+	Rolls     int        `json:"rolls"`
+	lots.Costs
+	GrossPnL     decimal.Decimal `json:"gross_pnl"`
+	NetPnL       decimal.Decimal `json:"net_pnl"`
+	BaseGrossPnL decimal.Decimal `json:"base_gross_pnl"`
+	BaseNetPnL   decimal.Decimal `json:"base_net_pnl"`
+	Positions    []Position      `json:"positions"`
+}
+
+// LinkRolls marks each futures position that continues a rolled position
+// with the contract it was rolled from.
+func LinkRolls(positions []Position) []Position {
+	for _, chain := range link(positions) {
+		for i := 1; i < len(chain); i++ {
+			positions[chain[i]].RolledFrom = positions[chain[i-1]].Symbol
+		}
+	}
+	return positions
+}
+
+// Chains groups futures positions into their roll chains, ordered by the
+// time each chain opened. A position that was never rolled is a chain of one.
+func Chains(positions []Position) []Chain {
+	chains := []Chain{}
+	for _, indexes := range link(positions) {
+		first := positions[indexes[0]]
+		chain := Chain{
+			AccountID: first.AccountID,
+			Root:      first.Underlying,
+			Direction: first.Direction,
+			OpenedAt:  first.OpenedAt,
+			Contracts: []string{},
+			Rolls:     len(indexes) - 1,
+			Positions: []Position{},
+		}
+		for _, i := range indexes {
+			position := positions[i]
+			if len(chain.Contracts) == 0 || chain.Contracts[len(chain.Contracts)-1] != position.Symbol {
+				chain.Contracts = append(chain.Contracts, position.Symbol)
+			}
+			chain.Costs = chain.Costs.Plus(position.Costs)
+			chain.GrossPnL = chain.GrossPnL.Add(position.GrossPnL)
+			chain.NetPnL = chain.NetPnL.Add(position.NetPnL)
+			chain.BaseGrossPnL = chain.BaseGrossPnL.Add(position.BaseGrossPnL)
+			chain.BaseNetPnL = chain.BaseNetPnL.Add(position.BaseNetPnL)
+			chain.Positions = append(chain.Positions, position)
+			chain.Status = position.Status
+			chain.ClosedAt = position.ClosedAt
+		}
+		chains = append(chains, chain)
+	}
+	return chains
+}
+
+// link returns the roll chains as indexes into positions, which must be
+// ordered by the time they opened as Build returns them.
+func link(positions []Position) [][]int {
+	chains := [][]int{}
+	for i, position := range positions {
+		if !isFuture(position) {
+			continue
+		}
+
+		continued := false
+		for c, chain := range chains {
+			if rolls(positions[chain[len(chain)-1]], position) {
+				chains[c] = append(chain, i)
+				continued = true
+				break
+			}
+		}
+		if !continued {
+			chains = append(chains, []int{i})
+		}
+	}
+	return chains
+}
+
+func isFuture(position Position) bool {
+	return position.SpreadID == nil && position.Instrument != nil && position.Instrument.AssetClass == models.AssetFuture
+}
+
+// rolls reports whether next re-opened previous in a later contract.
+func rolls(previous, next Position) bool {
+	if previous.Status != StatusClosed || previous.Underlying != next.Underlying || previous.Direction != next.Direction {
+		return false
+	}
+	if !accounts.Same(previous.AccountID, next.AccountID) {
+		return false
+	}
+	if next.Instrument.ContractMonth <= previous.Instrument.ContractMonth {
+		return false
+	}
+	gap := next.OpenedAt.Sub(*previous.ClosedAt)
+	return gap <= rollWindow && gap >= -rollWindow
+}
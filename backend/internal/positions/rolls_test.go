@@ -0,0 +1,67 @@
+package positions
+
+import (
+	"github.com/drewbuiltit/trading-journal/backend/internal/lots"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestBuild_FuturesUsePointValue(t *testing.T) {
+	positions := Build([]models.Execution{
+		fill(1, "ESZ24", models.SideBuy, "2", "5800.25", "0", 0),
+		fill(2, "ESZ24", models.SideSell, "2", "5810.75", "0", time.Hour),
+	}, lots.Config{})
+
+	assert.Len(t, positions, 1)
+	assert.Equal(t, "ES", positions[0].Underlying)
+	assert.Equal(t, "50", positions[0].Multiplier.String())
+	assert.Equal(t, "1050", positions[0].GrossPnL.String())
+}
+
+func TestChains_LinkRolls(t *testing.T) {
+	positions := LinkRolls(Build([]models.Execution{
+		fill(1, "ESU24", models.SideBuy, "1", "5500", "2", 0),
+		fill(2, "ESU24", models.SideSell, "1", "5550", "2", 30*24*time.Hour),
+		fill(3, "ESZ24", models.SideBuy, "1", "5590", "2", 30*24*time.Hour+time.Second),
+		fill(4, "ESZ24", models.SideSell, "1", "5600", "2", 60*24*time.Hour),
+		fill(5, "ESH25", models.SideBuy, "1", "5650", "2", 90*24*time.Hour),
+		fill(6, "NQZ24", models.SideSell, "1", "20000", "2", 0),
+	}, lots.Config{}))
+
+	assert.Len(t, positions, 4)
+	assert.Empty(t, positions[0].RolledFrom)
+	assert.Equal(t, "ESZ24", positions[2].Symbol)
+	assert.Equal(t, "ESU24", positions[2].RolledFrom)
+	assert.Empty(t, positions[3].RolledFrom, "a re-entry days after the close is not a roll")
+
+	chains := Chains(positions)
+	assert.Len(t, chains, 3)
+
+	es := chains[0]
+	assert.Equal(t, "ES", es.Root)
+	assert.Equal(t, []string{"ESU24", "ESZ24"}, es.Contracts)
+	assert.Equal(t, 1, es.Rolls)
+	assert.Equal(t, StatusClosed, es.Status)
+	assert.Equal(t, "3000", es.GrossPnL.String())
+	assert.Equal(t, "2992", es.NetPnL.String())
+
+	assert.Equal(t, "NQ", chains[1].Root)
+	assert.Equal(t, StatusOpen, chains[1].Status)
+	assert.Equal(t, []string{"ESH25"}, chains[2].Contracts)
+}
+
+func TestChains_IgnoresEquitiesAndDirectionChanges(t *testing.T) {
+	positions := Build([]models.Execution{
+		fill(1, "CLX24", models.SideBuy, "1", "70", "0", 0),
+		fill(2, "CLX24", models.SideSell, "1", "71", "0", time.Hour),
+		fill(3, "CLZ24", models.SideSell, "1", "71", "0", time.Hour),
+		fill(4, "AAPL", models.SideBuy, "1", "200", "0", 0),
+	}, lots.Config{})
+
+	chains := Chains(positions)
+	assert.Len(t, chains, 2)
+	assert.Equal(t, 0, chains[0].Rolls)
+	assert.Equal(t, 0, chains[1].Rolls)
+}
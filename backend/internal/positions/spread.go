@@ -0,0 +1,153 @@
+package positions
+
+import (
+	"github.com/drewbuiltit/trading-journal/backend/internal/accounts"
+	"github.com/drewbuiltit/trading-journal/backend/internal/instruments"
+	"github.com/drewbuiltit/trading-journal/backend/internal/lots"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/shopspring/decimal"
+	"sort"
+	"time"
+)
+
+// GroupSpreads folds the leg positions of each spread into one position
+// carrying the legs, on their shared underlying or else the spread's name.
+// Quantity counts whole spreads and prices are net per spread, so a net
+// credit makes the spread short. Planned prices and risk stay on the legs.
+func GroupSpreads(positions []Position, spreads []models.Spread) []Position {
+	byID := make(map[int]models.Spread)
+	for _, spread := range spreads {
+		byID[spread.ID] = spread
+	}
+
+	grouped := []Position{}
+	legs := make(map[int][]Position)
+	for _, position := range positions {
+		if position.SpreadID == nil {
+			grouped = append(grouped, position)
+			continue
+		}
+		legs[*position.SpreadID] = append(legs[*position.SpreadID], position)
+	}
+
+	for id, spreadLegs := range legs {
+		grouped = append(grouped, spreadPosition(byID[id], id, spreadLegs))
+	}
+
+	sort.SliceStable(grouped, func(i, j int) bool {
+		if !grouped[i].OpenedAt.Equal(grouped[j].OpenedAt) {
+			return grouped[i].OpenedAt.Before(grouped[j].OpenedAt)
+		}
+		return grouped[i].Symbol < grouped[j].Symbol
+	})
+
+	return grouped
+}
+
+func spreadPosition(spread models.Spread, id int, legs []Position) Position {
+	spreadID := id
+	position := Position{
+		AccountID:    legs[0].AccountID,
+		Underlying:   legs[0].Underlying,
+		SpreadID:     &spreadID,
+		Kind:         spread.Kind,
+		Status:       StatusClosed,
+		Quantity:     legs[0].Quantity,
+		OpenQuantity: legs[0].OpenQuantity,
+		Multiplier:   legs[0].Multiplier,
+		Currency:     legs[0].Currency,
+		BaseCurrency: legs[0].BaseCurrency,
+		OpenedAt:     legs[0].OpenedAt,
+		ExecutionIDs: []int{},
+		Matches:      []lots.Match{},
+		OpenLots:     []lots.Lot{},
+		Legs:         legs,
+	}
+
+	var closedAt time.Time
+	netEntry, netExit := decimal.Zero, decimal.Zero
+	contracts := []models.Instrument{}
+	seen := make(map[string]bool)
+	for _, leg := range legs {
+		if leg.Underlying != position.Underlying {
+			position.Underlying = ""
+		}
+		if !accounts.Same(leg.AccountID, position.AccountID) {
+			position.AccountID = nil
+		}
+		if !leg.Multiplier.Equal(position.Multiplier) {
+			position.Multiplier = decimal.Zero
+		}
+		if leg.Currency != position.Currency {
+			position.Currency = ""
+		}
+		if leg.Status == StatusOpen {
+			position.Status = StatusOpen
+		} else if leg.ClosedAt.After(closedAt) {
+			closedAt = *leg.ClosedAt
+		}
+		if leg.OpenedAt.Before(position.OpenedAt) {
+			position.OpenedAt = leg.OpenedAt
+		}
+		position.Quantity = decimal.Min(position.Quantity, leg.Quantity)
+		position.OpenQuantity = decimal.Min(position.OpenQuantity, leg.OpenQuantity)
+
+		// Long legs are paid for on entry and sold on exit; short legs
+		// are the other way round.
+		entry := leg.EntryAverage.Mul(leg.Quantity)
+		exit := leg.ExitAverage.Mul(leg.Quantity)
+		if leg.Direction == DirectionShort {
+			entry, exit = entry.Neg(), exit.Neg()
+		}
+		netEntry = netEntry.Add(entry)
+		netExit = netExit.Add(exit)
+
+		position.Costs = position.Costs.Plus(leg.Costs)
+		position.BorrowCost = position.BorrowCost.Add(leg.BorrowCost)
+		position.GrossPnL = position.GrossPnL.Add(leg.GrossPnL)
+		position.NetPnL = position.NetPnL.Add(leg.NetPnL)
+		position.BaseGrossPnL = position.BaseGrossPnL.Add(leg.BaseGrossPnL)
+		position.BaseNetPnL = position.BaseNetPnL.Add(leg.BaseNetPnL)
+		position.FXRateMissing = position.FXRateMissing || leg.FXRateMissing
+		if position.Strategy == "" {
+			position.Strategy = leg.Strategy
+		}
+		position.Tags = models.NormalizeTags(append(position.Tags, leg.Tags...))
+		position.ExecutionIDs = append(position.ExecutionIDs, leg.ExecutionIDs...)
+		position.TradeIDs = append(position.TradeIDs, leg.TradeIDs...)
+		position.Matches = append(position.Matches, leg.Matches...)
+		position.OpenLots = append(position.OpenLots, leg.OpenLots...)
+
+		if !seen[leg.Symbol] {
+			seen[leg.Symbol] = true
+			contracts = append(contracts, instruments.Lookup(leg.Symbol))
+		}
+	}
+
+	position.Symbol = position.Underlying
+	if position.Symbol == "" {
+		position.Symbol = spread.Name
+	}
+	if position.Kind == "" {
+		position.Kind = instruments.Classify(contracts)
+	}
+
+	position.Direction = DirectionLong
+	if netEntry.IsNegative() {
+		position.Direction = DirectionShort
+		netEntry, netExit = netEntry.Neg(), netExit.Neg()
+	}
+	if position.Quantity.IsPositive() {
+		position.EntryAverage = netEntry.Div(position.Quantity)
+		if position.Status == StatusClosed {
+			position.ExitAverage = netExit.Div(position.Quantity)
+		}
+	}
+
+	if position.Status == StatusClosed {
+		position.ClosedAt = &closedAt
+		position.HoldingSeconds = int64(closedAt.Sub(position.OpenedAt).Seconds())
+	}
+
+	return position
+}
@@ -0,0 +1,181 @@
+package spreads
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/drewbuiltit/trading-journal/backend/internal/auth"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"github.com/gorilla/mux"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type SpreadHandler struct {
+	Store store.Store
+}
+
+type SpreadRequest struct {
+	Name string `json:"name"`
+	Kind string `json:"kind,omitempty"`
+}
+
+func (req *SpreadRequest) validate() error {
+	if strings.TrimSpace(req.Name) == "" {
+		return errors.New("name is required")
+	}
+	if req.Kind != "" && !models.ValidSpreadKind(strings.ToLower(req.Kind)) {
+		return errors.New("kind must be vertical, calendar, diagonal, straddle, strangle, iron_condor or custom")
+	}
+	return nil
+}
+
+// apply copies the request onto spread. An empty kind is classified from the
+// legs when positions are built.
+func (req *SpreadRequest) apply(spread *models.Spread) {
+	spread.Name = strings.TrimSpace(req.Name)
+	spread.Kind = strings.ToLower(req.Kind)
+}
+
+func (h *SpreadHandler) CreateSpread(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	var req SpreadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	if err := req.validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	spread := &models.Spread{UserID: userID}
+	req.apply(spread)
+
+	if err := h.Store.CreateSpread(spread); err != nil {
+		http.Error(w, "Error creating spread", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(spread)
+}
+
+func (h *SpreadHandler) ListSpreads(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	spreads, err := h.Store.ListSpreads(userID)
+	if err != nil {
+		http.Error(w, "Error listing spreads", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(spreads)
+}
+
+func (h *SpreadHandler) GetSpread(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	spreadID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid spread ID", http.StatusBadRequest)
+		return
+	}
+
+	spread, err := h.Store.GetSpread(userID, spreadID)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "Spread not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error fetching spread", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(spread)
+}
+
+func (h *SpreadHandler) UpdateSpread(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	spreadID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid spread ID", http.StatusBadRequest)
+		return
+	}
+
+	var req SpreadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	if err := req.validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	spread := &models.Spread{ID: spreadID, UserID: userID}
+	req.apply(spread)
+
+	err = h.Store.UpdateSpread(spread)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "Spread not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error updating spread", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(spread)
+}
+
+// DeleteSpread removes the grouping only; the legs stay and are reported as
+// outright positions again.
+func (h *SpreadHandler) DeleteSpread(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	spreadID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid spread ID", http.StatusBadRequest)
+		return
+	}
+
+	err = h.Store.DeleteSpread(userID, spreadID)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "Spread not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error deleting spread", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
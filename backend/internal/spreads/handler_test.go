@@ -0,0 +1,99 @@
+package spreads
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/drewbuiltit/trading-journal/backend/internal/auth"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newSpreadRequest(t *testing.T, method, url string, body []byte, userID int, vars map[string]string) *http.Request {
+	req, err := http.NewRequest(method, url, bytes.NewBuffer(body))
+	assert.NoError(t, err)
+	req = req.WithContext(context.WithValue(req.Context(), auth.UserContextKey, userID))
+	if vars != nil {
+		req = mux.SetURLVars(req, vars)
+	}
+	return req
+}
+
+func TestSpreadHandler(t *testing.T) {
+	spreadHandler := &SpreadHandler{Store: store.NewMemoryStore()}
+
+	t.Run("Creation", func(t *testing.T) {
+		body, _ := json.Marshal(SpreadRequest{Name: " SPY condor ", Kind: "IRON_CONDOR"})
+
+		rr := httptest.NewRecorder()
+		spreadHandler.CreateSpread(rr, newSpreadRequest(t, "POST", "/spreads", body, 1, nil))
+		assert.Equal(t, http.StatusCreated, rr.Code)
+
+		var spread models.Spread
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &spread))
+		assert.Equal(t, 1, spread.ID)
+		assert.Equal(t, "SPY condor", spread.Name)
+		assert.Equal(t, models.SpreadIronCondor, spread.Kind)
+	})
+
+	t.Run("Creation with Invalid Kind", func(t *testing.T) {
+		body, _ := json.Marshal(SpreadRequest{Name: "butterfly", Kind: "butterfly"})
+
+		rr := httptest.NewRecorder()
+		spreadHandler.CreateSpread(rr, newSpreadRequest(t, "POST", "/spreads", body, 1, nil))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Creation without Name", func(t *testing.T) {
+		body, _ := json.Marshal(SpreadRequest{})
+
+		rr := httptest.NewRecorder()
+		spreadHandler.CreateSpread(rr, newSpreadRequest(t, "POST", "/spreads", body, 1, nil))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, "name is required\n", rr.Body.String())
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		body, _ := json.Marshal(SpreadRequest{Name: "SPY wide condor"})
+
+		rr := httptest.NewRecorder()
+		spreadHandler.UpdateSpread(rr, newSpreadRequest(t, "PUT", "/spreads/1", body, 1, map[string]string{"id": "1"}))
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		rr = httptest.NewRecorder()
+		spreadHandler.GetSpread(rr, newSpreadRequest(t, "GET", "/spreads/1", nil, 1, map[string]string{"id": "1"}))
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var spread models.Spread
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &spread))
+		assert.Equal(t, "SPY wide condor", spread.Name)
+		assert.Empty(t, spread.Kind)
+	})
+
+	t.Run("Scoped to User", func(t *testing.T) {
+		vars := map[string]string{"id": "1"}
+
+		rr := httptest.NewRecorder()
+		spreadHandler.GetSpread(rr, newSpreadRequest(t, "GET", "/spreads/1", nil, 2, vars))
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+
+		rr = httptest.NewRecorder()
+		spreadHandler.ListSpreads(rr, newSpreadRequest(t, "GET", "/spreads", nil, 2, nil))
+		assert.Equal(t, "[]\n", rr.Body.String())
+
+		rr = httptest.NewRecorder()
+		spreadHandler.DeleteSpread(rr, newSpreadRequest(t, "DELETE", "/spreads/1", nil, 2, vars))
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		spreadHandler.DeleteSpread(rr, newSpreadRequest(t, "DELETE", "/spreads/1", nil, 1, map[string]string{"id": "1"}))
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+	})
+}
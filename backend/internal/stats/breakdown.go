@@ -0,0 +1,178 @@
+package stats
+
+import (
+	"fmt"
+	"github.com/drewbuiltit/trading-journal/backend/internal/fx"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/drewbuiltit/trading-journal/backend/internal/positions"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"github.com/shopspring/decimal"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Bucket summarizes the closed positions that fell in one slice of time.
+type Bucket struct {
+	Key     string           `json:"key"`
+	Trades  int              `json:"trades"`
+	Wins    int              `json:"wins"`
+	WinRate *decimal.Decimal `json:"win_rate"`
+	NetPnL  decimal.Decimal  `json:"net_pnl"`
+}
+
+// Breakdown groups closed positions by when they were entered, in the
+// user's time zone, and by how long they were held. Every hour of the day,
+// day of the week and holding time bucket is listed, with or without trades;
+// months are the calendar months, as YYYY-MM, that had an entry.
+type Breakdown struct {
+	BaseCurrency string   `json:"base_currency"`
+	Timezone     string   `json:"timezone"`
+	HourOfDay    []Bucket `json:"hour_of_day"`
+	DayOfWeek    []Bucket `json:"day_of_week"`
+	Month        []Bucket `json:"month"`
+	HoldingTime  []Bucket `json:"holding_time"`
+	// FXRateMissing is set when a position had no rate into the base
+	// currency. Such positions are counted in Unconverted and left out of
+	// the buckets.
+	FXRateMissing bool `json:"fx_rate_missing,omitempty"`
+	Unconverted   int  `json:"unconverted,omitempty"`
+}
+
+// holdingTimes are the upper bounds of the holding time buckets; positions
+// held longer than the last fall in a final open-ended bucket.
+var holdingTimes = []struct {
+	key   string
+	under time.Duration
+}{
+	{"under_5m", 5 * time.Minute},
+	{"5m_to_1h", time.Hour},
+	{"1h_to_1d", 24 * time.Hour},
+	{"1d_to_1w", 7 * 24 * time.Hour},
+	{"1w_to_30d", 30 * 24 * time.Hour},
+}
+
+// LocationOf returns the user's time zone, UTC when they have not set one.
+func LocationOf(user *models.User) *time.Location {
+	if user == nil || user.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// BreakdownForUser builds the user's positions and breaks down the closed
+// ones the filter matches, in loc or, when it is nil, the user's time zone.
+func BreakdownForUser(s store.Store, userID int, filter positions.Filter, loc *time.Location) (Breakdown, error) {
+	built, _, err := positions.ForUser(s, userID)
+	if err != nil {
+		return Breakdown{}, err
+	}
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return Breakdown{}, err
+	}
+	if loc == nil {
+		loc = LocationOf(user)
+	}
+
+	filter.Status = positions.StatusClosed
+	matched := []positions.Position{}
+	for _, position := range built {
+		if filter.Match(position) {
+			matched = append(matched, position)
+		}
+	}
+	breakdown := ComputeBreakdown(matched, loc)
+	breakdown.BaseCurrency = fx.BaseCurrencyOf(user)
+	return breakdown, nil
+}
+
+// ComputeBreakdown buckets closed positions by their entry time in loc and
+// their holding time. Open positions are skipped.
+func ComputeBreakdown(built []positions.Position, loc *time.Location) Breakdown {
+	breakdown := Breakdown{
+		Timezone:    loc.String(),
+		HourOfDay:   make([]Bucket, 24),
+		DayOfWeek:   make([]Bucket, 7),
+		Month:       []Bucket{},
+		HoldingTime: make([]Bucket, len(holdingTimes)+1),
+	}
+	for hour := range breakdown.HourOfDay {
+		breakdown.HourOfDay[hour].Key = fmt.Sprintf("%02d", hour)
+	}
+	for day := range breakdown.DayOfWeek {
+		// Weeks start on Monday.
+		breakdown.DayOfWeek[day].Key = strings.ToLower(time.Weekday((day + 1) % 7).String())
+	}
+	for i, holding := range holdingTimes {
+		breakdown.HoldingTime[i].Key = holding.key
+	}
+	breakdown.HoldingTime[len(holdingTimes)].Key = "over_30d"
+
+	months := make(map[string]*Bucket)
+	for _, position := range built {
+		if position.Status != positions.StatusClosed {
+			continue
+		}
+		if position.FXRateMissing {
+			breakdown.FXRateMissing = true
+			breakdown.Unconverted++
+			continue
+		}
+
+		opened := position.OpenedAt.In(loc)
+		month := opened.Format("2006-01")
+		if months[month] == nil {
+			months[month] = &Bucket{Key: month}
+		}
+		holding := len(holdingTimes)
+		for i, bucket := range holdingTimes {
+			if time.Duration(position.HoldingSeconds)*time.Second < bucket.under {
+				holding = i
+				break
+			}
+		}
+
+		for _, bucket := range []*Bucket{
+			&breakdown.HourOfDay[opened.Hour()],
+			&breakdown.DayOfWeek[(int(opened.Weekday())+6)%7],
+			months[month],
+			&breakdown.HoldingTime[holding],
+		} {
+			bucket.add(position.BaseNetPnL)
+		}
+	}
+
+	for _, bucket := range months {
+		breakdown.Month = append(breakdown.Month, *bucket)
+	}
+	sort.Slice(breakdown.Month, func(i, j int) bool { return breakdown.Month[i].Key < breakdown.Month[j].Key })
+
+	for _, buckets := range [][]Bucket{breakdown.HourOfDay, breakdown.DayOfWeek, breakdown.Month, breakdown.HoldingTime} {
+		for i := range buckets {
+			buckets[i].rate()
+		}
+	}
+	return breakdown
+}
+
+func (b *Bucket) add(pnl decimal.Decimal) {
+	b.Trades++
+	b.NetPnL = b.NetPnL.Add(pnl)
+	if pnl.IsPositive() {
+		b.Wins++
+	}
+}
+
+// rate works out the win rate once every trade is in, leaving it out of
+// empty buckets.
+func (b *Bucket) rate() {
+	if b.Trades > 0 {
+		rate := decimal.NewFromInt(int64(b.Wins)).DivRound(decimal.NewFromInt(int64(b.Trades)), precision)
+		b.WinRate = &rate
+	}
+}
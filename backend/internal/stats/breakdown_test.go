@@ -0,0 +1,72 @@
+package stats
+
+import (
+	"github.com/drewbuiltit/trading-journal/backend/internal/positions"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func entered(pnl string, openedAt time.Time, held time.Duration) positions.Position {
+	position := closed(pnl, 0)
+	position.OpenedAt = openedAt
+	position.HoldingSeconds = int64(held.Seconds())
+	return position
+}
+
+func TestComputeBreakdown(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	assert.NoError(t, err)
+
+	built := []positions.Position{
+		entered("100", time.Date(2024, 5, 1, 14, 30, 0, 0, time.UTC), 2*time.Minute),
+		entered("-50", time.Date(2024, 5, 1, 19, 0, 0, 0, time.UTC), 3*time.Hour),
+		// Friday evening in New York is already Saturday, and June, in UTC.
+		entered("-20", time.Date(2024, 6, 1, 2, 0, 0, 0, time.UTC), 10*24*time.Hour),
+		entered("30", time.Date(2024, 6, 3, 13, 45, 0, 0, time.UTC), 60*24*time.Hour),
+		{Status: positions.StatusOpen, OpenedAt: start, BaseNetPnL: dec("999")},
+	}
+	unconverted := entered("0", time.Date(2024, 5, 1, 14, 0, 0, 0, time.UTC), time.Hour)
+	unconverted.FXRateMissing = true
+	built = append(built, unconverted)
+
+	breakdown := ComputeBreakdown(built, newYork)
+	assert.Equal(t, "America/New_York", breakdown.Timezone)
+	assert.True(t, breakdown.FXRateMissing)
+	assert.Equal(t, 1, breakdown.Unconverted)
+
+	assert.Len(t, breakdown.HourOfDay, 24)
+	assert.Equal(t, "10", breakdown.HourOfDay[10].Key)
+	assert.Equal(t, 1, breakdown.HourOfDay[10].Trades)
+	assert.Equal(t, "1", breakdown.HourOfDay[10].WinRate.String())
+	assert.Equal(t, "-50", breakdown.HourOfDay[15].NetPnL.String(), "the afternoon trade lost")
+	assert.Equal(t, "0", breakdown.HourOfDay[15].WinRate.String())
+	assert.Equal(t, 1, breakdown.HourOfDay[22].Trades)
+	assert.Equal(t, 0, breakdown.HourOfDay[2].Trades)
+	assert.Nil(t, breakdown.HourOfDay[2].WinRate, "empty buckets have no win rate")
+
+	assert.Len(t, breakdown.DayOfWeek, 7)
+	assert.Equal(t, "monday", breakdown.DayOfWeek[0].Key)
+	assert.Equal(t, "sunday", breakdown.DayOfWeek[6].Key)
+	assert.Equal(t, 2, breakdown.DayOfWeek[2].Trades)
+	assert.Equal(t, "0.5", breakdown.DayOfWeek[2].WinRate.String())
+	assert.Equal(t, "50", breakdown.DayOfWeek[2].NetPnL.String())
+	assert.Equal(t, 1, breakdown.DayOfWeek[4].Trades, "entered on a Friday in New York")
+	assert.Equal(t, 0, breakdown.DayOfWeek[5].Trades)
+
+	assert.Len(t, breakdown.Month, 2)
+	assert.Equal(t, "2024-05", breakdown.Month[0].Key)
+	assert.Equal(t, 3, breakdown.Month[0].Trades)
+	assert.Equal(t, "30", breakdown.Month[0].NetPnL.String())
+	assert.Equal(t, "2024-06", breakdown.Month[1].Key)
+
+	holding := map[string]int{}
+	for _, bucket := range breakdown.HoldingTime {
+		holding[bucket.Key] = bucket.Trades
+	}
+	assert.Equal(t, map[string]int{"under_5m": 1, "5m_to_1h": 0, "1h_to_1d": 1, "1d_to_1w": 0, "1w_to_30d": 1, "over_30d": 1}, holding)
+
+	breakdown = ComputeBreakdown(built, time.UTC)
+	assert.Equal(t, 1, breakdown.DayOfWeek[5].Trades, "a Saturday in UTC")
+	assert.Equal(t, 2, breakdown.Month[1].Trades)
+}
@@ -0,0 +1,196 @@
+package stats
+
+import (
+	"github.com/drewbuiltit/trading-journal/backend/internal/fx"
+	"github.com/drewbuiltit/trading-journal/backend/internal/positions"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"github.com/shopspring/decimal"
+	"sort"
+	"time"
+)
+
+const (
+	IntervalDay   = "day"
+	IntervalTrade = "trade"
+)
+
+// EquityPoint is the realized P&L at the end of a day, or at each close for
+// the trade interval, with the drawdown from the best point before it.
+// Equity and DrawdownPercent are only given when a starting capital is.
+type EquityPoint struct {
+	Time            time.Time        `json:"time"`
+	PnL             decimal.Decimal  `json:"pnl"`
+	Cumulative      decimal.Decimal  `json:"cumulative_pnl"`
+	Equity          *decimal.Decimal `json:"equity,omitempty"`
+	Peak            decimal.Decimal  `json:"peak"`
+	Drawdown        decimal.Decimal  `json:"drawdown"`
+	DrawdownPercent *decimal.Decimal `json:"drawdown_percent,omitempty"`
+}
+
+// Drawdown is a fall from a peak in cumulative P&L: Start is the peak,
+// Trough the lowest point before equity got back to the peak at Recovery.
+// An unrecovered drawdown has no Recovery and is under water until the last
+// point.
+type Drawdown struct {
+	Amount                decimal.Decimal  `json:"amount"`
+	Percent               *decimal.Decimal `json:"percent,omitempty"`
+	Start                 time.Time        `json:"start"`
+	Trough                time.Time        `json:"trough"`
+	Recovery              *time.Time       `json:"recovery,omitempty"`
+	TimeUnderWaterSeconds int64            `json:"time_under_water_seconds"`
+}
+
+// EquityCurve is the cumulative realized P&L of a user's positions in the
+// base currency, starting from zero at the first close in range.
+type EquityCurve struct {
+	BaseCurrency string           `json:"base_currency"`
+	Interval     string           `json:"interval"`
+	Capital      *decimal.Decimal `json:"capital,omitempty"`
+	Points       []EquityPoint    `json:"points"`
+	// MaxDrawdown is the deepest drawdown by amount.
+	MaxDrawdown     *Drawdown       `json:"max_drawdown,omitempty"`
+	CurrentDrawdown decimal.Decimal `json:"current_drawdown"`
+	// TimeUnderWaterSeconds is the total time spent below a previous peak.
+	TimeUnderWaterSeconds int64 `json:"time_under_water_seconds"`
+	// FXRateMissing is set when a close had no rate into the base currency,
+	// so its P&L is left out.
+	FXRateMissing bool `json:"fx_rate_missing,omitempty"`
+}
+
+// EquityForUser builds the user's positions and returns the equity curve of
+// those the filter matches. The filter's dates select closes rather than
+// positions, so that partial closes of a position count on their own day.
+func EquityForUser(s store.Store, userID int, filter positions.Filter, interval string, capital *decimal.Decimal) (EquityCurve, error) {
+	built, _, err := positions.ForUser(s, userID)
+	if err != nil {
+		return EquityCurve{}, err
+	}
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return EquityCurve{}, err
+	}
+
+	from, to := filter.From, filter.To
+	filter.From, filter.To = nil, nil
+	matched := []positions.Position{}
+	for _, position := range built {
+		if filter.Match(position) {
+			matched = append(matched, position)
+		}
+	}
+
+	curve := Curve(matched, interval, capital, from, to)
+	curve.BaseCurrency = fx.BaseCurrencyOf(user)
+	return curve, nil
+}
+
+// realized is the realized P&L of one lot match.
+type realized struct {
+	at  time.Time
+	pnl decimal.Decimal
+}
+
+// Curve accumulates the realized P&L of the positions' lot matches closed
+// between from and to, inclusive days either of which may be nil, into
+// points at the given interval, and measures its drawdowns. With a
+// capital, equity and drawdown percentages are measured against it plus
+// the P&L so far.
+func Curve(built []positions.Position, interval string, capital *decimal.Decimal, from, to *time.Time) EquityCurve {
+	curve := EquityCurve{
+		Interval:        interval,
+		Capital:         capital,
+		Points:          []EquityPoint{},
+		CurrentDrawdown: decimal.Zero,
+	}
+
+	closes := []realized{}
+	for _, position := range built {
+		curve.FXRateMissing = curve.FXRateMissing || position.FXRateMissing
+		for _, match := range position.Matches {
+			if !match.FXRate.IsPositive() {
+				continue
+			}
+			day := truncate(match.ClosedAt)
+			if (from != nil && day.Before(*from)) || (to != nil && day.After(*to)) {
+				continue
+			}
+			closes = append(closes, realized{at: match.ClosedAt, pnl: match.BaseNetPnL})
+		}
+	}
+	sort.SliceStable(closes, func(i, j int) bool { return closes[i].at.Before(closes[j].at) })
+
+	for _, c := range closes {
+		at := c.at.UTC()
+		if interval == IntervalDay {
+			at = truncate(at)
+		}
+		if n := len(curve.Points); n > 0 && curve.Points[n-1].Time.Equal(at) {
+			curve.Points[n-1].PnL = curve.Points[n-1].PnL.Add(c.pnl)
+			continue
+		}
+		curve.Points = append(curve.Points, EquityPoint{Time: at, PnL: c.pnl})
+	}
+
+	cumulative, peak := decimal.Zero, decimal.Zero
+	var current *Drawdown
+	for i := range curve.Points {
+		point := &curve.Points[i]
+		cumulative = cumulative.Add(point.PnL)
+		point.Cumulative = cumulative
+
+		if cumulative.GreaterThanOrEqual(peak) {
+			if current != nil {
+				recovery := point.Time
+				current.Recovery = &recovery
+				current.TimeUnderWaterSeconds = int64(recovery.Sub(current.Start).Seconds())
+				curve.TimeUnderWaterSeconds += current.TimeUnderWaterSeconds
+				current = nil
+			}
+			peak = cumulative
+		}
+		point.Peak = peak
+		point.Drawdown = cumulative.Sub(peak)
+
+		if capital != nil {
+			equity := capital.Add(cumulative)
+			point.Equity = &equity
+			if base := capital.Add(peak); base.IsPositive() {
+				percent := point.Drawdown.DivRound(base, precision)
+				point.DrawdownPercent = &percent
+			}
+		}
+
+		if point.Drawdown.IsNegative() {
+			if current == nil {
+				// The drawdown starts at the last peak, or at the first
+				// point when it was never above zero.
+				start := point.Time
+				if i > 0 {
+					start = curve.Points[i-1].Time
+				}
+				current = &Drawdown{Amount: decimal.Zero, Start: start}
+			}
+			if point.Drawdown.LessThan(current.Amount) {
+				current.Amount = point.Drawdown
+				current.Percent = point.DrawdownPercent
+				current.Trough = point.Time
+			}
+			if curve.MaxDrawdown == nil || current.Amount.LessThan(curve.MaxDrawdown.Amount) {
+				curve.MaxDrawdown = current
+			}
+		}
+	}
+
+	if current != nil {
+		last := curve.Points[len(curve.Points)-1].Time
+		current.TimeUnderWaterSeconds = int64(last.Sub(current.Start).Seconds())
+		curve.TimeUnderWaterSeconds += current.TimeUnderWaterSeconds
+		curve.CurrentDrawdown = cumulative.Sub(peak)
+	}
+	return curve
+}
+
+func truncate(at time.Time) time.Time {
+	at = at.UTC()
+	return time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC)
+}
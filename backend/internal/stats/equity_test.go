@@ -0,0 +1,61 @@
+package stats
+
+import (
+	"github.com/drewbuiltit/trading-journal/backend/internal/lots"
+	"github.com/drewbuiltit/trading-journal/backend/internal/positions"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func matches(closes ...lots.Match) positions.Position {
+	return positions.Position{Matches: closes}
+}
+
+func match(pnl string, at time.Time) lots.Match {
+	return lots.Match{ClosedAt: at, FXRate: dec("1"), BaseNetPnL: dec(pnl)}
+}
+
+func TestCurve(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 5, 1+d, 0, 0, 0, 0, time.UTC) }
+	built := []positions.Position{
+		matches(match("100", day(0).Add(10*time.Hour)), match("-200", day(1).Add(15*time.Hour))),
+		matches(match("50", day(0).Add(15*time.Hour)), match("-100", day(2).Add(15*time.Hour))),
+		matches(match("400", day(3).Add(15*time.Hour)), match("-50", day(4).Add(15*time.Hour))),
+	}
+	capital := dec("1000")
+
+	curve := Curve(built, IntervalDay, &capital, nil, nil)
+	assert.Len(t, curve.Points, 5)
+	assert.Equal(t, day(0), curve.Points[0].Time)
+	assert.Equal(t, "150", curve.Points[0].Cumulative.String())
+	assert.Equal(t, "-150", curve.Points[2].Cumulative.String())
+	assert.Equal(t, "150", curve.Points[2].Peak.String())
+	assert.Equal(t, "-300", curve.Points[2].Drawdown.String())
+	assert.Equal(t, "850", curve.Points[2].Equity.String())
+	assert.Equal(t, "-0.2609", curve.Points[2].DrawdownPercent.String())
+
+	deepest := curve.MaxDrawdown
+	assert.Equal(t, "-300", deepest.Amount.String())
+	assert.Equal(t, "-0.2609", deepest.Percent.String())
+	assert.Equal(t, day(0), deepest.Start)
+	assert.Equal(t, day(2), deepest.Trough)
+	assert.Equal(t, day(3), *deepest.Recovery)
+	assert.Equal(t, int64(3*24*60*60), deepest.TimeUnderWaterSeconds)
+
+	assert.Equal(t, "-50", curve.CurrentDrawdown.String())
+	assert.Equal(t, int64(4*24*60*60), curve.TimeUnderWaterSeconds, "three days in the first drawdown and one in the second")
+
+	curve = Curve(built, IntervalTrade, nil, nil, nil)
+	assert.Len(t, curve.Points, 6)
+	assert.Equal(t, day(0).Add(10*time.Hour), curve.Points[0].Time)
+	assert.Nil(t, curve.Points[0].Equity)
+	assert.Nil(t, curve.MaxDrawdown.Percent)
+
+	from, to := day(1), day(2)
+	curve = Curve(built, IntervalDay, nil, &from, &to)
+	assert.Len(t, curve.Points, 2)
+	assert.Equal(t, "-200", curve.Points[0].Cumulative.String(), "the curve starts from zero at the start of the range")
+	assert.Equal(t, day(1), curve.MaxDrawdown.Start, "a curve that starts with a loss is under water from its first point")
+	assert.Nil(t, curve.MaxDrawdown.Recovery)
+}
@@ -0,0 +1,248 @@
+package stats
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/drewbuiltit/trading-journal/backend/internal/auth"
+	"github.com/drewbuiltit/trading-journal/backend/internal/positions"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"github.com/shopspring/decimal"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type StatsHandler struct {
+	Store store.Store
+}
+
+type TimezoneRequest struct {
+	Timezone string `json:"timezone"`
+}
+
+type TimezoneResponse struct {
+	Timezone string `json:"timezone"`
+}
+
+// GetStats returns performance statistics over the user's closed positions,
+// narrowed by the positions listing filters: ?from= and ?to= on the close
+// date, ?symbol=, ?strategy=, ?tag=, ?direction= and ?account=.
+func (h *StatsHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	filter, err := positions.ParseFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	stats, err := ForUser(h.Store, userID, filter)
+	if err != nil {
+		http.Error(w, "Error computing stats", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+// GetEquityCurve returns the cumulative realized P&L of the user's positions
+// with its drawdowns, a point a day or, with ?interval=trade, a point a
+// close. ?capital= is the equity to start from, which gives drawdowns as a
+// percentage. The positions listing filters apply, with ?from= and ?to=
+// selecting closes.
+func (h *StatsHandler) GetEquityCurve(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	filter, err := positions.ParseFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	interval := strings.ToLower(r.URL.Query().Get("interval"))
+	switch interval {
+	case "":
+		interval = IntervalDay
+	case IntervalDay, IntervalTrade:
+	default:
+		http.Error(w, "interval must be day or trade", http.StatusBadRequest)
+		return
+	}
+	var capital *decimal.Decimal
+	if value := r.URL.Query().Get("capital"); value != "" {
+		parsed, err := decimal.NewFromString(value)
+		if err != nil || !parsed.IsPositive() {
+			http.Error(w, "capital must be a positive number", http.StatusBadRequest)
+			return
+		}
+		capital = &parsed
+	}
+
+	curve, err := EquityForUser(h.Store, userID, filter, interval, capital)
+	if err != nil {
+		http.Error(w, "Error computing equity curve", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(curve)
+}
+
+// GetRisk returns risk-adjusted measures of the user's results: Sharpe,
+// Sortino and Calmar ratios of daily returns on ?capital=, and the SQN of
+// their closed positions. ?risk_free= is the annual risk-free rate as a
+// fraction, zero by default, and ?periods= the number of daily returns in a
+// year, 252 by default. The positions listing filters apply, with ?from= and
+// ?to= selecting closes.
+func (h *StatsHandler) GetRisk(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	query := r.URL.Query()
+	filter, err := positions.ParseFilter(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var capital *decimal.Decimal
+	if value := query.Get("capital"); value != "" {
+		parsed, err := decimal.NewFromString(value)
+		if err != nil || !parsed.IsPositive() {
+			http.Error(w, "capital must be a positive number", http.StatusBadRequest)
+			return
+		}
+		capital = &parsed
+	}
+	riskFree := decimal.Zero
+	if value := query.Get("risk_free"); value != "" {
+		riskFree, err = decimal.NewFromString(value)
+		if err != nil || riskFree.IsNegative() || riskFree.GreaterThanOrEqual(decimal.NewFromInt(1)) {
+			http.Error(w, "risk_free must be a rate between 0 and 1", http.StatusBadRequest)
+			return
+		}
+	}
+	periods := TradingDays
+	if value := query.Get("periods"); value != "" {
+		periods, err = strconv.Atoi(value)
+		if err != nil || periods <= 0 {
+			http.Error(w, "periods must be a positive integer", http.StatusBadRequest)
+			return
+		}
+	}
+
+	risk, err := RiskForUser(h.Store, userID, filter, capital, riskFree, periods)
+	if err != nil {
+		http.Error(w, "Error computing risk metrics", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(risk)
+}
+
+// GetBreakdown returns the user's closed positions grouped by the hour and
+// day of the week they were entered, their month and how long they were
+// held. Times are in the user's time zone unless ?timezone= names another.
+// The positions listing filters apply.
+func (h *StatsHandler) GetBreakdown(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	filter, err := positions.ParseFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var loc *time.Location
+	if value := r.URL.Query().Get("timezone"); value != "" {
+		loc, err = loadTimezone(value)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	breakdown, err := BreakdownForUser(h.Store, userID, filter, loc)
+	if err != nil {
+		http.Error(w, "Error computing breakdown", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(breakdown)
+}
+
+func (h *StatsHandler) GetTimezone(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	user, err := h.Store.GetUserByID(userID)
+	if err != nil {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(TimezoneResponse{Timezone: LocationOf(user).String()})
+}
+
+// SetTimezone sets the time zone the user's breakdowns are reported in.
+func (h *StatsHandler) SetTimezone(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	var req TimezoneRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	loc, err := loadTimezone(strings.TrimSpace(req.Timezone))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.Store.UpdateTimezone(userID, loc.String())
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error updating time zone", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(TimezoneResponse{Timezone: loc.String()})
+}
+
+// loadTimezone loads a named time zone, refusing the server's own local one.
+func loadTimezone(name string) (*time.Location, error) {
+	loc, err := time.LoadLocation(name)
+	if err != nil || name == "" || strings.EqualFold(name, "local") {
+		return nil, errors.New("timezone must be an IANA time zone name")
+	}
+	return loc, nil
+}
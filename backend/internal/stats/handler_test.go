@@ -0,0 +1,243 @@
+package stats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/drewbuiltit/trading-journal/backend/internal/auth"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetStatsHandler(t *testing.T) {
+	statsHandler := &StatsHandler{Store: store.NewMemoryStore()}
+	assert.NoError(t, statsHandler.Store.CreateUser(&models.User{Username: "jane_doe", Email: "jane@example.com"}))
+	account := &models.Account{UserID: 1, Name: "IRA"}
+	assert.NoError(t, statsHandler.Store.CreateAccount(account))
+
+	trade := func(symbol, action, quantity, price string, day int, strategy string, tags ...string) {
+		assert.NoError(t, statsHandler.Store.CreateTrade(&models.Trade{
+			UserID: 1, Symbol: symbol, Action: action, Quantity: dec(quantity), Price: dec(price),
+			TradeDate: start.AddDate(0, 0, day), Strategy: strategy, Tags: tags,
+		}))
+	}
+	trade("AAPL", models.ActionBuy, "10", "100", 0, "Breakout", "earnings")
+	trade("AAPL", models.ActionSell, "10", "110", 1, "")
+	trade("MSFT", models.ActionBuy, "5", "400", 10, "Pullback")
+	trade("MSFT", models.ActionSell, "5", "380", 11, "", "mistake")
+	trade("NVDA", models.ActionBuy, "1", "900", 12, "Breakout")
+
+	execution := &models.Execution{UserID: 1, AccountID: &account.ID, Symbol: "TSLA", Side: models.SideSell, Action: models.ActionSellShort,
+		Quantity: dec("2"), Price: dec("200"), ExecutedAt: start.AddDate(0, 0, 20), Strategy: "Fade"}
+	assert.NoError(t, statsHandler.Store.CreateExecution(execution))
+	assert.NoError(t, statsHandler.Store.CreateExecution(&models.Execution{UserID: 1, AccountID: &account.ID, Symbol: "TSLA", Side: models.SideBuy,
+		Action: models.ActionBuyToCover, Quantity: dec("2"), Price: dec("190"), ExecutedAt: start.AddDate(0, 0, 21)}))
+
+	get := func(query string) (int, Stats) {
+		req, err := http.NewRequest("GET", "/stats"+query, nil)
+		assert.NoError(t, err)
+		req = req.WithContext(context.WithValue(req.Context(), auth.UserContextKey, 1))
+
+		rr := httptest.NewRecorder()
+		statsHandler.GetStats(rr, req)
+
+		var stats Stats
+		if rr.Code == http.StatusOK {
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &stats))
+		}
+		return rr.Code, stats
+	}
+
+	code, stats := get("")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 3, stats.Trades, "open positions are left out")
+	assert.Equal(t, "20", stats.TotalNetPnL.String())
+
+	_, stats = get("?strategy=breakout")
+	assert.Equal(t, 1, stats.Trades)
+	assert.Equal(t, "100", stats.TotalNetPnL.String())
+
+	_, stats = get("?tag=mistake")
+	assert.Equal(t, 1, stats.Trades, "a tag on any fill tags the position")
+	assert.Equal(t, "-100", stats.TotalNetPnL.String())
+
+	_, stats = get("?from=2024-05-10&to=2024-05-15")
+	assert.Equal(t, 1, stats.Trades)
+	assert.Equal(t, "-100", stats.TotalNetPnL.String())
+
+	_, stats = get(fmt.Sprintf("?account=%d", account.ID))
+	assert.Equal(t, 1, stats.Trades)
+	assert.Equal(t, "20", stats.TotalNetPnL.String())
+
+	_, stats = get("?symbol=nvda")
+	assert.Zero(t, stats.Trades)
+	assert.Equal(t, "USD", stats.BaseCurrency)
+
+	code, _ = get("?from=05/10/2024")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestGetEquityCurveHandler(t *testing.T) {
+	statsHandler := &StatsHandler{Store: store.NewMemoryStore()}
+	assert.NoError(t, statsHandler.Store.CreateUser(&models.User{Username: "jane_doe", Email: "jane@example.com"}))
+	for i, price := range []string{"100", "110", "100", "95", "95", "120"} {
+		side := models.SideBuy
+		if i%2 == 1 {
+			side = models.SideSell
+		}
+		execution := &models.Execution{UserID: 1, Symbol: "AAPL", Side: side, Quantity: dec("10"), Price: dec(price), ExecutedAt: start.AddDate(0, 0, i)}
+		assert.NoError(t, statsHandler.Store.CreateExecution(execution))
+	}
+
+	get := func(query string) (int, EquityCurve) {
+		req, err := http.NewRequest("GET", "/stats/equity"+query, nil)
+		assert.NoError(t, err)
+		req = req.WithContext(context.WithValue(req.Context(), auth.UserContextKey, 1))
+
+		rr := httptest.NewRecorder()
+		statsHandler.GetEquityCurve(rr, req)
+
+		var curve EquityCurve
+		if rr.Code == http.StatusOK {
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &curve))
+		}
+		return rr.Code, curve
+	}
+
+	code, curve := get("?capital=10000")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, IntervalDay, curve.Interval)
+	assert.Equal(t, "USD", curve.BaseCurrency)
+	assert.Len(t, curve.Points, 3)
+	assert.Equal(t, "300", curve.Points[2].Cumulative.String())
+	assert.Equal(t, "-50", curve.MaxDrawdown.Amount.String())
+	assert.Equal(t, "-0.005", curve.MaxDrawdown.Percent.String())
+	assert.NotNil(t, curve.MaxDrawdown.Recovery)
+
+	_, curve = get("?from=2024-05-04")
+	assert.Len(t, curve.Points, 2)
+	assert.Equal(t, "-50", curve.Points[0].Cumulative.String())
+
+	code, _ = get("?interval=week")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = get("?capital=-5")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestGetRiskHandler(t *testing.T) {
+	statsHandler := &StatsHandler{Store: store.NewMemoryStore()}
+	assert.NoError(t, statsHandler.Store.CreateUser(&models.User{Username: "jane_doe", Email: "jane@example.com"}))
+	for i, price := range []string{"100", "110", "100", "95", "95", "120"} {
+		side := models.SideBuy
+		if i%2 == 1 {
+			side = models.SideSell
+		}
+		execution := &models.Execution{UserID: 1, Symbol: "AAPL", Side: side, Quantity: dec("10"), Price: dec(price), ExecutedAt: start.AddDate(0, 0, i)}
+		assert.NoError(t, statsHandler.Store.CreateExecution(execution))
+	}
+
+	get := func(query string) (int, Risk) {
+		req, err := http.NewRequest("GET", "/stats/risk"+query, nil)
+		assert.NoError(t, err)
+		req = req.WithContext(context.WithValue(req.Context(), auth.UserContextKey, 1))
+
+		rr := httptest.NewRecorder()
+		statsHandler.GetRisk(rr, req)
+
+		var risk Risk
+		if rr.Code == http.StatusOK {
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &risk))
+		}
+		return rr.Code, risk
+	}
+
+	code, risk := get("")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "USD", risk.BaseCurrency)
+	assert.Equal(t, TradingDays, risk.Periods)
+	assert.Equal(t, 3, risk.Trades)
+	assert.NotNil(t, risk.SQN)
+	assert.Nil(t, risk.Sharpe, "ratios of returns need a capital")
+
+	_, withCapital := get("?capital=10000")
+	assert.NotNil(t, withCapital.Sharpe)
+	assert.NotNil(t, withCapital.Sortino)
+	assert.NotNil(t, withCapital.Calmar)
+
+	_, withRate := get("?capital=10000&risk_free=0.05")
+	assert.Equal(t, "0.05", withRate.RiskFreeRate.String())
+	assert.True(t, withRate.Sharpe.LessThan(*withCapital.Sharpe), "a risk-free rate lowers excess returns")
+
+	_, yearly := get("?capital=10000&periods=365")
+	assert.Equal(t, 365, yearly.Periods)
+	assert.True(t, yearly.Volatility.GreaterThan(*withCapital.Volatility), "more periods annualize to more volatility")
+
+	for _, query := range []string{"?capital=0", "?risk_free=abc", "?risk_free=5", "?periods=0", "?periods=daily"} {
+		code, _ = get(query)
+		assert.Equal(t, http.StatusBadRequest, code, query)
+	}
+}
+
+func TestGetBreakdownHandler(t *testing.T) {
+	statsHandler := &StatsHandler{Store: store.NewMemoryStore()}
+	assert.NoError(t, statsHandler.Store.CreateUser(&models.User{Username: "jane_doe", Email: "jane@example.com"}))
+	// Entered at 15:30 UTC, 11:30 in New York.
+	for i, side := range []string{models.SideBuy, models.SideSell} {
+		execution := &models.Execution{UserID: 1, Symbol: "AAPL", Side: side, Quantity: dec("10"), Price: dec("100"), ExecutedAt: start.Add(time.Hour + time.Duration(i)*time.Minute)}
+		assert.NoError(t, statsHandler.Store.CreateExecution(execution))
+	}
+
+	newRequest := func(method, url, body string) *http.Request {
+		req, err := http.NewRequest(method, url, strings.NewReader(body))
+		assert.NoError(t, err)
+		return req.WithContext(context.WithValue(req.Context(), auth.UserContextKey, 1))
+	}
+	// enteredAt returns the hour the trade shows up in.
+	enteredAt := func(query string) (int, string) {
+		rr := httptest.NewRecorder()
+		statsHandler.GetBreakdown(rr, newRequest("GET", "/stats/breakdown"+query, ""))
+		if rr.Code != http.StatusOK {
+			return rr.Code, ""
+		}
+		var breakdown Breakdown
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &breakdown))
+		for _, bucket := range breakdown.HourOfDay {
+			if bucket.Trades > 0 {
+				return rr.Code, breakdown.Timezone + " " + bucket.Key
+			}
+		}
+		return rr.Code, breakdown.Timezone
+	}
+
+	_, hour := enteredAt("")
+	assert.Equal(t, "UTC 15", hour, "UTC until the user sets a time zone")
+
+	rr := httptest.NewRecorder()
+	statsHandler.SetTimezone(rr, newRequest("PUT", "/timezone", `{"timezone": "America/New_York"}`))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	rr = httptest.NewRecorder()
+	statsHandler.GetTimezone(rr, newRequest("GET", "/timezone", ""))
+	var response TimezoneResponse
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, "America/New_York", response.Timezone)
+
+	_, hour = enteredAt("")
+	assert.Equal(t, "America/New_York 11", hour)
+	_, hour = enteredAt("?timezone=Asia/Tokyo")
+	assert.Equal(t, "Asia/Tokyo 00", hour, "?timezone= overrides the user's")
+
+	code, _ := enteredAt("?timezone=Mars/Olympus")
+	assert.Equal(t, http.StatusBadRequest, code)
+	for _, body := range []string{`{"timezone": "Nowhere/Special"}`, `{"timezone": "Local"}`, `{"timezone": ""}`} {
+		rr = httptest.NewRecorder()
+		statsHandler.SetTimezone(rr, newRequest("PUT", "/timezone", body))
+		assert.Equal(t, http.StatusBadRequest, rr.Code, body)
+	}
+}
@@ -0,0 +1,206 @@
+package stats
+
+import (
+	"github.com/drewbuiltit/trading-journal/backend/internal/fx"
+	"github.com/drewbuiltit/trading-journal/backend/internal/positions"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"github.com/shopspring/decimal"
+	"math"
+	"time"
+)
+
+// TradingDays is the default number of daily returns in a year.
+const TradingDays = 252
+
+// sqnTrades caps the number of trades SQN is scaled by, as Van Tharp does,
+// so that a long record does not score well on size alone.
+const sqnTrades = 100
+
+// Risk holds risk-adjusted measures of a user's results. Sharpe, Sortino
+// and Calmar ratios come from daily returns on the equity, capital plus the
+// realized P&L so far, and are only given with a capital. The returns run
+// over every weekday from the first close to the last, and any weekend day
+// with a close; days without a close return nothing. SQN, the system
+// quality number, measures the net P&L of closed positions: the square root
+// of their number, at most 100, times their mean over their standard
+// deviation; positions without a rate into the base currency are left out.
+// Ratios whose deviation is zero are omitted.
+type Risk struct {
+	BaseCurrency string           `json:"base_currency"`
+	Capital      *decimal.Decimal `json:"capital,omitempty"`
+	RiskFreeRate decimal.Decimal  `json:"risk_free_rate"`
+	Periods      int              `json:"periods"`
+	Days         int              `json:"days"`
+	Trades       int              `json:"trades"`
+	// AnnualizedReturn compounds the return over the days to a year of
+	// Periods days.
+	AnnualizedReturn *decimal.Decimal `json:"annualized_return,omitempty"`
+	// Volatility is the annualized standard deviation of daily returns.
+	Volatility         *decimal.Decimal `json:"volatility,omitempty"`
+	MaxDrawdownPercent *decimal.Decimal `json:"max_drawdown_percent,omitempty"`
+	Sharpe             *decimal.Decimal `json:"sharpe,omitempty"`
+	Sortino            *decimal.Decimal `json:"sortino,omitempty"`
+	Calmar             *decimal.Decimal `json:"calmar,omitempty"`
+	SQN                *decimal.Decimal `json:"sqn,omitempty"`
+	FXRateMissing      bool             `json:"fx_rate_missing,omitempty"`
+}
+
+// RiskForUser builds the user's positions and measures the risk of those
+// the filter matches, with the filter's dates selecting closes as they do
+// for the equity curve.
+func RiskForUser(s store.Store, userID int, filter positions.Filter, capital *decimal.Decimal, riskFree decimal.Decimal, periods int) (Risk, error) {
+	built, _, err := positions.ForUser(s, userID)
+	if err != nil {
+		return Risk{}, err
+	}
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return Risk{}, err
+	}
+
+	closedFilter := filter
+	closedFilter.Status = positions.StatusClosed
+	from, to := filter.From, filter.To
+	filter.From, filter.To = nil, nil
+
+	matched, closed := []positions.Position{}, []positions.Position{}
+	for _, position := range built {
+		if filter.Match(position) {
+			matched = append(matched, position)
+		}
+		if closedFilter.Match(position) {
+			closed = append(closed, position)
+		}
+	}
+
+	risk := ComputeRisk(Curve(matched, IntervalDay, nil, from, to), closed, capital, riskFree, periods)
+	risk.BaseCurrency = fx.BaseCurrencyOf(user)
+	return risk, nil
+}
+
+// ComputeRisk measures a daily equity curve and the closed positions behind
+// it, with riskFree the annual risk-free rate, taken as a simple rate per
+// period, and periods the number of daily returns in a year.
+func ComputeRisk(curve EquityCurve, closed []positions.Position, capital *decimal.Decimal, riskFree decimal.Decimal, periods int) Risk {
+	risk := Risk{
+		Capital:       capital,
+		RiskFreeRate:  riskFree,
+		Periods:       periods,
+		FXRateMissing: curve.FXRateMissing,
+	}
+
+	pnls := []float64{}
+	for _, position := range closed {
+		if position.Status != positions.StatusClosed {
+			continue
+		}
+		if position.FXRateMissing {
+			risk.FXRateMissing = true
+			continue
+		}
+		pnls = append(pnls, position.BaseNetPnL.InexactFloat64())
+	}
+	risk.Trades = len(pnls)
+	if deviation := stddev(pnls); deviation > 0 {
+		n := math.Min(float64(len(pnls)), sqnTrades)
+		risk.SQN = ratio(math.Sqrt(n) * mean(pnls) / deviation)
+	}
+
+	daily := dailyPnL(curve.Points)
+	risk.Days = len(daily)
+	if capital == nil || len(daily) == 0 {
+		return risk
+	}
+
+	returns := make([]float64, len(daily))
+	excess := make([]float64, len(daily))
+	equity, peak := capital.InexactFloat64(), capital.InexactFloat64()
+	deepest := 0.0
+	perPeriod := riskFree.InexactFloat64() / float64(periods)
+	for i, pnl := range daily {
+		if equity <= 0 {
+			// The capital is gone; returns on it are meaningless.
+			return risk
+		}
+		returns[i] = pnl / equity
+		excess[i] = returns[i] - perPeriod
+		equity += pnl
+		peak = math.Max(peak, equity)
+		deepest = math.Min(deepest, equity/peak-1)
+	}
+
+	annualFactor := math.Sqrt(float64(periods))
+	if equity > 0 {
+		growth := math.Pow(equity/capital.InexactFloat64(), float64(periods)/float64(len(daily))) - 1
+		risk.AnnualizedReturn = ratio(growth)
+		if deepest < 0 {
+			risk.Calmar = ratio(growth / -deepest)
+		}
+	}
+	risk.MaxDrawdownPercent = ratio(deepest)
+	if deviation := stddev(returns); deviation > 0 {
+		risk.Volatility = ratio(deviation * annualFactor)
+		risk.Sharpe = ratio(mean(excess) / deviation * annualFactor)
+	}
+
+	downside := 0.0
+	for _, r := range excess {
+		downside += math.Pow(math.Min(r, 0), 2)
+	}
+	if downside > 0 {
+		risk.Sortino = ratio(mean(excess) / math.Sqrt(downside/float64(len(excess))) * annualFactor)
+	}
+	return risk
+}
+
+// dailyPnL spreads the points of a daily curve over every weekday between
+// the first and last, with nothing made on days without a point.
+func dailyPnL(points []EquityPoint) []float64 {
+	if len(points) == 0 {
+		return nil
+	}
+	byDay := make(map[time.Time]float64)
+	for _, point := range points {
+		byDay[point.Time] = point.PnL.InexactFloat64()
+	}
+
+	daily := []float64{}
+	last := points[len(points)-1].Time
+	for day := points[0].Time; !day.After(last); day = day.AddDate(0, 0, 1) {
+		pnl, ok := byDay[day]
+		if !ok && (day.Weekday() == time.Saturday || day.Weekday() == time.Sunday) {
+			continue
+		}
+		daily = append(daily, pnl)
+	}
+	return daily
+}
+
+func mean(values []float64) float64 {
+	total := 0.0
+	for _, v := range values {
+		total += v
+	}
+	return total / float64(len(values))
+}
+
+// stddev is the sample standard deviation, zero for fewer than two values.
+func stddev(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	m := mean(values)
+	total := 0.0
+	for _, v := range values {
+		total += (v - m) * (v - m)
+	}
+	return math.Sqrt(total / float64(len(values)-1))
+}
+
+func ratio(value float64) *decimal.Decimal {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return nil
+	}
+	rounded := decimal.NewFromFloat(value).Round(precision)
+	return &rounded
+}
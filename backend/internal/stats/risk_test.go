@@ -0,0 +1,67 @@
+package stats
+
+import (
+	"github.com/drewbuiltit/trading-journal/backend/internal/positions"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// riskPositions close for +10, -5 and +20 on Monday to Wednesday, nothing on
+// Thursday and -10 on Friday.
+func riskPositions() []positions.Position {
+	monday := time.Date(2024, 5, 6, 15, 0, 0, 0, time.UTC)
+	built := []positions.Position{}
+	for _, close := range []struct {
+		pnl string
+		day int
+	}{{"10", 0}, {"-5", 1}, {"20", 2}, {"-10", 4}} {
+		position := matches(match(close.pnl, monday.AddDate(0, 0, close.day)))
+		closedAt := monday.AddDate(0, 0, close.day)
+		position.Status = positions.StatusClosed
+		position.ClosedAt = &closedAt
+		position.BaseNetPnL = dec(close.pnl)
+		built = append(built, position)
+	}
+	return built
+}
+
+// The expected values were worked out independently with Python's
+// statistics module.
+func TestComputeRisk(t *testing.T) {
+	built := riskPositions()
+	curve := Curve(built, IntervalDay, nil, nil, nil)
+	capital := dec("1000")
+
+	risk := ComputeRisk(curve, built, &capital, decimal.Zero, TradingDays)
+	assert.Equal(t, 5, risk.Days, "Thursday counts as a day without a return")
+	assert.Equal(t, 4, risk.Trades)
+	assert.Equal(t, "4.0426", risk.Sharpe.String())
+	assert.Equal(t, "9.8596", risk.Sortino.String())
+	assert.Equal(t, "1.1178", risk.AnnualizedReturn.String())
+	assert.Equal(t, "-0.0098", risk.MaxDrawdownPercent.String())
+	assert.Equal(t, "114.5763", risk.Calmar.String())
+	assert.Equal(t, "0.1894", risk.Volatility.String())
+	assert.Equal(t, "0.5447", risk.SQN.String())
+
+	risk = ComputeRisk(curve, built, &capital, dec("0.05"), TradingDays)
+	assert.Equal(t, "3.7787", risk.Sharpe.String())
+	assert.Equal(t, "8.9948", risk.Sortino.String())
+}
+
+func TestComputeRisk_WithoutCapital(t *testing.T) {
+	built := riskPositions()
+	unconverted := closed("0", 3)
+	unconverted.FXRateMissing = true
+	risk := ComputeRisk(Curve(built, IntervalDay, nil, nil, nil), append(built, unconverted), nil, decimal.Zero, TradingDays)
+	assert.True(t, risk.FXRateMissing)
+	assert.Equal(t, 4, risk.Trades, "a position without a rate is left out")
+	assert.Nil(t, risk.Sharpe, "returns need a capital to be measured against")
+	assert.Nil(t, risk.Calmar)
+	assert.Equal(t, "0.5447", risk.SQN.String(), "SQN only needs the trades")
+
+	risk = ComputeRisk(Curve(nil, IntervalDay, nil, nil, nil), nil, nil, decimal.Zero, TradingDays)
+	assert.Zero(t, risk.Days)
+	assert.Nil(t, risk.SQN)
+}
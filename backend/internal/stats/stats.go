@@ -0,0 +1,201 @@
+package stats
+
+import (
+	"github.com/drewbuiltit/trading-journal/backend/internal/fx"
+	"github.com/drewbuiltit/trading-journal/backend/internal/positions"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"github.com/shopspring/decimal"
+	"sort"
+)
+
+// Stats summarizes closed positions by their net P&L in the base currency.
+// A position that made exactly nothing is neither a win nor a loss and ends
+// a streak. Ratios that would divide by zero are omitted.
+type Stats struct {
+	BaseCurrency string `json:"base_currency"`
+	Trades       int    `json:"trades"`
+	Wins         int    `json:"wins"`
+	Losses       int    `json:"losses"`
+	Breakeven    int    `json:"breakeven"`
+	// WinRate is the share of all trades that were wins.
+	WinRate     *decimal.Decimal `json:"win_rate"`
+	GrossProfit decimal.Decimal  `json:"gross_profit"`
+	GrossLoss   decimal.Decimal  `json:"gross_loss"`
+	AverageWin  decimal.Decimal  `json:"average_win"`
+	AverageLoss decimal.Decimal  `json:"average_loss"`
+	LargestWin  decimal.Decimal  `json:"largest_win"`
+	LargestLoss decimal.Decimal  `json:"largest_loss"`
+	// PayoffRatio is the average win over the size of the average loss.
+	PayoffRatio *decimal.Decimal `json:"payoff_ratio"`
+	// ProfitFactor is the gross profit over the size of the gross loss.
+	ProfitFactor *decimal.Decimal `json:"profit_factor"`
+	// Expectancy is the net P&L made per trade.
+	Expectancy           decimal.Decimal `json:"expectancy"`
+	TotalNetPnL          decimal.Decimal `json:"total_net_pnl"`
+	MaxConsecutiveWins   int             `json:"max_consecutive_wins"`
+	MaxConsecutiveLosses int             `json:"max_consecutive_losses"`
+	// CurrentStreak counts the latest run of wins, or of losses as a
+	// negative number.
+	CurrentStreak int `json:"current_streak"`
+	// RTrades counts the trades planned with a stop loss, which are the
+	// ones measured in R.
+	RTrades  int              `json:"r_trades"`
+	AverageR *decimal.Decimal `json:"average_r"`
+	TotalR   decimal.Decimal  `json:"total_r"`
+	// RDistribution counts R-multiples in buckets 1R wide, from the lowest
+	// bucket with a trade to the highest.
+	RDistribution []RBucket `json:"r_distribution"`
+	// FXRateMissing is set when a position had no rate into the base
+	// currency. Such positions are counted in Unconverted and left out of
+	// everything else.
+	FXRateMissing bool `json:"fx_rate_missing,omitempty"`
+	Unconverted   int  `json:"unconverted,omitempty"`
+}
+
+// RBucket counts the trades whose R-multiple was at least From and below To.
+type RBucket struct {
+	From  decimal.Decimal `json:"from"`
+	To    decimal.Decimal `json:"to"`
+	Count int             `json:"count"`
+}
+
+const precision = 4
+
+// ForUser builds the user's positions and returns the stats of the closed
+// ones the filter matches.
+func ForUser(s store.Store, userID int, filter positions.Filter) (Stats, error) {
+	built, _, err := positions.ForUser(s, userID)
+	if err != nil {
+		return Stats{}, err
+	}
+
+	filter.Status = positions.StatusClosed
+	matched := []positions.Position{}
+	for _, position := range built {
+		if filter.Match(position) {
+			matched = append(matched, position)
+		}
+	}
+	stats := Compute(matched)
+	if stats.BaseCurrency == "" {
+		user, err := s.GetUserByID(userID)
+		if err != nil {
+			return Stats{}, err
+		}
+		stats.BaseCurrency = fx.BaseCurrencyOf(user)
+	}
+	return stats, nil
+}
+
+// Compute works out the stats of closed positions, taken in the order they
+// closed. Open positions are skipped.
+func Compute(built []positions.Position) Stats {
+	closed := []positions.Position{}
+	for _, position := range built {
+		if position.Status == positions.StatusClosed && position.ClosedAt != nil {
+			closed = append(closed, position)
+		}
+	}
+	sort.SliceStable(closed, func(i, j int) bool {
+		return closed[i].ClosedAt.Before(*closed[j].ClosedAt)
+	})
+
+	stats := Stats{
+		GrossProfit: decimal.Zero,
+		GrossLoss:   decimal.Zero,
+		AverageWin:  decimal.Zero,
+		AverageLoss: decimal.Zero,
+		LargestWin:  decimal.Zero,
+		LargestLoss: decimal.Zero,
+		Expectancy:  decimal.Zero,
+		TotalNetPnL: decimal.Zero,
+		TotalR:      decimal.Zero,
+	}
+	multiples := []decimal.Decimal{}
+	for _, position := range closed {
+		if stats.BaseCurrency == "" {
+			stats.BaseCurrency = position.BaseCurrency
+		}
+		if position.FXRateMissing {
+			stats.FXRateMissing = true
+			stats.Unconverted++
+			continue
+		}
+
+		if position.RMultiple != nil {
+			multiples = append(multiples, *position.RMultiple)
+			stats.TotalR = stats.TotalR.Add(*position.RMultiple)
+		}
+
+		pnl := position.BaseNetPnL
+		stats.Trades++
+		stats.TotalNetPnL = stats.TotalNetPnL.Add(pnl)
+		switch {
+		case pnl.IsPositive():
+			stats.Wins++
+			stats.GrossProfit = stats.GrossProfit.Add(pnl)
+			stats.LargestWin = decimal.Max(stats.LargestWin, pnl)
+			if stats.CurrentStreak < 0 {
+				stats.CurrentStreak = 0
+			}
+			stats.CurrentStreak++
+			stats.MaxConsecutiveWins = max(stats.MaxConsecutiveWins, stats.CurrentStreak)
+		case pnl.IsNegative():
+			stats.Losses++
+			stats.GrossLoss = stats.GrossLoss.Add(pnl)
+			stats.LargestLoss = decimal.Min(stats.LargestLoss, pnl)
+			if stats.CurrentStreak > 0 {
+				stats.CurrentStreak = 0
+			}
+			stats.CurrentStreak--
+			stats.MaxConsecutiveLosses = max(stats.MaxConsecutiveLosses, -stats.CurrentStreak)
+		default:
+			stats.Breakeven++
+			stats.CurrentStreak = 0
+		}
+	}
+
+	if stats.Trades > 0 {
+		rate := decimal.NewFromInt(int64(stats.Wins)).DivRound(decimal.NewFromInt(int64(stats.Trades)), precision)
+		stats.WinRate = &rate
+		stats.Expectancy = stats.TotalNetPnL.DivRound(decimal.NewFromInt(int64(stats.Trades)), precision)
+	}
+	if stats.Wins > 0 {
+		stats.AverageWin = stats.GrossProfit.DivRound(decimal.NewFromInt(int64(stats.Wins)), precision)
+	}
+	if stats.Losses > 0 {
+		stats.AverageLoss = stats.GrossLoss.DivRound(decimal.NewFromInt(int64(stats.Losses)), precision)
+		payoff := stats.AverageWin.DivRound(stats.AverageLoss.Abs(), precision)
+		stats.PayoffRatio = &payoff
+		factor := stats.GrossProfit.DivRound(stats.GrossLoss.Abs(), precision)
+		stats.ProfitFactor = &factor
+	}
+	stats.RTrades = len(multiples)
+	if stats.RTrades > 0 {
+		average := stats.TotalR.DivRound(decimal.NewFromInt(int64(stats.RTrades)), precision)
+		stats.AverageR = &average
+	}
+	stats.RDistribution = distribution(multiples)
+	return stats
+}
+
+// distribution buckets R-multiples by the whole number of R below them.
+func distribution(multiples []decimal.Decimal) []RBucket {
+	buckets := []RBucket{}
+	if len(multiples) == 0 {
+		return buckets
+	}
+	low, high := multiples[0].Floor(), multiples[0].Floor()
+	for _, multiple := range multiples {
+		low = decimal.Min(low, multiple.Floor())
+		high = decimal.Max(high, multiple.Floor())
+	}
+	one := decimal.NewFromInt(1)
+	for from := low; from.LessThanOrEqual(high); from = from.Add(one) {
+		buckets = append(buckets, RBucket{From: from, To: from.Add(one)})
+	}
+	for _, multiple := range multiples {
+		buckets[multiple.Floor().Sub(low).IntPart()].Count++
+	}
+	return buckets
+}
@@ -0,0 +1,112 @@
+package stats
+
+import (
+	"github.com/drewbuiltit/trading-journal/backend/internal/positions"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var start = time.Date(2024, 5, 1, 14, 30, 0, 0, time.UTC)
+
+func dec(value string) decimal.Decimal {
+	return decimal.RequireFromString(value)
+}
+
+func closed(pnl string, day int) positions.Position {
+	closedAt := start.AddDate(0, 0, day)
+	return positions.Position{Status: positions.StatusClosed, ClosedAt: &closedAt, BaseCurrency: "USD", BaseNetPnL: dec(pnl)}
+}
+
+func TestCompute(t *testing.T) {
+	// Listed out of order: streaks follow the close dates.
+	stats := Compute([]positions.Position{
+		closed("-50", 3),
+		closed("100", 0),
+		closed("300", 1),
+		closed("0", 2),
+		closed("-150", 4),
+		closed("200", 5),
+		{Status: positions.StatusOpen, BaseNetPnL: dec("999")},
+	})
+
+	assert.Equal(t, "USD", stats.BaseCurrency)
+	assert.Equal(t, 6, stats.Trades)
+	assert.Equal(t, 3, stats.Wins)
+	assert.Equal(t, 2, stats.Losses)
+	assert.Equal(t, 1, stats.Breakeven)
+	assert.Equal(t, "0.5", stats.WinRate.String())
+	assert.Equal(t, "600", stats.GrossProfit.String())
+	assert.Equal(t, "-200", stats.GrossLoss.String())
+	assert.Equal(t, "200", stats.AverageWin.String())
+	assert.Equal(t, "-100", stats.AverageLoss.String())
+	assert.Equal(t, "300", stats.LargestWin.String())
+	assert.Equal(t, "-150", stats.LargestLoss.String())
+	assert.Equal(t, "2", stats.PayoffRatio.String())
+	assert.Equal(t, "3", stats.ProfitFactor.String())
+	assert.Equal(t, "66.6667", stats.Expectancy.String())
+	assert.Equal(t, "400", stats.TotalNetPnL.String())
+	assert.Equal(t, 2, stats.MaxConsecutiveWins, "the breakeven trade ends the streak")
+	assert.Equal(t, 2, stats.MaxConsecutiveLosses)
+	assert.Equal(t, 1, stats.CurrentStreak)
+}
+
+func TestCompute_FXRateMissing(t *testing.T) {
+	unconverted := closed("0", 1)
+	unconverted.FXRateMissing = true
+
+	stats := Compute([]positions.Position{closed("10", 0), unconverted, closed("20", 2)})
+	assert.True(t, stats.FXRateMissing)
+	assert.Equal(t, 1, stats.Unconverted)
+	assert.Equal(t, 2, stats.Trades, "a position without a rate is not a breakeven trade")
+	assert.Equal(t, 0, stats.Breakeven)
+	assert.Equal(t, "1", stats.WinRate.String())
+	assert.Equal(t, 2, stats.CurrentStreak, "nor does it end a streak")
+}
+
+func TestCompute_NoLosses(t *testing.T) {
+	stats := Compute([]positions.Position{closed("10", 0), closed("20", 1)})
+	assert.Equal(t, "1", stats.WinRate.String())
+	assert.Nil(t, stats.PayoffRatio, "there is no loss to compare with")
+	assert.Nil(t, stats.ProfitFactor)
+	assert.Equal(t, 2, stats.CurrentStreak)
+
+	stats = Compute(nil)
+	assert.Zero(t, stats.Trades)
+	assert.Nil(t, stats.WinRate)
+	assert.Equal(t, "0", stats.Expectancy.String())
+}
+
+func TestCompute_RMultiples(t *testing.T) {
+	withR := func(position positions.Position, r string) positions.Position {
+		multiple := dec(r)
+		position.RMultiple = &multiple
+		return position
+	}
+
+	stats := Compute([]positions.Position{
+		withR(closed("300", 0), "3"),
+		withR(closed("-100", 1), "-1"),
+		withR(closed("-120", 2), "-1.2"),
+		withR(closed("50", 3), "0.5"),
+		closed("80", 4),
+	})
+	assert.Equal(t, 5, stats.Trades)
+	assert.Equal(t, 4, stats.RTrades, "trades without a stop are not measured in R")
+	assert.Equal(t, "1.3", stats.TotalR.String())
+	assert.Equal(t, "0.325", stats.AverageR.String())
+
+	assert.Len(t, stats.RDistribution, 6, "empty buckets between the lowest and highest are kept")
+	assert.Equal(t, "-2", stats.RDistribution[0].From.String())
+	assert.Equal(t, "-1", stats.RDistribution[0].To.String())
+	counts := []int{}
+	for _, bucket := range stats.RDistribution {
+		counts = append(counts, bucket.Count)
+	}
+	assert.Equal(t, []int{1, 1, 1, 0, 0, 1}, counts, "a loss of exactly 1R falls in the -1R bucket")
+
+	stats = Compute([]positions.Position{closed("80", 0)})
+	assert.Nil(t, stats.AverageR)
+	assert.Empty(t, stats.RDistribution)
+}
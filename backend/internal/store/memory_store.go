@@ -3,17 +3,58 @@ package store
 import (
 	"errors"
 	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"sort"
 	"sync"
+	"time"
 )
 
 type MemoryStore struct {
-	users map[string]*models.User
-	mu    sync.RWMutex
+	users            map[string]*models.User
+	accounts         map[int]models.Account
+	nextAccountID    int
+	trades           map[int]models.Trade
+	nextTradeID      int
+	executions       map[int]models.Execution
+	nextExecutionID  int
+	cashTransactions []models.CashTransaction
+	nextCashID       int
+	importBatches    map[int]models.ImportBatch
+	nextBatchID      int
+	spreads          map[int]models.Spread
+	nextSpreadID     int
+	csvTemplates     map[int]models.CSVTemplate
+	nextTemplateID   int
+	lotSelections    []models.LotSelection
+	nextSelectionID  int
+	fxRates          []models.FXRate
+	nextFXRateID     int
+	corporateActions []models.CorporateAction
+	nextActionID     int
+	feeSchedules     []models.FeeSchedule
+	nextScheduleID   int
+	mu               sync.RWMutex
 }
 
 func NewMemoryStore() *MemoryStore {
 	return &MemoryStore{
-		users: make(map[string]*models.User),
+		users:           make(map[string]*models.User),
+		accounts:        make(map[int]models.Account),
+		nextAccountID:   1,
+		trades:          make(map[int]models.Trade),
+		nextTradeID:     1,
+		executions:      make(map[int]models.Execution),
+		nextExecutionID: 1,
+		nextCashID:      1,
+		importBatches:   make(map[int]models.ImportBatch),
+		nextBatchID:     1,
+		spreads:         make(map[int]models.Spread),
+		nextSpreadID:    1,
+		csvTemplates:    make(map[int]models.CSVTemplate),
+		nextTemplateID:  1,
+		nextSelectionID: 1,
+		nextFXRateID:    1,
+		nextActionID:    1,
+		nextScheduleID:  1,
 	}
 }
 
@@ -41,3 +82,862 @@ func (m *MemoryStore) GetUserByEmail(email string) (*models.User, error) {
 
 	return user, nil
 }
+
+func (m *MemoryStore) GetUserByID(userID int) (*models.User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, user := range m.users {
+		if user.ID == userID {
+			return user, nil
+		}
+	}
+
+	return nil, ErrNotFound
+}
+
+func (m *MemoryStore) UpdateLotMethod(userID int, method string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, user := range m.users {
+		if user.ID == userID {
+			user.LotMethod = method
+			return nil
+		}
+	}
+
+	return ErrNotFound
+}
+
+func (m *MemoryStore) UpdateBaseCurrency(userID int, currency string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, user := range m.users {
+		if user.ID == userID {
+			user.BaseCurrency = currency
+			return nil
+		}
+	}
+
+	return ErrNotFound
+}
+
+func (m *MemoryStore) UpdateTimezone(userID int, timezone string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, user := range m.users {
+		if user.ID == userID {
+			user.Timezone = timezone
+			return nil
+		}
+	}
+
+	return ErrNotFound
+}
+
+func (m *MemoryStore) CreateAccount(account *models.Account) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	account.ID = m.nextAccountID
+	m.nextAccountID++
+	if account.CreatedAt.IsZero() {
+		account.CreatedAt = time.Now().UTC()
+	}
+	m.accounts[account.ID] = *account
+	return nil
+}
+
+func (m *MemoryStore) GetAccount(userID, accountID int) (*models.Account, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	account, exists := m.accounts[accountID]
+	if !exists || account.UserID != userID {
+		return nil, ErrNotFound
+	}
+
+	return &account, nil
+}
+
+func (m *MemoryStore) ListAccounts(userID int) ([]models.Account, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	accounts := []models.Account{}
+	for _, account := range m.accounts {
+		if account.UserID == userID {
+			accounts = append(accounts, account)
+		}
+	}
+
+	sort.Slice(accounts, func(i, j int) bool {
+		if accounts[i].Name != accounts[j].Name {
+			return accounts[i].Name < accounts[j].Name
+		}
+		return accounts[i].ID < accounts[j].ID
+	})
+
+	return accounts, nil
+}
+
+func (m *MemoryStore) UpdateAccount(account *models.Account) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, exists := m.accounts[account.ID]
+	if !exists || existing.UserID != account.UserID {
+		return ErrNotFound
+	}
+
+	account.CreatedAt = existing.CreatedAt
+	m.accounts[account.ID] = *account
+	return nil
+}
+
+func (m *MemoryStore) DeleteAccount(userID, accountID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, exists := m.accounts[accountID]
+	if !exists || existing.UserID != userID {
+		return ErrNotFound
+	}
+
+	delete(m.accounts, accountID)
+
+	for id, execution := range m.executions {
+		if execution.AccountID != nil && *execution.AccountID == accountID {
+			execution.AccountID = nil
+			m.executions[id] = execution
+		}
+	}
+	for id, trade := range m.trades {
+		if trade.AccountID != nil && *trade.AccountID == accountID {
+			trade.AccountID = nil
+			m.trades[id] = trade
+		}
+	}
+	schedules := m.feeSchedules[:0]
+	for _, schedule := range m.feeSchedules {
+		if schedule.AccountID == nil || *schedule.AccountID != accountID {
+			schedules = append(schedules, schedule)
+		}
+	}
+	m.feeSchedules = schedules
+	for id, batch := range m.importBatches {
+		if batch.AccountID != nil && *batch.AccountID == accountID {
+			batch.AccountID = nil
+			m.importBatches[id] = batch
+		}
+	}
+	for i, transaction := range m.cashTransactions {
+		if transaction.AccountID != nil && *transaction.AccountID == accountID {
+			m.cashTransactions[i].AccountID = nil
+		}
+	}
+	return nil
+}
+
+func (m *MemoryStore) CreateTrade(trade *models.Trade) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	trade.ID = m.nextTradeID
+	m.nextTradeID++
+	m.trades[trade.ID] = *trade
+	return nil
+}
+
+func (m *MemoryStore) GetTrade(userID, tradeID int) (*models.Trade, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	trade, exists := m.trades[tradeID]
+	if !exists || trade.UserID != userID {
+		return nil, ErrNotFound
+	}
+
+	return &trade, nil
+}
+
+func (m *MemoryStore) ListTrades(userID int) ([]models.Trade, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	trades := []models.Trade{}
+	for _, trade := range m.trades {
+		if trade.UserID == userID {
+			trades = append(trades, trade)
+		}
+	}
+
+	sort.Slice(trades, func(i, j int) bool {
+		if !trades[i].TradeDate.Equal(trades[j].TradeDate) {
+			return trades[i].TradeDate.After(trades[j].TradeDate)
+		}
+		return trades[i].ID > trades[j].ID
+	})
+
+	return trades, nil
+}
+
+func (m *MemoryStore) UpdateTrade(trade *models.Trade) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, exists := m.trades[trade.ID]
+	if !exists || existing.UserID != trade.UserID {
+		return ErrNotFound
+	}
+
+	m.trades[trade.ID] = *trade
+	return nil
+}
+
+func (m *MemoryStore) DeleteTrade(userID, tradeID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, exists := m.trades[tradeID]
+	if !exists || existing.UserID != userID {
+		return ErrNotFound
+	}
+
+	delete(m.trades, tradeID)
+	return nil
+}
+
+func (m *MemoryStore) CreateExecution(execution *models.Execution) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if execution.Fingerprint != "" {
+		for _, existing := range m.executions {
+			if existing.UserID == execution.UserID && existing.Fingerprint == execution.Fingerprint {
+				return ErrDuplicate
+			}
+		}
+	}
+
+	execution.ID = m.nextExecutionID
+	m.nextExecutionID++
+	m.executions[execution.ID] = *execution
+	return nil
+}
+
+func (m *MemoryStore) GetExecution(userID, executionID int) (*models.Execution, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	execution, exists := m.executions[executionID]
+	if !exists || execution.UserID != userID {
+		return nil, ErrNotFound
+	}
+
+	return &execution, nil
+}
+
+func (m *MemoryStore) GetExecutionByFingerprint(userID int, fingerprint string) (*models.Execution, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, execution := range m.executions {
+		if execution.UserID == userID && fingerprint != "" && execution.Fingerprint == fingerprint {
+			return &execution, nil
+		}
+	}
+
+	return nil, ErrNotFound
+}
+
+func (m *MemoryStore) ListExecutions(userID int) ([]models.Execution, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	executions := []models.Execution{}
+	for _, execution := range m.executions {
+		if execution.UserID == userID {
+			executions = append(executions, execution)
+		}
+	}
+
+	sort.Slice(executions, func(i, j int) bool {
+		if !executions[i].ExecutedAt.Equal(executions[j].ExecutedAt) {
+			return executions[i].ExecutedAt.Before(executions[j].ExecutedAt)
+		}
+		return executions[i].ID < executions[j].ID
+	})
+
+	return executions, nil
+}
+
+func (m *MemoryStore) UpdateExecution(execution *models.Execution) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, exists := m.executions[execution.ID]
+	if !exists || existing.UserID != execution.UserID {
+		return ErrNotFound
+	}
+
+	execution.Fingerprint = existing.Fingerprint
+	execution.ImportBatchID = existing.ImportBatchID
+	m.executions[execution.ID] = *execution
+	return nil
+}
+
+func (m *MemoryStore) DeleteExecution(userID, executionID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, exists := m.executions[executionID]
+	if !exists || existing.UserID != userID {
+		return ErrNotFound
+	}
+
+	delete(m.executions, executionID)
+
+	selections := m.lotSelections[:0]
+	for _, selection := range m.lotSelections {
+		if selection.CloseExecutionID != executionID && selection.OpenExecutionID != executionID {
+			selections = append(selections, selection)
+		}
+	}
+	m.lotSelections = selections
+	return nil
+}
+
+func (m *MemoryStore) CreateCashTransaction(transaction *models.CashTransaction) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if transaction.Fingerprint != "" {
+		for _, existing := range m.cashTransactions {
+			if existing.UserID == transaction.UserID && existing.Fingerprint == transaction.Fingerprint {
+				return ErrDuplicate
+			}
+		}
+	}
+
+	transaction.ID = m.nextCashID
+	m.nextCashID++
+	m.cashTransactions = append(m.cashTransactions, *transaction)
+	return nil
+}
+
+func (m *MemoryStore) GetCashTransaction(userID, transactionID int) (*models.CashTransaction, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, transaction := range m.cashTransactions {
+		if transaction.ID == transactionID && transaction.UserID == userID {
+			return &transaction, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (m *MemoryStore) ListCashTransactions(userID int) ([]models.CashTransaction, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	transactions := []models.CashTransaction{}
+	for _, transaction := range m.cashTransactions {
+		if transaction.UserID == userID {
+			transactions = append(transactions, transaction)
+		}
+	}
+
+	sort.Slice(transactions, func(i, j int) bool {
+		if !transactions[i].PostedAt.Equal(transactions[j].PostedAt) {
+			return transactions[i].PostedAt.Before(transactions[j].PostedAt)
+		}
+		return transactions[i].ID < transactions[j].ID
+	})
+
+	return transactions, nil
+}
+
+func (m *MemoryStore) UpdateCashTransaction(transaction *models.CashTransaction) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, existing := range m.cashTransactions {
+		if existing.ID == transaction.ID && existing.UserID == transaction.UserID {
+			transaction.Fingerprint = existing.Fingerprint
+			transaction.ImportBatchID = existing.ImportBatchID
+			m.cashTransactions[i] = *transaction
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (m *MemoryStore) DeleteCashTransaction(userID, transactionID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, existing := range m.cashTransactions {
+		if existing.ID == transactionID && existing.UserID == userID {
+			m.cashTransactions = append(m.cashTransactions[:i], m.cashTransactions[i+1:]...)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (m *MemoryStore) CreateImportBatch(batch *models.ImportBatch) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	batch.ID = m.nextBatchID
+	m.nextBatchID++
+	if batch.CreatedAt.IsZero() {
+		batch.CreatedAt = time.Now().UTC()
+	}
+	m.importBatches[batch.ID] = *batch
+	return nil
+}
+
+func (m *MemoryStore) GetImportBatch(userID, batchID int) (*models.ImportBatch, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	batch, exists := m.importBatches[batchID]
+	if !exists || batch.UserID != userID {
+		return nil, ErrNotFound
+	}
+
+	return &batch, nil
+}
+
+func (m *MemoryStore) ListImportBatches(userID int) ([]models.ImportBatch, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	batches := []models.ImportBatch{}
+	for _, batch := range m.importBatches {
+		if batch.UserID == userID {
+			batches = append(batches, batch)
+		}
+	}
+
+	sort.Slice(batches, func(i, j int) bool {
+		if !batches[i].CreatedAt.Equal(batches[j].CreatedAt) {
+			return batches[i].CreatedAt.After(batches[j].CreatedAt)
+		}
+		return batches[i].ID > batches[j].ID
+	})
+
+	return batches, nil
+}
+
+func (m *MemoryStore) UpdateImportBatch(batch *models.ImportBatch) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, exists := m.importBatches[batch.ID]
+	if !exists || existing.UserID != batch.UserID {
+		return ErrNotFound
+	}
+
+	batch.CreatedAt = existing.CreatedAt
+	m.importBatches[batch.ID] = *batch
+	return nil
+}
+
+func (m *MemoryStore) DeleteImportBatch(userID, batchID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, exists := m.importBatches[batchID]
+	if !exists || existing.UserID != userID {
+		return ErrNotFound
+	}
+
+	delete(m.importBatches, batchID)
+
+	removed := make(map[int]bool)
+	for id, execution := range m.executions {
+		if execution.ImportBatchID != nil && *execution.ImportBatchID == batchID {
+			delete(m.executions, id)
+			removed[id] = true
+		}
+	}
+	selections := m.lotSelections[:0]
+	for _, selection := range m.lotSelections {
+		if !removed[selection.CloseExecutionID] && !removed[selection.OpenExecutionID] {
+			selections = append(selections, selection)
+		}
+	}
+	m.lotSelections = selections
+
+	transactions := m.cashTransactions[:0]
+	for _, transaction := range m.cashTransactions {
+		if transaction.ImportBatchID == nil || *transaction.ImportBatchID != batchID {
+			transactions = append(transactions, transaction)
+		}
+	}
+	m.cashTransactions = transactions
+	return nil
+}
+
+func (m *MemoryStore) CreateSpread(spread *models.Spread) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	spread.ID = m.nextSpreadID
+	m.nextSpreadID++
+	if spread.CreatedAt.IsZero() {
+		spread.CreatedAt = time.Now().UTC()
+	}
+	m.spreads[spread.ID] = *spread
+	return nil
+}
+
+func (m *MemoryStore) GetSpread(userID, spreadID int) (*models.Spread, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	spread, exists := m.spreads[spreadID]
+	if !exists || spread.UserID != userID {
+		return nil, ErrNotFound
+	}
+
+	return &spread, nil
+}
+
+func (m *MemoryStore) ListSpreads(userID int) ([]models.Spread, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	spreads := []models.Spread{}
+	for _, spread := range m.spreads {
+		if spread.UserID == userID {
+			spreads = append(spreads, spread)
+		}
+	}
+
+	sort.Slice(spreads, func(i, j int) bool {
+		if !spreads[i].CreatedAt.Equal(spreads[j].CreatedAt) {
+			return spreads[i].CreatedAt.After(spreads[j].CreatedAt)
+		}
+		return spreads[i].ID > spreads[j].ID
+	})
+
+	return spreads, nil
+}
+
+func (m *MemoryStore) UpdateSpread(spread *models.Spread) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, exists := m.spreads[spread.ID]
+	if !exists || existing.UserID != spread.UserID {
+		return ErrNotFound
+	}
+
+	spread.CreatedAt = existing.CreatedAt
+	m.spreads[spread.ID] = *spread
+	return nil
+}
+
+func (m *MemoryStore) DeleteSpread(userID, spreadID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, exists := m.spreads[spreadID]
+	if !exists || existing.UserID != userID {
+		return ErrNotFound
+	}
+
+	delete(m.spreads, spreadID)
+
+	for id, execution := range m.executions {
+		if execution.SpreadID != nil && *execution.SpreadID == spreadID {
+			execution.SpreadID = nil
+			m.executions[id] = execution
+		}
+	}
+	for id, trade := range m.trades {
+		if trade.SpreadID != nil && *trade.SpreadID == spreadID {
+			trade.SpreadID = nil
+			m.trades[id] = trade
+		}
+	}
+	return nil
+}
+
+func (m *MemoryStore) CreateCSVTemplate(template *models.CSVTemplate) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	template.ID = m.nextTemplateID
+	m.nextTemplateID++
+	if template.CreatedAt.IsZero() {
+		template.CreatedAt = time.Now().UTC()
+	}
+	m.csvTemplates[template.ID] = *template
+	return nil
+}
+
+func (m *MemoryStore) GetCSVTemplate(userID, templateID int) (*models.CSVTemplate, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	template, exists := m.csvTemplates[templateID]
+	if !exists || template.UserID != userID {
+		return nil, ErrNotFound
+	}
+
+	return &template, nil
+}
+
+func (m *MemoryStore) ListCSVTemplates(userID int) ([]models.CSVTemplate, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	templates := []models.CSVTemplate{}
+	for _, template := range m.csvTemplates {
+		if template.UserID == userID {
+			templates = append(templates, template)
+		}
+	}
+
+	sort.Slice(templates, func(i, j int) bool {
+		if templates[i].Name != templates[j].Name {
+			return templates[i].Name < templates[j].Name
+		}
+		return templates[i].ID < templates[j].ID
+	})
+
+	return templates, nil
+}
+
+func (m *MemoryStore) UpdateCSVTemplate(template *models.CSVTemplate) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, exists := m.csvTemplates[template.ID]
+	if !exists || existing.UserID != template.UserID {
+		return ErrNotFound
+	}
+
+	template.CreatedAt = existing.CreatedAt
+	m.csvTemplates[template.ID] = *template
+	return nil
+}
+
+func (m *MemoryStore) DeleteCSVTemplate(userID, templateID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, exists := m.csvTemplates[templateID]
+	if !exists || existing.UserID != userID {
+		return ErrNotFound
+	}
+
+	delete(m.csvTemplates, templateID)
+	return nil
+}
+
+func (m *MemoryStore) ListLotSelections(userID int) ([]models.LotSelection, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	selections := []models.LotSelection{}
+	for _, selection := range m.lotSelections {
+		if selection.UserID == userID {
+			selections = append(selections, selection)
+		}
+	}
+
+	return selections, nil
+}
+
+func (m *MemoryStore) ReplaceLotSelections(userID, closeExecutionID int, selections []models.LotSelection) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	kept := []models.LotSelection{}
+	for _, selection := range m.lotSelections {
+		if selection.UserID != userID || selection.CloseExecutionID != closeExecutionID {
+			kept = append(kept, selection)
+		}
+	}
+
+	for i := range selections {
+		selections[i].ID = m.nextSelectionID
+		m.nextSelectionID++
+		kept = append(kept, selections[i])
+	}
+
+	m.lotSelections = kept
+	return nil
+}
+
+func (m *MemoryStore) ListFXRates(userID int) ([]models.FXRate, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	rates := []models.FXRate{}
+	for _, rate := range m.fxRates {
+		if rate.UserID == userID {
+			rates = append(rates, rate)
+		}
+	}
+
+	sort.Slice(rates, func(i, j int) bool {
+		if !rates[i].Date.Equal(rates[j].Date) {
+			return rates[i].Date.Before(rates[j].Date)
+		}
+		if rates[i].Currency != rates[j].Currency {
+			return rates[i].Currency < rates[j].Currency
+		}
+		return rates[i].Quote < rates[j].Quote
+	})
+
+	return rates, nil
+}
+
+func (m *MemoryStore) SaveFXRates(userID int, rates []models.FXRate) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range rates {
+		rates[i].UserID = userID
+		replaced := false
+		for j, existing := range m.fxRates {
+			if existing.UserID == userID && existing.Date.Equal(rates[i].Date) &&
+				existing.Currency == rates[i].Currency && existing.Quote == rates[i].Quote {
+				rates[i].ID = existing.ID
+				m.fxRates[j] = rates[i]
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			rates[i].ID = m.nextFXRateID
+			m.nextFXRateID++
+			m.fxRates = append(m.fxRates, rates[i])
+		}
+	}
+	return nil
+}
+
+func (m *MemoryStore) ListCorporateActions(userID int) ([]models.CorporateAction, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	actions := []models.CorporateAction{}
+	for _, action := range m.corporateActions {
+		if action.UserID == userID {
+			actions = append(actions, action)
+		}
+	}
+
+	sort.Slice(actions, func(i, j int) bool {
+		if !actions[i].Date.Equal(actions[j].Date) {
+			return actions[i].Date.Before(actions[j].Date)
+		}
+		return actions[i].ID < actions[j].ID
+	})
+
+	return actions, nil
+}
+
+func (m *MemoryStore) SaveCorporateActions(userID int, actions []models.CorporateAction) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range actions {
+		actions[i].UserID = userID
+		replaced := false
+		for j, existing := range m.corporateActions {
+			if existing.UserID == userID && existing.Date.Equal(actions[i].Date) &&
+				existing.Type == actions[i].Type && existing.Symbol == actions[i].Symbol {
+				actions[i].ID = existing.ID
+				m.corporateActions[j] = actions[i]
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			actions[i].ID = m.nextActionID
+			m.nextActionID++
+			m.corporateActions = append(m.corporateActions, actions[i])
+		}
+	}
+	return nil
+}
+
+func (m *MemoryStore) DeleteCorporateAction(userID, actionID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, action := range m.corporateActions {
+		if action.ID == actionID && action.UserID == userID {
+			m.corporateActions = append(m.corporateActions[:i], m.corporateActions[i+1:]...)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (m *MemoryStore) GetFeeSchedule(userID int, accountID *int) (*models.FeeSchedule, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if i := m.feeScheduleIndex(userID, accountID); i >= 0 {
+		schedule := m.feeSchedules[i]
+		return &schedule, nil
+	}
+	return nil, ErrNotFound
+}
+
+func (m *MemoryStore) ListFeeSchedules(userID int) ([]models.FeeSchedule, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	schedules := []models.FeeSchedule{}
+	for _, schedule := range m.feeSchedules {
+		if schedule.UserID == userID {
+			schedules = append(schedules, schedule)
+		}
+	}
+	return schedules, nil
+}
+
+func (m *MemoryStore) SaveFeeSchedule(schedule *models.FeeSchedule) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if i := m.feeScheduleIndex(schedule.UserID, schedule.AccountID); i >= 0 {
+		schedule.ID = m.feeSchedules[i].ID
+		m.feeSchedules[i] = *schedule
+		return nil
+	}
+
+	schedule.ID = m.nextScheduleID
+	m.nextScheduleID++
+	m.feeSchedules = append(m.feeSchedules, *schedule)
+	return nil
+}
+
+// feeScheduleIndex finds the schedule of the account, or the user's default
+// when accountID is nil, returning -1 when there is none.
+func (m *MemoryStore) feeScheduleIndex(userID int, accountID *int) int {
+	for i, schedule := range m.feeSchedules {
+		if schedule.UserID != userID || (schedule.AccountID == nil) != (accountID == nil) {
+			continue
+		}
+		if accountID == nil || *schedule.AccountID == *accountID {
+			return i
+		}
+	}
+	return -1
+}
@@ -2,10 +2,16 @@ package store
 
 import (
 	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 )
 
+func dec(value string) decimal.Decimal {
+	return decimal.RequireFromString(value)
+}
+
 func TestMemoryStore_CreateUser(t *testing.T) {
 	store := NewMemoryStore()
 
@@ -43,3 +49,147 @@ func TestMemoryStore_GetUserByEmail(t *testing.T) {
 	assert.Equal(t, user.Email, retrievedUser.Email, "Emails should match")
 	assert.Equal(t, user.Password, retrievedUser.Password, "Passwords should match")
 }
+
+func TestMemoryStore_TradeCRUD(t *testing.T) {
+	store := NewMemoryStore()
+
+	older := &models.Trade{UserID: 1, Symbol: "AAPL", Quantity: dec("10"), Price: dec("180"), TradeDate: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}
+	newer := &models.Trade{UserID: 1, Symbol: "MSFT", Quantity: dec("5"), Price: dec("400"), TradeDate: time.Date(2024, 2, 2, 0, 0, 0, 0, time.UTC)}
+	other := &models.Trade{UserID: 2, Symbol: "NVDA", Quantity: dec("1"), Price: dec("700"), TradeDate: time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)}
+
+	assert.NoError(t, store.CreateTrade(older))
+	assert.NoError(t, store.CreateTrade(newer))
+	assert.NoError(t, store.CreateTrade(other))
+	assert.NotEqual(t, older.ID, newer.ID, "Trade IDs should be unique")
+
+	trades, err := store.ListTrades(1)
+	assert.NoError(t, err)
+	assert.Len(t, trades, 2, "ListTrades should only return the user's trades")
+	assert.Equal(t, "MSFT", trades[0].Symbol, "ListTrades should return newest trades first")
+
+	_, err = store.GetTrade(2, older.ID)
+	assert.ErrorIs(t, err, ErrNotFound, "GetTrade should not return another user's trade")
+
+	older.Price = dec("181")
+	assert.NoError(t, store.UpdateTrade(older))
+	fetched, err := store.GetTrade(1, older.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "181", fetched.Price.String(), "UpdateTrade should persist changes")
+
+	assert.ErrorIs(t, store.DeleteTrade(2, older.ID), ErrNotFound, "DeleteTrade should not delete another user's trade")
+	assert.NoError(t, store.DeleteTrade(1, older.ID))
+	_, err = store.GetTrade(1, older.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestMemoryStore_ListExecutionsOrdersByTime(t *testing.T) {
+	store := NewMemoryStore()
+
+	later := &models.Execution{UserID: 1, Symbol: "AAPL", Side: models.SideSell, Quantity: dec("1"), Price: dec("2"), ExecutedAt: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}
+	earlier := &models.Execution{UserID: 1, Symbol: "AAPL", Side: models.SideBuy, Quantity: dec("1"), Price: dec("1"), ExecutedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	assert.NoError(t, store.CreateExecution(later))
+	assert.NoError(t, store.CreateExecution(earlier))
+
+	executions, err := store.ListExecutions(1)
+	assert.NoError(t, err)
+	assert.Len(t, executions, 2)
+	assert.Equal(t, earlier.ID, executions[0].ID, "ListExecutions should return oldest executions first")
+
+	_, err = store.GetExecution(2, earlier.ID)
+	assert.ErrorIs(t, err, ErrNotFound, "GetExecution should not return another user's execution")
+}
+
+func TestMemoryStore_DeleteSpreadDetachesLegs(t *testing.T) {
+	store := NewMemoryStore()
+
+	spread := &models.Spread{UserID: 1, Name: "SPY put credit"}
+	assert.NoError(t, store.CreateSpread(spread))
+
+	execution := &models.Execution{UserID: 1, Symbol: "SPY   240621P00500000", Side: models.SideSell, Quantity: dec("1"), Price: dec("4"), SpreadID: &spread.ID}
+	trade := &models.Trade{UserID: 1, Symbol: "SPY   240621P00495000", Action: models.ActionBuy, Quantity: dec("1"), Price: dec("2"), SpreadID: &spread.ID}
+	assert.NoError(t, store.CreateExecution(execution))
+	assert.NoError(t, store.CreateTrade(trade))
+
+	assert.ErrorIs(t, store.DeleteSpread(2, spread.ID), ErrNotFound, "DeleteSpread should not delete another user's spread")
+	assert.NoError(t, store.DeleteSpread(1, spread.ID))
+
+	_, err := store.GetSpread(1, spread.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	fetchedExecution, err := store.GetExecution(1, execution.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, fetchedExecution.SpreadID, "DeleteSpread should detach executions")
+
+	fetchedTrade, err := store.GetTrade(1, trade.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, fetchedTrade.SpreadID, "DeleteSpread should detach trades")
+}
+
+func TestMemoryStore_DeleteAccountKeepsTrades(t *testing.T) {
+	store := NewMemoryStore()
+
+	account := &models.Account{UserID: 1, Name: "IRA"}
+	assert.NoError(t, store.CreateAccount(account))
+
+	trade := &models.Trade{UserID: 1, AccountID: &account.ID, Symbol: "VTI", Action: models.ActionBuy, Quantity: dec("10"), Price: dec("250")}
+	execution := &models.Execution{UserID: 1, AccountID: &account.ID, Symbol: "VTI", Side: models.SideBuy, Quantity: dec("5"), Price: dec("251")}
+	assert.NoError(t, store.CreateTrade(trade))
+	assert.NoError(t, store.CreateExecution(execution))
+
+	assert.ErrorIs(t, store.DeleteAccount(2, account.ID), ErrNotFound, "DeleteAccount should not delete another user's account")
+	assert.NoError(t, store.DeleteAccount(1, account.ID))
+
+	fetchedTrade, err := store.GetTrade(1, trade.ID)
+	assert.NoError(t, err, "DeleteAccount should keep the account's trades")
+	assert.Nil(t, fetchedTrade.AccountID)
+
+	fetchedExecution, err := store.GetExecution(1, execution.ID)
+	assert.NoError(t, err, "DeleteAccount should keep the account's executions")
+	assert.Nil(t, fetchedExecution.AccountID)
+}
+
+func TestMemoryStore_ExecutionFingerprints(t *testing.T) {
+	s := NewMemoryStore()
+
+	execution := &models.Execution{UserID: 1, Symbol: "AAPL", Fingerprint: "abc"}
+	assert.NoError(t, s.CreateExecution(execution))
+	assert.ErrorIs(t, s.CreateExecution(&models.Execution{UserID: 1, Symbol: "AAPL", Fingerprint: "abc"}), ErrDuplicate)
+	assert.NoError(t, s.CreateExecution(&models.Execution{UserID: 2, Symbol: "AAPL", Fingerprint: "abc"}))
+	assert.NoError(t, s.CreateExecution(&models.Execution{UserID: 1, Symbol: "AAPL"}))
+	assert.NoError(t, s.CreateExecution(&models.Execution{UserID: 1, Symbol: "AAPL"}))
+
+	assert.NoError(t, s.UpdateExecution(&models.Execution{ID: execution.ID, UserID: 1, Symbol: "MSFT"}))
+	updated, err := s.GetExecution(1, execution.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "abc", updated.Fingerprint, "edits keep the fingerprint")
+
+	found, err := s.GetExecutionByFingerprint(1, "abc")
+	assert.NoError(t, err)
+	assert.Equal(t, execution.ID, found.ID)
+	_, err = s.GetExecutionByFingerprint(3, "abc")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = s.GetExecutionByFingerprint(1, "")
+	assert.ErrorIs(t, err, ErrNotFound, "fills entered by hand have no fingerprint to find")
+}
+
+func TestMemoryStore_CorporateActions(t *testing.T) {
+	s := NewMemoryStore()
+	date := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+
+	actions := []models.CorporateAction{{Date: date, Type: models.ActionSplit, Symbol: "NVDA", NewShares: 10, OldShares: 1}}
+	assert.NoError(t, s.SaveCorporateActions(1, actions))
+	replacement := []models.CorporateAction{{Date: date, Type: models.ActionSplit, Symbol: "NVDA", NewShares: 4, OldShares: 1}}
+	assert.NoError(t, s.SaveCorporateActions(1, replacement))
+	assert.Equal(t, actions[0].ID, replacement[0].ID, "the same day, type and symbol is replaced")
+
+	listed, err := s.ListCorporateActions(1)
+	assert.NoError(t, err)
+	assert.Len(t, listed, 1)
+	assert.Equal(t, int64(4), listed[0].NewShares)
+
+	assert.ErrorIs(t, s.DeleteCorporateAction(2, listed[0].ID), ErrNotFound)
+	assert.NoError(t, s.DeleteCorporateAction(1, listed[0].ID))
+	listed, err = s.ListCorporateActions(1)
+	assert.NoError(t, err)
+	assert.Empty(t, listed)
+}
@@ -1,8 +1,10 @@
 package store
 
 import (
+	"errors"
 	"github.com/drewbuiltit/trading-journal/backend/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type PostgresStore struct {
@@ -27,3 +29,602 @@ func (s *PostgresStore) GetUserByEmail(email string) (*models.User, error) {
 	}
 	return &user, nil
 }
+
+func (s *PostgresStore) GetUserByID(userID int) (*models.User, error) {
+	var user models.User
+	err := s.DB.First(&user, userID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (s *PostgresStore) UpdateLotMethod(userID int, method string) error {
+	result := s.DB.Model(&models.User{}).Where("id = ?", userID).Update("lot_method", method)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *PostgresStore) UpdateBaseCurrency(userID int, currency string) error {
+	result := s.DB.Model(&models.User{}).Where("id = ?", userID).Update("base_currency", currency)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *PostgresStore) UpdateTimezone(userID int, timezone string) error {
+	result := s.DB.Model(&models.User{}).Where("id = ?", userID).Update("timezone", timezone)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *PostgresStore) CreateAccount(account *models.Account) error {
+	return s.DB.Create(account).Error
+}
+
+func (s *PostgresStore) GetAccount(userID, accountID int) (*models.Account, error) {
+	var account models.Account
+	err := s.DB.Where("id = ? AND user_id = ?", accountID, userID).First(&account).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (s *PostgresStore) ListAccounts(userID int) ([]models.Account, error) {
+	var accounts []models.Account
+	err := s.DB.Where("user_id = ?", userID).Order("name ASC, id ASC").Find(&accounts).Error
+	if err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+func (s *PostgresStore) UpdateAccount(account *models.Account) error {
+	result := s.DB.Model(&models.Account{}).
+		Where("id = ? AND user_id = ?", account.ID, account.UserID).
+		Select("*").Omit("id", "user_id", "created_at").
+		Updates(account)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteAccount removes an account and leaves its trades and executions
+// unassigned rather than deleting them.
+func (s *PostgresStore) DeleteAccount(userID, accountID int) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.Execution{}).
+			Where("user_id = ? AND account_id = ?", userID, accountID).
+			Update("account_id", nil).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&models.Trade{}).
+			Where("user_id = ? AND account_id = ?", userID, accountID).
+			Update("account_id", nil).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&models.ImportBatch{}).
+			Where("user_id = ? AND account_id = ?", userID, accountID).
+			Update("account_id", nil).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&models.CashTransaction{}).
+			Where("user_id = ? AND account_id = ?", userID, accountID).
+			Update("account_id", nil).Error
+		if err != nil {
+			return err
+		}
+
+		result := tx.Where("id = ? AND user_id = ?", accountID, userID).Delete(&models.Account{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+func (s *PostgresStore) CreateTrade(trade *models.Trade) error {
+	return s.DB.Create(trade).Error
+}
+
+func (s *PostgresStore) GetTrade(userID, tradeID int) (*models.Trade, error) {
+	var trade models.Trade
+	err := s.DB.Where("id = ? AND user_id = ?", tradeID, userID).First(&trade).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &trade, nil
+}
+
+func (s *PostgresStore) ListTrades(userID int) ([]models.Trade, error) {
+	var trades []models.Trade
+	err := s.DB.Where("user_id = ?", userID).Order("trade_date DESC, id DESC").Find(&trades).Error
+	if err != nil {
+		return nil, err
+	}
+	return trades, nil
+}
+
+func (s *PostgresStore) UpdateTrade(trade *models.Trade) error {
+	result := s.DB.Model(&models.Trade{}).
+		Where("id = ? AND user_id = ?", trade.ID, trade.UserID).
+		Select("*").Omit("id", "user_id").
+		Updates(trade)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *PostgresStore) DeleteTrade(userID, tradeID int) error {
+	result := s.DB.Where("id = ? AND user_id = ?", tradeID, userID).Delete(&models.Trade{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *PostgresStore) CreateExecution(execution *models.Execution) error {
+	err := s.DB.Create(execution).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+func (s *PostgresStore) GetExecution(userID, executionID int) (*models.Execution, error) {
+	var execution models.Execution
+	err := s.DB.Where("id = ? AND user_id = ?", executionID, userID).First(&execution).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &execution, nil
+}
+
+func (s *PostgresStore) GetExecutionByFingerprint(userID int, fingerprint string) (*models.Execution, error) {
+	var execution models.Execution
+	err := s.DB.Where("user_id = ? AND fingerprint = ? AND fingerprint <> ''", userID, fingerprint).First(&execution).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &execution, nil
+}
+
+func (s *PostgresStore) ListExecutions(userID int) ([]models.Execution, error) {
+	var executions []models.Execution
+	err := s.DB.Where("user_id = ?", userID).Order("executed_at ASC, id ASC").Find(&executions).Error
+	if err != nil {
+		return nil, err
+	}
+	return executions, nil
+}
+
+func (s *PostgresStore) UpdateExecution(execution *models.Execution) error {
+	result := s.DB.Model(&models.Execution{}).
+		Where("id = ? AND user_id = ?", execution.ID, execution.UserID).
+		Select("*").Omit("id", "user_id", "fingerprint", "import_batch_id").
+		Updates(execution)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *PostgresStore) DeleteExecution(userID, executionID int) error {
+	result := s.DB.Where("id = ? AND user_id = ?", executionID, userID).Delete(&models.Execution{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *PostgresStore) CreateCashTransaction(transaction *models.CashTransaction) error {
+	err := s.DB.Create(transaction).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+func (s *PostgresStore) GetCashTransaction(userID, transactionID int) (*models.CashTransaction, error) {
+	var transaction models.CashTransaction
+	err := s.DB.Where("id = ? AND user_id = ?", transactionID, userID).First(&transaction).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+func (s *PostgresStore) ListCashTransactions(userID int) ([]models.CashTransaction, error) {
+	var transactions []models.CashTransaction
+	err := s.DB.Where("user_id = ?", userID).Order("posted_at ASC, id ASC").Find(&transactions).Error
+	if err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+func (s *PostgresStore) UpdateCashTransaction(transaction *models.CashTransaction) error {
+	result := s.DB.Model(&models.CashTransaction{}).
+		Where("id = ? AND user_id = ?", transaction.ID, transaction.UserID).
+		Select("*").Omit("id", "user_id", "fingerprint", "import_batch_id").
+		Updates(transaction)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *PostgresStore) DeleteCashTransaction(userID, transactionID int) error {
+	result := s.DB.Where("id = ? AND user_id = ?", transactionID, userID).Delete(&models.CashTransaction{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *PostgresStore) CreateImportBatch(batch *models.ImportBatch) error {
+	return s.DB.Create(batch).Error
+}
+
+func (s *PostgresStore) GetImportBatch(userID, batchID int) (*models.ImportBatch, error) {
+	var batch models.ImportBatch
+	err := s.DB.Where("id = ? AND user_id = ?", batchID, userID).First(&batch).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+func (s *PostgresStore) ListImportBatches(userID int) ([]models.ImportBatch, error) {
+	var batches []models.ImportBatch
+	err := s.DB.Where("user_id = ?", userID).Order("created_at DESC, id DESC").Find(&batches).Error
+	if err != nil {
+		return nil, err
+	}
+	return batches, nil
+}
+
+func (s *PostgresStore) UpdateImportBatch(batch *models.ImportBatch) error {
+	result := s.DB.Model(&models.ImportBatch{}).
+		Where("id = ? AND user_id = ?", batch.ID, batch.UserID).
+		Select("*").Omit("id", "user_id", "created_at").
+		Updates(batch)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteImportBatch rolls an import back, removing the batch and every
+// execution and cash transaction it created.
+func (s *PostgresStore) DeleteImportBatch(userID, batchID int) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("user_id = ? AND import_batch_id = ?", userID, batchID).Delete(&models.Execution{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Where("user_id = ? AND import_batch_id = ?", userID, batchID).Delete(&models.CashTransaction{}).Error
+		if err != nil {
+			return err
+		}
+
+		result := tx.Where("id = ? AND user_id = ?", batchID, userID).Delete(&models.ImportBatch{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+func (s *PostgresStore) CreateSpread(spread *models.Spread) error {
+	return s.DB.Create(spread).Error
+}
+
+func (s *PostgresStore) GetSpread(userID, spreadID int) (*models.Spread, error) {
+	var spread models.Spread
+	err := s.DB.Where("id = ? AND user_id = ?", spreadID, userID).First(&spread).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &spread, nil
+}
+
+func (s *PostgresStore) ListSpreads(userID int) ([]models.Spread, error) {
+	var spreads []models.Spread
+	err := s.DB.Where("user_id = ?", userID).Order("created_at DESC, id DESC").Find(&spreads).Error
+	if err != nil {
+		return nil, err
+	}
+	return spreads, nil
+}
+
+func (s *PostgresStore) UpdateSpread(spread *models.Spread) error {
+	result := s.DB.Model(&models.Spread{}).
+		Where("id = ? AND user_id = ?", spread.ID, spread.UserID).
+		Select("*").Omit("id", "user_id", "created_at").
+		Updates(spread)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteSpread removes a spread and detaches its legs, which then count as
+// outright positions again.
+func (s *PostgresStore) DeleteSpread(userID, spreadID int) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.Execution{}).
+			Where("user_id = ? AND spread_id = ?", userID, spreadID).
+			Update("spread_id", nil).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&models.Trade{}).
+			Where("user_id = ? AND spread_id = ?", userID, spreadID).
+			Update("spread_id", nil).Error
+		if err != nil {
+			return err
+		}
+
+		result := tx.Where("id = ? AND user_id = ?", spreadID, userID).Delete(&models.Spread{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
+
+func (s *PostgresStore) CreateCSVTemplate(template *models.CSVTemplate) error {
+	return s.DB.Create(template).Error
+}
+
+func (s *PostgresStore) GetCSVTemplate(userID, templateID int) (*models.CSVTemplate, error) {
+	var template models.CSVTemplate
+	err := s.DB.Where("id = ? AND user_id = ?", templateID, userID).First(&template).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (s *PostgresStore) ListCSVTemplates(userID int) ([]models.CSVTemplate, error) {
+	var templates []models.CSVTemplate
+	err := s.DB.Where("user_id = ?", userID).Order("name, id").Find(&templates).Error
+	if err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+func (s *PostgresStore) UpdateCSVTemplate(template *models.CSVTemplate) error {
+	result := s.DB.Model(&models.CSVTemplate{}).
+		Where("id = ? AND user_id = ?", template.ID, template.UserID).
+		Select("*").Omit("id", "user_id", "created_at").
+		Updates(template)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *PostgresStore) DeleteCSVTemplate(userID, templateID int) error {
+	result := s.DB.Where("id = ? AND user_id = ?", templateID, userID).Delete(&models.CSVTemplate{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *PostgresStore) ListLotSelections(userID int) ([]models.LotSelection, error) {
+	var selections []models.LotSelection
+	err := s.DB.Where("user_id = ?", userID).Order("close_execution_id ASC, id ASC").Find(&selections).Error
+	if err != nil {
+		return nil, err
+	}
+	return selections, nil
+}
+
+func (s *PostgresStore) ReplaceLotSelections(userID, closeExecutionID int, selections []models.LotSelection) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("user_id = ? AND close_execution_id = ?", userID, closeExecutionID).
+			Delete(&models.LotSelection{}).Error
+		if err != nil {
+			return err
+		}
+		if len(selections) == 0 {
+			return nil
+		}
+		return tx.Create(&selections).Error
+	})
+}
+
+func (s *PostgresStore) ListFXRates(userID int) ([]models.FXRate, error) {
+	var rates []models.FXRate
+	err := s.DB.Where("user_id = ?", userID).Order("date ASC, currency ASC, quote ASC").Find(&rates).Error
+	if err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
+
+// SaveFXRates inserts rates, replacing any already loaded for the same day
+// and currency pair.
+func (s *PostgresStore) SaveFXRates(userID int, rates []models.FXRate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+	for i := range rates {
+		rates[i].UserID = userID
+	}
+	return s.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "date"}, {Name: "currency"}, {Name: "quote"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate"}),
+	}).Create(&rates).Error
+}
+
+func (s *PostgresStore) ListCorporateActions(userID int) ([]models.CorporateAction, error) {
+	var actions []models.CorporateAction
+	err := s.DB.Where("user_id = ?", userID).Order("date ASC, id ASC").Find(&actions).Error
+	if err != nil {
+		return nil, err
+	}
+	return actions, nil
+}
+
+// SaveCorporateActions inserts actions, replacing any already loaded for the
+// same day, type and symbol.
+func (s *PostgresStore) SaveCorporateActions(userID int, actions []models.CorporateAction) error {
+	if len(actions) == 0 {
+		return nil
+	}
+	for i := range actions {
+		actions[i].UserID = userID
+	}
+	return s.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "date"}, {Name: "type"}, {Name: "symbol"}},
+		DoUpdates: clause.AssignmentColumns([]string{"new_symbol", "ratio", "cash", "note"}),
+	}).Create(&actions).Error
+}
+
+func (s *PostgresStore) DeleteCorporateAction(userID, actionID int) error {
+	result := s.DB.Where("id = ? AND user_id = ?", actionID, userID).Delete(&models.CorporateAction{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *PostgresStore) GetFeeSchedule(userID int, accountID *int) (*models.FeeSchedule, error) {
+	var schedule models.FeeSchedule
+	err := feeScheduleOf(s.DB, userID, accountID).First(&schedule).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+func (s *PostgresStore) ListFeeSchedules(userID int) ([]models.FeeSchedule, error) {
+	var schedules []models.FeeSchedule
+	err := s.DB.Where("user_id = ?", userID).Order("id ASC").Find(&schedules).Error
+	if err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+func (s *PostgresStore) SaveFeeSchedule(schedule *models.FeeSchedule) error {
+	var existing models.FeeSchedule
+	err := feeScheduleOf(s.DB, schedule.UserID, schedule.AccountID).First(&existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return s.DB.Create(schedule).Error
+	}
+	if err != nil {
+		return err
+	}
+	schedule.ID = existing.ID
+	return s.DB.Save(schedule).Error
+}
+
+// feeScheduleOf scopes a query to the account's schedule, or to the user's
+// default when accountID is nil.
+func feeScheduleOf(db *gorm.DB, userID int, accountID *int) *gorm.DB {
+	if accountID == nil {
+		return db.Where("user_id = ? AND account_id IS NULL", userID)
+	}
+	return db.Where("user_id = ? AND account_id = ?", userID, *accountID)
+}
@@ -0,0 +1,105 @@
+package store
+
+import (
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"math/rand"
+	"os"
+	"testing"
+	"time"
+)
+
+// TestPostgresStore_SumsMatchDecimal checks that amounts survive a round trip
+// through the decimal columns and that SQL sums agree with Go to the last
+// digit. It needs a disposable database in TEST_DATABASE_DSN.
+func TestPostgresStore_SumsMatchDecimal(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.Execution{}))
+
+	store := NewPostgresStore(db)
+	user := &models.User{Username: "sums", Email: "sums@example.com", Password: "secret"}
+	require.NoError(t, store.CreateUser(user))
+	t.Cleanup(func() {
+		db.Where("user_id = ?", user.ID).Delete(&models.Execution{})
+		db.Delete(user)
+	})
+
+	r := rand.New(rand.NewSource(1))
+	notional, costs := decimal.Zero, decimal.Zero
+	for i := 0; i < 500; i++ {
+		execution := &models.Execution{
+			UserID:     user.ID,
+			Symbol:     "AAPL",
+			Side:       models.SideBuy,
+			Quantity:   decimal.New(r.Int63n(100000)+1, -4),
+			Price:      decimal.New(r.Int63n(1000000)+1, -4),
+			Commission: decimal.New(r.Int63n(1000), -3),
+			Fees:       decimal.New(r.Int63n(1000), -5),
+			ExecutedAt: time.Now(),
+		}
+		require.NoError(t, store.CreateExecution(execution))
+		notional = notional.Add(execution.Quantity.Mul(execution.Price))
+		costs = costs.Add(execution.Commission).Add(execution.Fees)
+	}
+
+	var sums struct {
+		Notional decimal.Decimal
+		Costs    decimal.Decimal
+	}
+	require.NoError(t, db.Model(&models.Execution{}).
+		Select("SUM(quantity * price) AS notional, SUM(commission + fees) AS costs").
+		Where("user_id = ?", user.ID).
+		Scan(&sums).Error)
+	assert.True(t, notional.Equal(sums.Notional), "notional %s != %s", notional, sums.Notional)
+	assert.True(t, costs.Equal(sums.Costs), "costs %s != %s", costs, sums.Costs)
+
+	executions, err := store.ListExecutions(user.ID)
+	require.NoError(t, err)
+	listed := decimal.Zero
+	for _, execution := range executions {
+		listed = listed.Add(execution.Quantity.Mul(execution.Price))
+	}
+	assert.True(t, notional.Equal(listed))
+}
+
+// TestPostgresStore_DuplicateFingerprint checks that the unique index on
+// fingerprints surfaces as ErrDuplicate.
+func TestPostgresStore_DuplicateFingerprint(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.Execution{}))
+
+	store := NewPostgresStore(db)
+	user := &models.User{Username: "fingerprints", Email: "fingerprints@example.com", Password: "secret"}
+	require.NoError(t, store.CreateUser(user))
+	t.Cleanup(func() {
+		db.Where("user_id = ?", user.ID).Delete(&models.Execution{})
+		db.Delete(user)
+	})
+
+	require.NoError(t, store.CreateExecution(&models.Execution{UserID: user.ID, Symbol: "AAPL", Fingerprint: "abc", ExecutedAt: time.Now()}))
+	assert.ErrorIs(t, store.CreateExecution(&models.Execution{UserID: user.ID, Symbol: "AAPL", Fingerprint: "abc", ExecutedAt: time.Now()}), ErrDuplicate)
+	require.NoError(t, store.CreateExecution(&models.Execution{UserID: user.ID, Symbol: "AAPL", ExecutedAt: time.Now()}))
+	require.NoError(t, store.CreateExecution(&models.Execution{UserID: user.ID, Symbol: "AAPL", ExecutedAt: time.Now()}))
+
+	found, err := store.GetExecutionByFingerprint(user.ID, "abc")
+	require.NoError(t, err)
+	assert.Equal(t, "abc", found.Fingerprint)
+	_, err = store.GetExecutionByFingerprint(user.ID, "")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -1,8 +1,81 @@
 package store
 
-import "github.com/drewbuiltit/trading-journal/backend/internal/models"
+import (
+	"errors"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+)
+
+var ErrNotFound = errors.New("record not found")
+
+// ErrDuplicate is returned when an execution's or cash transaction's
+// fingerprint is already taken.
+// The Postgres store relies on the connection translating database errors.
+var ErrDuplicate = errors.New("duplicate record")
 
 type Store interface {
 	CreateUser(user *models.User) error
 	GetUserByEmail(email string) (*models.User, error)
+	GetUserByID(userID int) (*models.User, error)
+	UpdateLotMethod(userID int, method string) error
+	UpdateBaseCurrency(userID int, currency string) error
+	UpdateTimezone(userID int, timezone string) error
+
+	CreateAccount(account *models.Account) error
+	GetAccount(userID, accountID int) (*models.Account, error)
+	ListAccounts(userID int) ([]models.Account, error)
+	UpdateAccount(account *models.Account) error
+	DeleteAccount(userID, accountID int) error
+
+	CreateTrade(trade *models.Trade) error
+	GetTrade(userID, tradeID int) (*models.Trade, error)
+	ListTrades(userID int) ([]models.Trade, error)
+	UpdateTrade(trade *models.Trade) error
+	DeleteTrade(userID, tradeID int) error
+
+	CreateExecution(execution *models.Execution) error
+	GetExecution(userID, executionID int) (*models.Execution, error)
+	GetExecutionByFingerprint(userID int, fingerprint string) (*models.Execution, error)
+	ListExecutions(userID int) ([]models.Execution, error)
+	UpdateExecution(execution *models.Execution) error
+	DeleteExecution(userID, executionID int) error
+
+	CreateCashTransaction(transaction *models.CashTransaction) error
+	GetCashTransaction(userID, transactionID int) (*models.CashTransaction, error)
+	ListCashTransactions(userID int) ([]models.CashTransaction, error)
+	UpdateCashTransaction(transaction *models.CashTransaction) error
+	DeleteCashTransaction(userID, transactionID int) error
+
+	CreateImportBatch(batch *models.ImportBatch) error
+	GetImportBatch(userID, batchID int) (*models.ImportBatch, error)
+	ListImportBatches(userID int) ([]models.ImportBatch, error)
+	UpdateImportBatch(batch *models.ImportBatch) error
+	DeleteImportBatch(userID, batchID int) error
+
+	CreateSpread(spread *models.Spread) error
+	GetSpread(userID, spreadID int) (*models.Spread, error)
+	ListSpreads(userID int) ([]models.Spread, error)
+	UpdateSpread(spread *models.Spread) error
+	DeleteSpread(userID, spreadID int) error
+
+	CreateCSVTemplate(template *models.CSVTemplate) error
+	GetCSVTemplate(userID, templateID int) (*models.CSVTemplate, error)
+	ListCSVTemplates(userID int) ([]models.CSVTemplate, error)
+	UpdateCSVTemplate(template *models.CSVTemplate) error
+	DeleteCSVTemplate(userID, templateID int) error
+
+	ListLotSelections(userID int) ([]models.LotSelection, error)
+	ReplaceLotSelections(userID, closeExecutionID int, selections []models.LotSelection) error
+
+	ListFXRates(userID int) ([]models.FXRate, error)
+	SaveFXRates(userID int, rates []models.FXRate) error
+
+	ListCorporateActions(userID int) ([]models.CorporateAction, error)
+	SaveCorporateActions(userID int, actions []models.CorporateAction) error
+	DeleteCorporateAction(userID, actionID int) error
+
+	// GetFeeSchedule returns the schedule of the account, or the user's
+	// default schedule when accountID is nil.
+	GetFeeSchedule(userID int, accountID *int) (*models.FeeSchedule, error)
+	ListFeeSchedules(userID int) ([]models.FeeSchedule, error)
+	SaveFeeSchedule(schedule *models.FeeSchedule) error
 }
@@ -0,0 +1,89 @@
+package tax
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/drewbuiltit/trading-journal/backend/internal/accounts"
+	"github.com/drewbuiltit/trading-journal/backend/internal/auth"
+	"github.com/drewbuiltit/trading-journal/backend/internal/stats"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type TaxHandler struct {
+	Store store.Store
+}
+
+// GetReport returns the realized gains for the tax year given by ?year=, in
+// the user's time zone, split into short and long term. ?account= narrows
+// the lines reported; wash sales are still found across all the user's
+// accounts.
+func (h *TaxHandler) GetReport(w http.ResponseWriter, r *http.Request) {
+	report, _, ok := h.report(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// ExportForm8949 returns the report for ?year= as a CSV laid out like Form
+// 8949.
+func (h *TaxHandler) ExportForm8949(w http.ResponseWriter, r *http.Request) {
+	report, loc, ok := h.report(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="form-8949-%d.csv"`, report.Year))
+	WriteForm8949(w, report, loc)
+}
+
+func (h *TaxHandler) report(w http.ResponseWriter, r *http.Request) (Report, *time.Location, bool) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return Report{}, nil, false
+	}
+
+	value := r.URL.Query().Get("year")
+	if value == "" {
+		http.Error(w, "year is required", http.StatusBadRequest)
+		return Report{}, nil, false
+	}
+	year, err := strconv.Atoi(value)
+	if err != nil || year < 1900 || year > 9999 {
+		http.Error(w, "year must be a four-digit year", http.StatusBadRequest)
+		return Report{}, nil, false
+	}
+	accountFilter, err := accounts.ParseFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return Report{}, nil, false
+	}
+
+	user, err := h.Store.GetUserByID(userID)
+	if err != nil {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return Report{}, nil, false
+	}
+	loc := stats.LocationOf(user)
+
+	disposals, base, err := ForUser(h.Store, userID)
+	if err != nil {
+		http.Error(w, "Error building tax lots", http.StatusInternalServerError)
+		return Report{}, nil, false
+	}
+
+	filtered := []Disposal{}
+	for _, disposal := range disposals {
+		if accountFilter.Match(disposal.AccountID) {
+			filtered = append(filtered, disposal)
+		}
+	}
+	return NewReport(filtered, year, base, loc), loc, true
+}
@@ -0,0 +1,87 @@
+package tax
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"github.com/drewbuiltit/trading-journal/backend/internal/auth"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTaxHandlers(t *testing.T) {
+	taxHandler := &TaxHandler{Store: store.NewMemoryStore()}
+	assert.NoError(t, taxHandler.Store.CreateUser(&models.User{Username: "jane_doe", Email: "jane@example.com"}))
+	ira := &models.Account{UserID: 1, Name: "IRA"}
+	assert.NoError(t, taxHandler.Store.CreateAccount(ira))
+
+	for _, execution := range []models.Execution{
+		fill(0, nil, models.SideBuy, "10", "100", day(2023, 1, 3)),
+		fill(0, nil, models.SideSell, "10", "150", day(2024, 3, 1)),
+		fill(0, nil, models.SideBuy, "10", "100", day(2024, 6, 3)),
+		fill(0, nil, models.SideSell, "10", "90", day(2024, 7, 1)),
+		fill(0, &ira.ID, models.SideBuy, "10", "95", day(2024, 7, 10)),
+	} {
+		execution := execution
+		assert.NoError(t, taxHandler.Store.CreateExecution(&execution))
+	}
+
+	get := func(handler func(http.ResponseWriter, *http.Request), url string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("GET", url, nil)
+		assert.NoError(t, err)
+		req = req.WithContext(context.WithValue(req.Context(), auth.UserContextKey, 1))
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+
+	t.Run("Report", func(t *testing.T) {
+		rr := get(taxHandler.GetReport, "/tax/lots?year=2024")
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		var report Report
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+		assert.Equal(t, 2024, report.Year)
+		assert.Equal(t, "USD", report.BaseCurrency)
+		assert.Len(t, report.Disposals, 2)
+		assert.Equal(t, TermShort, report.Disposals[0].Term)
+		assert.Equal(t, CodeWashSale, report.Disposals[0].Code, "the IRA purchase replaces the shares")
+		assert.True(t, report.ShortTerm.Gain.IsZero())
+		assert.Equal(t, "100", report.ShortTerm.Adjustment.String())
+		assert.Equal(t, "500", report.LongTerm.Gain.String())
+	})
+
+	t.Run("Other Year", func(t *testing.T) {
+		var report Report
+		assert.NoError(t, json.Unmarshal(get(taxHandler.GetReport, "/tax/lots?year=2023").Body.Bytes(), &report))
+		assert.Empty(t, report.Disposals)
+	})
+
+	t.Run("Filter by Account", func(t *testing.T) {
+		var report Report
+		assert.NoError(t, json.Unmarshal(get(taxHandler.GetReport, "/tax/lots?year=2024&account=1").Body.Bytes(), &report))
+		assert.Empty(t, report.Disposals)
+	})
+
+	t.Run("Form 8949", func(t *testing.T) {
+		rr := get(taxHandler.ExportForm8949, "/tax/8949?year=2024")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, `attachment; filename="form-8949-2024.csv"`, rr.Header().Get("Content-Disposition"))
+
+		rows, err := csv.NewReader(rr.Body).ReadAll()
+		assert.NoError(t, err)
+		assert.Equal(t, form8949Columns, rows[0])
+		assert.Equal(t, []string{"I", "10 AAPL", "06/03/2024", "07/01/2024", "900.00", "1000.00", "W", "100.00", "0.00"}, rows[1])
+		assert.Equal(t, []string{"II", "10 AAPL", "01/03/2023", "03/01/2024", "1500.00", "1000.00", "", "", "500.00"}, rows[2])
+	})
+
+	t.Run("Bad Year", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, get(taxHandler.GetReport, "/tax/lots").Code)
+		assert.Equal(t, http.StatusBadRequest, get(taxHandler.GetReport, "/tax/lots?year=24").Code)
+		assert.Equal(t, http.StatusBadRequest, get(taxHandler.ExportForm8949, "/tax/8949?year=abc").Code)
+	})
+}
@@ -0,0 +1,297 @@
+package tax
+
+import (
+	"github.com/drewbuiltit/trading-journal/backend/internal/fx"
+	"github.com/drewbuiltit/trading-journal/backend/internal/lots"
+	"github.com/drewbuiltit/trading-journal/backend/internal/positions"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"github.com/shopspring/decimal"
+	"sort"
+	"time"
+)
+
+const (
+	TermShort = "short"
+	TermLong  = "long"
+
+	// CodeWashSale is the Form 8949 adjustment code for a disallowed loss.
+	CodeWashSale = "W"
+)
+
+// washDays is how far either side of a loss sale a purchase of the same
+// security makes it a wash sale.
+const washDays = 30
+
+// Disposal is one line of Form 8949: a matched lot, or the part of one whose
+// basis carries the same wash-sale adjustment. Amounts are in the user's
+// base currency. BasisAdjustment is the part of CostBasis that is a loss
+// disallowed on an earlier sale, and Adjustment the part of this sale's
+// loss that is disallowed.
+type Disposal struct {
+	AccountID        *int            `json:"account_id,omitempty"`
+	Symbol           string          `json:"symbol"`
+	Direction        string          `json:"direction"`
+	Quantity         decimal.Decimal `json:"quantity"`
+	Acquired         time.Time       `json:"acquired"`
+	Sold             time.Time       `json:"sold"`
+	Proceeds         decimal.Decimal `json:"proceeds"`
+	CostBasis        decimal.Decimal `json:"cost_basis"`
+	BasisAdjustment  decimal.Decimal `json:"basis_adjustment"`
+	Code             string          `json:"code,omitempty"`
+	Adjustment       decimal.Decimal `json:"adjustment"`
+	Gain             decimal.Decimal `json:"gain"`
+	Term             string          `json:"term"`
+	OpenExecutionID  int             `json:"open_execution_id,omitempty"`
+	CloseExecutionID int             `json:"close_execution_id,omitempty"`
+	OpenTradeID      int             `json:"open_trade_id,omitempty"`
+	CloseTradeID     int             `json:"close_trade_id,omitempty"`
+	// FXRateMissing is set when there was no rate into the base currency on
+	// the sale date, so the amounts are in the instrument's currency.
+	FXRateMissing bool `json:"fx_rate_missing,omitempty"`
+}
+
+// lotKey identifies the purchase a lot came from.
+type lotKey struct {
+	executionID int
+	tradeID     int
+}
+
+// carry is a loss disallowed on a wash sale and added to the basis of
+// quantity replacement shares, whose holding period starts earlier by held.
+type carry struct {
+	quantity decimal.Decimal
+	amount   decimal.Decimal
+	held     time.Duration
+}
+
+// acquisition is a purchase that opened a long lot, with the shares of it
+// still free to replace a loss sale and the losses carried into its basis.
+type acquisition struct {
+	key       lotKey
+	symbol    string
+	acquired  time.Time
+	quantity  decimal.Decimal
+	disposed  decimal.Decimal
+	replacing decimal.Decimal
+	carried   []carry
+}
+
+func (a *acquisition) available() decimal.Decimal {
+	return decimal.Min(a.quantity.Sub(a.replacing), a.quantity.Sub(a.disposed))
+}
+
+type sale struct {
+	accountID *int
+	match     lots.Match
+}
+
+// ForUser builds the user's lots from their current fills and lot method and
+// returns every disposal with wash sales applied across all their accounts.
+func ForUser(s store.Store, userID int) ([]Disposal, string, error) {
+	config, err := lots.ConfigForUser(s, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	fills, err := positions.Fills(s, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	base, table, err := fx.ForUser(s, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	return Disposals(positions.ConvertPnL(positions.Build(fills, config), table, base)), base, nil
+}
+
+// Disposals turns the lot matches of ungrouped positions into Form 8949
+// lines. A long lot sold at a loss is a wash sale to the extent the same
+// symbol was bought, in any account, within 30 days either side of the sale:
+// the disallowed loss moves into the basis of the replacement shares, whose
+// holding period takes on that of the shares sold. Each replacement share
+// absorbs one washed share at most. Short sales are always short-term and
+// are not checked for wash sales.
+func Disposals(built []positions.Position) []Disposal {
+	sales := []sale{}
+	acquisitions := make(map[lotKey]*acquisition)
+	acquire := func(key lotKey, symbol string, acquired time.Time, quantity decimal.Decimal) {
+		if key == (lotKey{}) {
+			return
+		}
+		if acquisitions[key] == nil {
+			acquisitions[key] = &acquisition{key: key, symbol: symbol, acquired: acquired}
+		}
+		acquisitions[key].quantity = acquisitions[key].quantity.Add(quantity)
+	}
+
+	for _, position := range built {
+		for _, match := range position.Matches {
+			sales = append(sales, sale{accountID: position.AccountID, match: match})
+			if match.Direction == lots.DirectionLong {
+				acquire(lotKey{match.OpenExecutionID, match.OpenTradeID}, match.Symbol, match.OpenedAt, match.Quantity)
+			}
+		}
+		for _, lot := range position.OpenLots {
+			if lot.Direction == lots.DirectionLong {
+				acquire(lotKey{lot.ExecutionID, lot.TradeID}, lot.Symbol, lot.OpenedAt, lot.Remaining)
+			}
+		}
+	}
+
+	bySymbol := make(map[string][]*acquisition)
+	for _, a := range acquisitions {
+		bySymbol[a.symbol] = append(bySymbol[a.symbol], a)
+	}
+	for _, list := range bySymbol {
+		sort.Slice(list, func(i, j int) bool {
+			if !list[i].acquired.Equal(list[j].acquired) {
+				return list[i].acquired.Before(list[j].acquired)
+			}
+			if list[i].key.executionID != list[j].key.executionID {
+				return list[i].key.executionID < list[j].key.executionID
+			}
+			return list[i].key.tradeID < list[j].key.tradeID
+		})
+	}
+
+	sort.SliceStable(sales, func(i, j int) bool {
+		return sales[i].match.ClosedAt.Before(sales[j].match.ClosedAt)
+	})
+
+	disposals := []Disposal{}
+	for _, s := range sales {
+		key := lotKey{s.match.OpenExecutionID, s.match.OpenTradeID}
+		for _, disposal := range split(s, acquisitions[key]) {
+			if disposal.Direction == lots.DirectionLong && disposal.Gain.IsNegative() {
+				wash(&disposal, key, bySymbol[disposal.Symbol])
+			}
+			disposals = append(disposals, disposal)
+		}
+		if a := acquisitions[key]; a != nil && s.match.Direction == lots.DirectionLong {
+			a.disposed = a.disposed.Add(s.match.Quantity)
+		}
+	}
+	return disposals
+}
+
+// split prices a sale and divides it into the parts of its lot that carry a
+// disallowed loss from an earlier wash sale and the part that carries none.
+func split(s sale, a *acquisition) []Disposal {
+	m := s.match
+	rate := m.FXRate
+	missing := !rate.IsPositive()
+	if missing {
+		rate = decimal.NewFromInt(1)
+	}
+	multiplier := m.Multiplier
+	if !multiplier.IsPositive() {
+		multiplier = decimal.NewFromInt(1)
+	}
+
+	// A short sale's proceeds are its opening price and its basis the cost
+	// of covering it.
+	salePrice, costPrice := m.ClosePrice, m.CostPrice
+	if m.Direction == lots.DirectionShort {
+		salePrice, costPrice = m.CostPrice, m.ClosePrice
+	}
+	proceeds := m.Quantity.Mul(salePrice).Mul(multiplier).Mul(rate)
+	basis := m.Quantity.Mul(costPrice).Mul(multiplier).Add(m.Costs.Total()).Mul(rate)
+
+	template := Disposal{
+		AccountID:        s.accountID,
+		Symbol:           m.Symbol,
+		Direction:        m.Direction,
+		Acquired:         m.OpenedAt,
+		Sold:             m.ClosedAt,
+		BasisAdjustment:  decimal.Zero,
+		Adjustment:       decimal.Zero,
+		OpenExecutionID:  m.OpenExecutionID,
+		CloseExecutionID: m.CloseExecutionID,
+		OpenTradeID:      m.OpenTradeID,
+		CloseTradeID:     m.CloseTradeID,
+		FXRateMissing:    missing,
+	}
+	part := func(quantity decimal.Decimal) Disposal {
+		disposal := template
+		disposal.Quantity = quantity
+		disposal.Proceeds = proceeds.Mul(quantity).Div(m.Quantity)
+		disposal.CostBasis = basis.Mul(quantity).Div(m.Quantity)
+		return disposal
+	}
+
+	parts := []Disposal{}
+	remaining := m.Quantity
+	if a != nil && m.Direction == lots.DirectionLong {
+		for len(a.carried) > 0 && remaining.IsPositive() {
+			c := &a.carried[0]
+			quantity := decimal.Min(c.quantity, remaining)
+			amount := c.amount.Mul(quantity).Div(c.quantity)
+
+			disposal := part(quantity)
+			disposal.Acquired = m.OpenedAt.Add(-c.held)
+			disposal.BasisAdjustment = amount
+			disposal.CostBasis = disposal.CostBasis.Add(amount)
+			parts = append(parts, disposal)
+
+			c.quantity = c.quantity.Sub(quantity)
+			c.amount = c.amount.Sub(amount)
+			if !c.quantity.IsPositive() {
+				a.carried = a.carried[1:]
+			}
+			remaining = remaining.Sub(quantity)
+		}
+	}
+	if remaining.IsPositive() {
+		parts = append(parts, part(remaining))
+	}
+
+	for i := range parts {
+		parts[i].Gain = parts[i].Proceeds.Sub(parts[i].CostBasis)
+		parts[i].Term = term(parts[i])
+	}
+	return parts
+}
+
+// wash disallows as much of a loss as there are replacement shares for,
+// carrying it into their basis.
+func wash(disposal *Disposal, own lotKey, candidates []*acquisition) {
+	sold := disposal.Sold.UTC()
+	day := time.Date(sold.Year(), sold.Month(), sold.Day(), 0, 0, 0, 0, time.UTC)
+	from, to := day.AddDate(0, 0, -washDays), day.AddDate(0, 0, washDays+1)
+	loss := disposal.Gain.Neg()
+
+	washed := decimal.Zero
+	for _, a := range candidates {
+		if !washed.LessThan(disposal.Quantity) {
+			break
+		}
+		if a.key == own || a.acquired.Before(from) || !a.acquired.Before(to) {
+			continue
+		}
+		quantity := decimal.Min(a.available(), disposal.Quantity.Sub(washed))
+		if !quantity.IsPositive() {
+			continue
+		}
+
+		a.replacing = a.replacing.Add(quantity)
+		a.carried = append(a.carried, carry{
+			quantity: quantity,
+			amount:   loss.Mul(quantity).Div(disposal.Quantity),
+			held:     disposal.Sold.Sub(disposal.Acquired),
+		})
+		washed = washed.Add(quantity)
+	}
+
+	if washed.IsPositive() {
+		disposal.Code = CodeWashSale
+		disposal.Adjustment = loss.Mul(washed).Div(disposal.Quantity)
+		disposal.Gain = disposal.Gain.Add(disposal.Adjustment)
+	}
+}
+
+// term is long for shares held more than a year. Short sales are short-term.
+func term(disposal Disposal) string {
+	if disposal.Direction == lots.DirectionLong && disposal.Sold.After(disposal.Acquired.AddDate(1, 0, 0)) {
+		return TermLong
+	}
+	return TermShort
+}
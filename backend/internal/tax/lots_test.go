@@ -0,0 +1,115 @@
+package tax
+
+import (
+	"github.com/drewbuiltit/trading-journal/backend/internal/lots"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/drewbuiltit/trading-journal/backend/internal/positions"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 15, 0, 0, 0, time.UTC)
+}
+
+func fill(id int, accountID *int, side, quantity, price string, at time.Time) models.Execution {
+	return models.Execution{
+		ID:         id,
+		UserID:     1,
+		AccountID:  accountID,
+		Symbol:     "AAPL",
+		Side:       side,
+		Quantity:   decimal.RequireFromString(quantity),
+		Price:      decimal.RequireFromString(price),
+		Multiplier: decimal.NewFromInt(1),
+		Currency:   "USD",
+		ExecutedAt: at,
+	}
+}
+
+func disposals(executions ...models.Execution) []Disposal {
+	built := positions.Build(executions, lots.Config{Method: lots.MethodFIFO})
+	for i := range built {
+		for j := range built[i].Matches {
+			built[i].Matches[j].FXRate = decimal.NewFromInt(1)
+		}
+	}
+	return Disposals(built)
+}
+
+func TestDisposals_WashSale(t *testing.T) {
+	result := disposals(
+		fill(1, nil, models.SideBuy, "10", "100", day(2024, 1, 2)),
+		fill(2, nil, models.SideSell, "10", "90", day(2024, 2, 1)),
+		fill(3, nil, models.SideBuy, "10", "95", day(2024, 2, 15)),
+		fill(4, nil, models.SideSell, "10", "110", day(2024, 6, 3)),
+	)
+	assert.Len(t, result, 2)
+
+	loss := result[0]
+	assert.Equal(t, CodeWashSale, loss.Code)
+	assert.Equal(t, "900", loss.Proceeds.String())
+	assert.Equal(t, "1000", loss.CostBasis.String())
+	assert.Equal(t, "100", loss.Adjustment.String())
+	assert.True(t, loss.Gain.IsZero(), "the whole loss is disallowed")
+
+	replacement := result[1]
+	assert.Equal(t, "100", replacement.BasisAdjustment.String())
+	assert.Equal(t, "1050", replacement.CostBasis.String())
+	assert.Equal(t, "50", replacement.Gain.String())
+	assert.Equal(t, day(2024, 1, 16), replacement.Acquired, "the holding period of the washed shares carries over")
+	assert.Empty(t, replacement.Code)
+}
+
+func TestDisposals_PartialWashAcrossAccounts(t *testing.T) {
+	ira := 2
+	result := disposals(
+		fill(1, nil, models.SideBuy, "10", "100", day(2024, 3, 1)),
+		fill(2, &ira, models.SideBuy, "4", "85", day(2024, 3, 20)),
+		fill(3, nil, models.SideSell, "10", "80", day(2024, 4, 1)),
+	)
+	assert.Len(t, result, 1)
+	assert.Equal(t, "80", result[0].Adjustment.String(), "4 of the 10 shares were replaced")
+	assert.Equal(t, "-120", result[0].Gain.String())
+}
+
+func TestDisposals_OutsideWindow(t *testing.T) {
+	result := disposals(
+		fill(1, nil, models.SideBuy, "10", "100", day(2024, 3, 1)),
+		fill(2, nil, models.SideSell, "10", "80", day(2024, 4, 1)),
+		fill(3, nil, models.SideBuy, "10", "85", time.Date(2024, 5, 1, 23, 0, 0, 0, time.UTC)),
+		fill(4, nil, models.SideBuy, "10", "85", time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)),
+	)
+	assert.Len(t, result, 1)
+	assert.Equal(t, CodeWashSale, result[0].Code, "the 30th day after the sale is inside the window")
+
+	result = disposals(
+		fill(1, nil, models.SideBuy, "10", "100", day(2024, 3, 1)),
+		fill(2, nil, models.SideSell, "10", "80", day(2024, 4, 1)),
+		fill(4, nil, models.SideBuy, "10", "85", time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)),
+	)
+	assert.Empty(t, result[0].Code)
+	assert.Equal(t, "-200", result[0].Gain.String())
+}
+
+func TestDisposals_Terms(t *testing.T) {
+	long := disposals(
+		fill(1, nil, models.SideBuy, "10", "100", day(2023, 1, 3)),
+		fill(2, nil, models.SideSell, "5", "120", day(2024, 1, 3)),
+		fill(3, nil, models.SideSell, "5", "120", day(2024, 1, 4)),
+	)
+	assert.Equal(t, TermShort, long[0].Term, "a year to the day is still short-term")
+	assert.Equal(t, TermLong, long[1].Term)
+
+	short := disposals(
+		fill(1, nil, models.SideSell, "10", "50", day(2024, 2, 1)),
+		fill(2, nil, models.SideBuy, "10", "60", day(2025, 3, 1)),
+	)
+	assert.Len(t, short, 1)
+	assert.Equal(t, TermShort, short[0].Term)
+	assert.Equal(t, "500", short[0].Proceeds.String())
+	assert.Equal(t, "600", short[0].CostBasis.String())
+	assert.Empty(t, short[0].Code, "short sales are not checked for wash sales")
+}
@@ -0,0 +1,113 @@
+package tax
+
+import (
+	"encoding/csv"
+	"github.com/shopspring/decimal"
+	"io"
+	"sort"
+	"time"
+)
+
+type Totals struct {
+	Proceeds   decimal.Decimal `json:"proceeds"`
+	CostBasis  decimal.Decimal `json:"cost_basis"`
+	Adjustment decimal.Decimal `json:"adjustment"`
+	Gain       decimal.Decimal `json:"gain"`
+}
+
+func (t *Totals) add(disposal Disposal) {
+	t.Proceeds = t.Proceeds.Add(disposal.Proceeds)
+	t.CostBasis = t.CostBasis.Add(disposal.CostBasis)
+	t.Adjustment = t.Adjustment.Add(disposal.Adjustment)
+	t.Gain = t.Gain.Add(disposal.Gain)
+}
+
+// Report is a tax year's realized gains, split by holding period.
+type Report struct {
+	Year         int        `json:"year"`
+	BaseCurrency string     `json:"base_currency"`
+	ShortTerm    Totals     `json:"short_term"`
+	LongTerm     Totals     `json:"long_term"`
+	Disposals    []Disposal `json:"disposals"`
+	// FXRateMissing is set when a disposal had no rate into the base
+	// currency. Such disposals are listed and counted in Unconverted but left
+	// out of the totals and Form 8949.
+	FXRateMissing bool `json:"fx_rate_missing,omitempty"`
+	Unconverted   int  `json:"unconverted,omitempty"`
+}
+
+// NewReport collects the disposals sold in year, as the calendar runs in
+// loc, short-term first and each term in the order sold.
+func NewReport(disposals []Disposal, year int, base string, loc *time.Location) Report {
+	report := Report{Year: year, BaseCurrency: base, Disposals: []Disposal{}}
+	for _, disposal := range disposals {
+		if disposal.Sold.In(loc).Year() != year {
+			continue
+		}
+		report.Disposals = append(report.Disposals, disposal)
+		if disposal.FXRateMissing {
+			report.FXRateMissing = true
+			report.Unconverted++
+			continue
+		}
+		if disposal.Term == TermLong {
+			report.LongTerm.add(disposal)
+		} else {
+			report.ShortTerm.add(disposal)
+		}
+	}
+
+	sort.SliceStable(report.Disposals, func(i, j int) bool {
+		if report.Disposals[i].Term != report.Disposals[j].Term {
+			return report.Disposals[i].Term == TermShort
+		}
+		return report.Disposals[i].Sold.Before(report.Disposals[j].Sold)
+	})
+	return report
+}
+
+var form8949Columns = []string{
+	"Part",
+	"(a) Description of property",
+	"(b) Date acquired",
+	"(c) Date sold or disposed of",
+	"(d) Proceeds",
+	"(e) Cost or other basis",
+	"(f) Code",
+	"(g) Amount of adjustment",
+	"(h) Gain or (loss)",
+}
+
+// WriteForm8949 writes the report's disposals in the columns of Form 8949,
+// Part I for short-term and Part II for long-term, with amounts rounded to
+// cents and dates in loc. Disposals not in the base currency are left out.
+func WriteForm8949(w io.Writer, report Report, loc *time.Location) error {
+	writer := csv.NewWriter(w)
+	writer.Write(form8949Columns)
+	for _, disposal := range report.Disposals {
+		if disposal.FXRateMissing {
+			continue
+		}
+		part := "I"
+		if disposal.Term == TermLong {
+			part = "II"
+		}
+		adjustment := ""
+		if !disposal.Adjustment.IsZero() {
+			adjustment = disposal.Adjustment.StringFixed(2)
+		}
+		writer.Write([]string{
+			part,
+			disposal.Quantity.String() + " " + disposal.Symbol,
+			disposal.Acquired.In(loc).Format("01/02/2006"),
+			disposal.Sold.In(loc).Format("01/02/2006"),
+			disposal.Proceeds.StringFixed(2),
+			disposal.CostBasis.StringFixed(2),
+			disposal.Code,
+			adjustment,
+			disposal.Gain.StringFixed(2),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,49 @@
+package tax
+
+import (
+	"bytes"
+	"encoding/csv"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestNewReport_Unconverted(t *testing.T) {
+	sold := day(2024, 3, 1)
+	report := NewReport([]Disposal{
+		{Symbol: "AAPL", Quantity: decimal.NewFromInt(10), Acquired: day(2024, 1, 2), Sold: sold, Proceeds: decimal.NewFromInt(1500), CostBasis: decimal.NewFromInt(1000), Gain: decimal.NewFromInt(500), Term: TermShort},
+		{Symbol: "SAP", Quantity: decimal.NewFromInt(10), Acquired: day(2024, 1, 2), Sold: sold, Proceeds: decimal.NewFromInt(2000), CostBasis: decimal.NewFromInt(1800), Gain: decimal.NewFromInt(200), Term: TermShort, FXRateMissing: true},
+	}, 2024, "USD", time.UTC)
+
+	assert.Len(t, report.Disposals, 2)
+	assert.True(t, report.FXRateMissing)
+	assert.Equal(t, 1, report.Unconverted)
+	assert.Equal(t, "500", report.ShortTerm.Gain.String(), "amounts in another currency are not added to the base totals")
+	assert.Equal(t, "1500", report.ShortTerm.Proceeds.String())
+
+	var buffer bytes.Buffer
+	assert.NoError(t, WriteForm8949(&buffer, report, time.UTC))
+	rows, err := csv.NewReader(&buffer).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, rows, 2)
+	assert.Equal(t, "10 AAPL", rows[1][1])
+}
+
+func TestNewReport_TaxYearInTimeZone(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	assert.NoError(t, err)
+	// 22:00 on New Year's Eve in New York is already 2025 in UTC.
+	sold := time.Date(2025, 1, 1, 3, 0, 0, 0, time.UTC)
+	disposals := []Disposal{{Symbol: "AAPL", Quantity: decimal.NewFromInt(10), Acquired: day(2024, 6, 3), Sold: sold, Gain: decimal.NewFromInt(50), Term: TermShort}}
+
+	assert.Len(t, NewReport(disposals, 2024, "USD", newYork).Disposals, 1)
+	assert.Empty(t, NewReport(disposals, 2025, "USD", newYork).Disposals)
+	assert.Len(t, NewReport(disposals, 2025, "USD", time.UTC).Disposals, 1)
+
+	var buffer bytes.Buffer
+	assert.NoError(t, WriteForm8949(&buffer, NewReport(disposals, 2024, "USD", newYork), newYork))
+	rows, err := csv.NewReader(&buffer).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, "12/31/2024", rows[1][3])
+}
@@ -0,0 +1,334 @@
+package trades
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/drewbuiltit/trading-journal/backend/internal/accounts"
+	"github.com/drewbuiltit/trading-journal/backend/internal/auth"
+	"github.com/drewbuiltit/trading-journal/backend/internal/instruments"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/drewbuiltit/trading-journal/backend/internal/positions"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"github.com/gorilla/mux"
+	"github.com/shopspring/decimal"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type TradeHandler struct {
+	Store store.Store
+}
+
+type TradeRequest struct {
+	Symbol     string          `json:"symbol"`
+	Direction  string          `json:"direction,omitempty"`
+	Action     string          `json:"action"`
+	Quantity   decimal.Decimal `json:"quantity"`
+	Price      decimal.Decimal `json:"price"`
+	Commission decimal.Decimal `json:"commission"`
+	Fees       decimal.Decimal `json:"fees"`
+	Multiplier decimal.Decimal `json:"multiplier"`
+	Currency   string          `json:"currency,omitempty"`
+	SpreadID   *int            `json:"spread_id,omitempty"`
+	AccountID  *int            `json:"account_id,omitempty"`
+	TradeDate  time.Time       `json:"trade_date"`
+	Strategy   string          `json:"strategy,omitempty"`
+	Tags       []string        `json:"tags,omitempty"`
+	Note       string          `json:"note,omitempty"`
+	models.Plan
+}
+
+func (req *TradeRequest) validate() error {
+	if strings.TrimSpace(req.Symbol) == "" {
+		return errors.New("symbol is required")
+	}
+	action := strings.ToLower(req.Action)
+	if !models.ValidAction(action) {
+		return errors.New("action must be buy, sell, sell_short or buy_to_cover")
+	}
+	if req.Direction != "" && strings.ToLower(req.Direction) != models.ActionDirection(action) {
+		return errors.New("direction does not match action")
+	}
+	if !req.Quantity.IsPositive() {
+		return errors.New("quantity must be positive")
+	}
+	if req.Price.IsNegative() {
+		return errors.New("price must not be negative")
+	}
+	if req.Commission.IsNegative() {
+		return errors.New("commission must not be negative")
+	}
+	if req.Fees.IsNegative() {
+		return errors.New("fees must not be negative")
+	}
+	if req.Multiplier.IsNegative() {
+		return errors.New("multiplier must not be negative")
+	}
+	if req.Currency != "" && !instruments.ValidCurrency(strings.ToUpper(req.Currency)) {
+		return errors.New("currency must be a three-letter currency code")
+	}
+	return req.Plan.Validate(models.ActionDirection(action))
+}
+
+// apply copies the request onto trade. Without an explicit currency the fill
+// is priced in the instrument's currency, or else the account's.
+func (req *TradeRequest) apply(trade *models.Trade, account *models.Account) {
+	trade.Symbol = instruments.Normalize(req.Symbol)
+	trade.Action = strings.ToLower(req.Action)
+	trade.Direction = models.ActionDirection(trade.Action)
+	trade.Quantity = req.Quantity
+	trade.Price = req.Price
+	trade.Commission = req.Commission
+	trade.Fees = req.Fees
+	trade.Multiplier = req.Multiplier
+	if !trade.Multiplier.IsPositive() {
+		trade.Multiplier = instruments.Lookup(trade.Symbol).Multiplier
+	}
+	trade.Currency = strings.ToUpper(req.Currency)
+	if trade.Currency == "" {
+		fallback := ""
+		if account != nil {
+			fallback = account.Currency
+		}
+		trade.Currency = instruments.CurrencyFor(trade.Symbol, fallback)
+	}
+	trade.SpreadID = req.SpreadID
+	trade.AccountID = req.AccountID
+	trade.TradeDate = req.TradeDate
+	if trade.TradeDate.IsZero() {
+		trade.TradeDate = time.Now().UTC()
+	}
+	trade.Strategy = req.Strategy
+	trade.Tags = models.NormalizeTags(req.Tags)
+	trade.Note = req.Note
+	trade.Plan = req.Plan
+}
+
+func (h *TradeHandler) CreateTrade(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	var req TradeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	if err := req.validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !h.validSpread(w, userID, req.SpreadID) {
+		return
+	}
+	account, ok := h.loadAccount(w, userID, req.AccountID)
+	if !ok {
+		return
+	}
+
+	trade := &models.Trade{UserID: userID}
+	req.apply(trade, account)
+	if !h.closes(w, userID, *trade) {
+		return
+	}
+
+	if err := h.Store.CreateTrade(trade); err != nil {
+		http.Error(w, "Error creating trade", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(trade)
+}
+
+func (h *TradeHandler) ListTrades(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	accountFilter, err := accounts.ParseFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	trades, err := h.Store.ListTrades(userID)
+	if err != nil {
+		http.Error(w, "Error listing trades", http.StatusInternalServerError)
+		return
+	}
+
+	if len(accountFilter) > 0 {
+		filtered := []models.Trade{}
+		for _, trade := range trades {
+			if accountFilter.Match(trade.AccountID) {
+				filtered = append(filtered, trade)
+			}
+		}
+		trades = filtered
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(trades)
+}
+
+func (h *TradeHandler) GetTrade(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	tradeID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid trade ID", http.StatusBadRequest)
+		return
+	}
+
+	trade, err := h.Store.GetTrade(userID, tradeID)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "Trade not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error fetching trade", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(trade)
+}
+
+func (h *TradeHandler) UpdateTrade(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	tradeID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid trade ID", http.StatusBadRequest)
+		return
+	}
+
+	var req TradeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	if err := req.validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !h.validSpread(w, userID, req.SpreadID) {
+		return
+	}
+	account, ok := h.loadAccount(w, userID, req.AccountID)
+	if !ok {
+		return
+	}
+
+	trade := &models.Trade{ID: tradeID, UserID: userID}
+	req.apply(trade, account)
+	if !h.closes(w, userID, *trade) {
+		return
+	}
+
+	err = h.Store.UpdateTrade(trade)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "Trade not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error updating trade", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(trade)
+}
+
+func (h *TradeHandler) DeleteTrade(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	tradeID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid trade ID", http.StatusBadRequest)
+		return
+	}
+
+	err = h.Store.DeleteTrade(userID, tradeID)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "Trade not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error deleting trade", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// validSpread checks that a referenced spread belongs to the user, writing
+// the error response when it does not.
+func (h *TradeHandler) validSpread(w http.ResponseWriter, userID int, spreadID *int) bool {
+	if spreadID == nil {
+		return true
+	}
+	_, err := h.Store.GetSpread(userID, *spreadID)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "Spread not found", http.StatusBadRequest)
+		return false
+	}
+	if err != nil {
+		http.Error(w, "Error fetching spread", http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
+
+// closes checks that a sell or buy to cover has a position to close,
+// writing the error response when it does not.
+func (h *TradeHandler) closes(w http.ResponseWriter, userID int, trade models.Trade) bool {
+	err := positions.CheckClose(h.Store, userID, trade.Execution())
+	if errors.Is(err, positions.ErrOverclose) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	if err != nil {
+		http.Error(w, "Error checking open positions", http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
+
+// loadAccount fetches a referenced account if it belongs to the user,
+// writing the error response when it does not.
+func (h *TradeHandler) loadAccount(w http.ResponseWriter, userID int, accountID *int) (*models.Account, bool) {
+	if accountID == nil {
+		return nil, true
+	}
+	account, err := h.Store.GetAccount(userID, *accountID)
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "Account not found", http.StatusBadRequest)
+		return nil, false
+	}
+	if err != nil {
+		http.Error(w, "Error fetching account", http.StatusInternalServerError)
+		return nil, false
+	}
+	return account, true
+}
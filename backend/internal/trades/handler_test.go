@@ -0,0 +1,290 @@
+package trades
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/drewbuiltit/trading-journal/backend/internal/auth"
+	"github.com/drewbuiltit/trading-journal/backend/internal/models"
+	"github.com/drewbuiltit/trading-journal/backend/internal/store"
+	"github.com/gorilla/mux"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func dec(value string) decimal.Decimal {
+	return decimal.RequireFromString(value)
+}
+
+func setupTestTradeHandler() *TradeHandler {
+	return &TradeHandler{
+		Store: store.NewMemoryStore(),
+	}
+}
+
+func newTradeRequest(t *testing.T, method, url string, body []byte, userID int, vars map[string]string) *http.Request {
+	req, err := http.NewRequest(method, url, bytes.NewBuffer(body))
+	assert.NoError(t, err)
+	req = req.WithContext(context.WithValue(req.Context(), auth.UserContextKey, userID))
+	if vars != nil {
+		req = mux.SetURLVars(req, vars)
+	}
+	return req
+}
+
+func TestCreateTradeHandler(t *testing.T) {
+	tradeHandler := setupTestTradeHandler()
+
+	t.Run("Successful Creation", func(t *testing.T) {
+		reqBody := TradeRequest{
+			Symbol:    "aapl",
+			Action:    "buy",
+			Quantity:  dec("10"),
+			Price:     dec("187.5"),
+			TradeDate: time.Date(2024, 3, 1, 14, 30, 0, 0, time.UTC),
+		}
+		body, _ := json.Marshal(reqBody)
+
+		rr := httptest.NewRecorder()
+		tradeHandler.CreateTrade(rr, newTradeRequest(t, "POST", "/trades", body, 1, nil))
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+
+		var trade models.Trade
+		err := json.Unmarshal(rr.Body.Bytes(), &trade)
+		assert.NoError(t, err)
+		assert.NotZero(t, trade.ID)
+		assert.Equal(t, 1, trade.UserID)
+		assert.Equal(t, "AAPL", trade.Symbol)
+		assert.Equal(t, "10", trade.Quantity.String())
+		assert.Equal(t, "187.5", trade.Price.String())
+		assert.Equal(t, models.DirectionLong, trade.Direction)
+	})
+
+	t.Run("Sell Beyond the Position", func(t *testing.T) {
+		body, _ := json.Marshal(TradeRequest{Symbol: "AAPL", Action: "sell", Quantity: dec("15"), Price: dec("190"), TradeDate: time.Date(2024, 3, 2, 14, 30, 0, 0, time.UTC)})
+
+		rr := httptest.NewRecorder()
+		tradeHandler.CreateTrade(rr, newTradeRequest(t, "POST", "/trades", body, 1, nil))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, "closing fill is more than the position held: 10 AAPL held long\n", rr.Body.String())
+	})
+
+	t.Run("Short Sale Derives Direction", func(t *testing.T) {
+		body, _ := json.Marshal(TradeRequest{Symbol: "TSLA", Action: "sell_short", Quantity: dec("5"), Price: dec("250")})
+
+		rr := httptest.NewRecorder()
+		tradeHandler.CreateTrade(rr, newTradeRequest(t, "POST", "/trades", body, 1, nil))
+		assert.Equal(t, http.StatusCreated, rr.Code)
+
+		var trade models.Trade
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &trade))
+		assert.Equal(t, models.ActionSellShort, trade.Action)
+		assert.Equal(t, models.DirectionShort, trade.Direction)
+	})
+
+	t.Run("Tags Are Normalized", func(t *testing.T) {
+		body, _ := json.Marshal(TradeRequest{Symbol: "MSFT", Action: "buy", Quantity: dec("1"), Price: dec("400"), Strategy: "Breakout", Tags: []string{" Earnings", "a+ setup", "earnings", ""}})
+
+		rr := httptest.NewRecorder()
+		tradeHandler.CreateTrade(rr, newTradeRequest(t, "POST", "/trades", body, 1, nil))
+		assert.Equal(t, http.StatusCreated, rr.Code)
+
+		var trade models.Trade
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &trade))
+		assert.Equal(t, []string{"a+ setup", "earnings"}, trade.Tags)
+		assert.Equal(t, "Breakout", trade.Strategy)
+	})
+
+	t.Run("Plan Is Kept", func(t *testing.T) {
+		body := []byte(`{"symbol": "NVDA", "action": "buy", "quantity": "10", "price": "901", "planned_entry": "900", "stop_loss": "880", "profit_target": "960"}`)
+
+		rr := httptest.NewRecorder()
+		tradeHandler.CreateTrade(rr, newTradeRequest(t, "POST", "/trades", body, 1, nil))
+		assert.Equal(t, http.StatusCreated, rr.Code)
+
+		var trade models.Trade
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &trade))
+		assert.Equal(t, "900", trade.PlannedEntry.String())
+		assert.Equal(t, "880", trade.StopLoss.String())
+		assert.Equal(t, "960", trade.ProfitTarget.String())
+	})
+
+	t.Run("Creation with Stop on the Wrong Side", func(t *testing.T) {
+		body := []byte(`{"symbol": "TSLA", "action": "sell_short", "quantity": "5", "price": "250", "planned_entry": "250", "stop_loss": "240"}`)
+
+		rr := httptest.NewRecorder()
+		tradeHandler.CreateTrade(rr, newTradeRequest(t, "POST", "/trades", body, 1, nil))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, "stop_loss must be on the losing side of planned_entry\n", rr.Body.String())
+	})
+
+	t.Run("Creation with Conflicting Direction", func(t *testing.T) {
+		body, _ := json.Marshal(TradeRequest{Symbol: "TSLA", Direction: "long", Action: "buy_to_cover", Quantity: dec("5"), Price: dec("250")})
+
+		rr := httptest.NewRecorder()
+		tradeHandler.CreateTrade(rr, newTradeRequest(t, "POST", "/trades", body, 1, nil))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, "direction does not match action\n", rr.Body.String())
+	})
+
+	t.Run("Creation without Action", func(t *testing.T) {
+		body, _ := json.Marshal(TradeRequest{Symbol: "TSLA", Quantity: dec("-5"), Price: dec("250")})
+
+		rr := httptest.NewRecorder()
+		tradeHandler.CreateTrade(rr, newTradeRequest(t, "POST", "/trades", body, 1, nil))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Creation with Missing Symbol", func(t *testing.T) {
+		body, _ := json.Marshal(TradeRequest{Action: "buy", Quantity: dec("10"), Price: dec("1")})
+
+		rr := httptest.NewRecorder()
+		tradeHandler.CreateTrade(rr, newTradeRequest(t, "POST", "/trades", body, 1, nil))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, "symbol is required\n", rr.Body.String())
+	})
+
+	t.Run("Creation with Unknown Account", func(t *testing.T) {
+		accountID := 42
+		body, _ := json.Marshal(TradeRequest{Symbol: "AAPL", Action: "buy", Quantity: dec("10"), Price: dec("1"), AccountID: &accountID})
+
+		rr := httptest.NewRecorder()
+		tradeHandler.CreateTrade(rr, newTradeRequest(t, "POST", "/trades", body, 1, nil))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, "Account not found\n", rr.Body.String())
+	})
+
+	t.Run("Creation with Invalid Payload", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		tradeHandler.CreateTrade(rr, newTradeRequest(t, "POST", "/trades", []byte(`{"symbol":}`), 1, nil))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, "Invalid request payload\n", rr.Body.String())
+	})
+}
+
+func TestTradeHandlerScopesToUser(t *testing.T) {
+	tradeHandler := setupTestTradeHandler()
+
+	trade := &models.Trade{UserID: 1, Symbol: "MSFT", Quantity: dec("5"), Price: dec("410"), TradeDate: time.Now()}
+	assert.NoError(t, tradeHandler.Store.CreateTrade(trade))
+	vars := map[string]string{"id": "1"}
+
+	t.Run("Owner Can Fetch", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		tradeHandler.GetTrade(rr, newTradeRequest(t, "GET", "/trades/1", nil, 1, vars))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Other User Gets Not Found", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		tradeHandler.GetTrade(rr, newTradeRequest(t, "GET", "/trades/1", nil, 2, vars))
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+
+		body, _ := json.Marshal(TradeRequest{Symbol: "MSFT", Action: "buy", Quantity: dec("1"), Price: dec("1")})
+		rr = httptest.NewRecorder()
+		tradeHandler.UpdateTrade(rr, newTradeRequest(t, "PUT", "/trades/1", body, 2, vars))
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+
+		rr = httptest.NewRecorder()
+		tradeHandler.DeleteTrade(rr, newTradeRequest(t, "DELETE", "/trades/1", nil, 2, vars))
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Other User Lists Nothing", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		tradeHandler.ListTrades(rr, newTradeRequest(t, "GET", "/trades", nil, 2, nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var trades []models.Trade
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &trades))
+		assert.Empty(t, trades)
+	})
+}
+
+func TestUpdateAndDeleteTradeHandler(t *testing.T) {
+	tradeHandler := setupTestTradeHandler()
+
+	trade := &models.Trade{UserID: 1, Symbol: "TSLA", Quantity: dec("3"), Price: dec("200"), TradeDate: time.Now()}
+	assert.NoError(t, tradeHandler.Store.CreateTrade(trade))
+	vars := map[string]string{"id": "1"}
+
+	t.Run("Successful Update", func(t *testing.T) {
+		body, _ := json.Marshal(TradeRequest{Symbol: "TSLA", Action: "buy", Quantity: dec("4"), Price: dec("210"), Note: "added"})
+
+		rr := httptest.NewRecorder()
+		tradeHandler.UpdateTrade(rr, newTradeRequest(t, "PUT", "/trades/1", body, 1, vars))
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		updated, err := tradeHandler.Store.GetTrade(1, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, "4", updated.Quantity.String())
+		assert.Equal(t, "210", updated.Price.String())
+		assert.Equal(t, "added", updated.Note)
+	})
+
+	t.Run("Invalid Trade ID", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		tradeHandler.GetTrade(rr, newTradeRequest(t, "GET", "/trades/abc", nil, 1, map[string]string{"id": "abc"}))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, "Invalid trade ID\n", rr.Body.String())
+	})
+
+	t.Run("Successful Delete", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		tradeHandler.DeleteTrade(rr, newTradeRequest(t, "DELETE", "/trades/1", nil, 1, vars))
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+
+		_, err := tradeHandler.Store.GetTrade(1, 1)
+		assert.ErrorIs(t, err, store.ErrNotFound)
+	})
+}
+
+func TestListTradesFiltersByAccount(t *testing.T) {
+	tradeHandler := setupTestTradeHandler()
+
+	cash := &models.Account{UserID: 1, Name: "Cash"}
+	ira := &models.Account{UserID: 1, Name: "IRA"}
+	assert.NoError(t, tradeHandler.Store.CreateAccount(cash))
+	assert.NoError(t, tradeHandler.Store.CreateAccount(ira))
+
+	for _, accountID := range []*int{&cash.ID, &ira.ID, nil} {
+		body, _ := json.Marshal(TradeRequest{Symbol: "SPY", Action: "buy", Quantity: dec("1"), Price: dec("500"), AccountID: accountID})
+		rr := httptest.NewRecorder()
+		tradeHandler.CreateTrade(rr, newTradeRequest(t, "POST", "/trades", body, 1, nil))
+		assert.Equal(t, http.StatusCreated, rr.Code)
+	}
+
+	list := func(query string) (int, []models.Trade) {
+		rr := httptest.NewRecorder()
+		tradeHandler.ListTrades(rr, newTradeRequest(t, "GET", "/trades"+query, nil, 1, nil))
+
+		var trades []models.Trade
+		if rr.Code == http.StatusOK {
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &trades))
+		}
+		return rr.Code, trades
+	}
+
+	_, trades := list("")
+	assert.Len(t, trades, 3)
+
+	_, trades = list("?account=2")
+	assert.Len(t, trades, 1)
+	assert.Equal(t, ira.ID, *trades[0].AccountID)
+
+	_, trades = list("?account=1,none")
+	assert.Len(t, trades, 2)
+
+	code, _ := list("?account=cash")
+	assert.Equal(t, http.StatusBadRequest, code)
+}